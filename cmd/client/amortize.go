@@ -0,0 +1,329 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/formulae"
+	"github.com/bhojpur/finance/pkg/loan"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// outputCSV is only understood by commands which print tables of numbers
+const outputCSV = "csv"
+
+var amortizeCmdOpts struct {
+	Principal              string
+	Rate                   string
+	StartDate              string
+	EndDate                string
+	Frequency              string
+	InterestType           string
+	PaymentPeriod          string
+	NoRounding             bool
+	RoundingPlaces         int32
+	RoundingErrorTolerance string
+	FirstPaymentDate       string
+	BackStub               string
+	DayCount               string
+	Prepay                 []string
+	Foreclose              string
+	ForeclosureCharge      string
+	Recast                 string
+	RateReset              []string
+	Spread                 string
+	ResetFrequency         string
+	RateCap                string
+	RateFloor              string
+	MaxRateChange          string
+	BenchmarkCurve         string
+	Plot                   string
+}
+
+// amortizeFlags names the flag of each loan config field for error messages
+var amortizeFlags = map[string]string{
+	"amount_borrowed":          "--principal",
+	"interest":                 "--rate",
+	"start_date":               "--start",
+	"end_date":                 "--end",
+	"frequency":                "--frequency",
+	"interest_type":            "--interest-type",
+	"payment_period":           "--payment-period",
+	"rounding_places":          "--rounding-places",
+	"rounding_error_tolerance": "--rounding-error-tolerance",
+	"first_payment_date":       "--first-payment",
+	"back_stub":                "--back-stub",
+	"day_count":                "--day-count",
+	"recast":                   "--recast",
+	"rate_resets":              "--rate-reset",
+	"spread":                   "--spread",
+	"reset_frequency":          "--reset-frequency",
+	"rate_cap":                 "--rate-cap",
+	"rate_floor":               "--rate-floor",
+	"max_rate_change":          "--max-rate-change",
+	"benchmark_curve":          "--benchmark-curve",
+}
+
+// amortizeCmd represents the amortize command
+var amortizeCmd = &cobra.Command{
+	Use:   "amortize",
+	Short: "Computes the amortization schedule of a loan locally",
+	Long: `Computes the amortization schedule of a loan without talking to a server.
+Besides the table, json and yaml output formats the schedule can be printed as csv.`,
+	Example: "  finance amortize --principal 200000 --rate 850 --start 2022-01-01 --end 2041-12-31 -o csv",
+	Args:    cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := amortizeConfig()
+		if err != nil {
+			log.Fatal(err)
+		}
+		amortization, err := formulae.NewAmortization(cfg)
+		if err != nil {
+			log.Fatal(flagError(err))
+		}
+		rows, err := amortization.GenerateTable()
+		if err != nil {
+			log.Fatal(flagError(err))
+		}
+		resp := loan.NewScheduleResponse(rows)
+
+		if rootCmdOpts.Output == outputCSV {
+			err = printAmortizationCSV(os.Stdout, resp)
+		} else {
+			var p *printer
+			p, err = newPrinter(os.Stdout)
+			if err != nil {
+				log.Fatal(err)
+			}
+			err = p.Print(resp, func(w io.Writer) error { return printAmortizationTable(w, resp) })
+		}
+		if err != nil {
+			log.WithError(err).Fatal("cannot print schedule")
+		}
+
+		if amortizeCmdOpts.Plot != "" {
+			name := strings.TrimSuffix(amortizeCmdOpts.Plot, ".html")
+			if err := formulae.PlotRows(rows, name); err != nil {
+				log.WithError(err).Fatal("cannot plot schedule")
+			}
+			log.Infof("wrote plot to %s.html", name)
+		}
+	},
+}
+
+// amortizeConfig converts the flags to an amortization config, validating them like the LoanService does
+func amortizeConfig() (*formulae.Config, error) {
+	opts := amortizeCmdOpts
+	c := &v1.LoanConfig{
+		StartDate:              opts.StartDate,
+		EndDate:                opts.EndDate,
+		AmountBorrowed:         opts.Principal,
+		Interest:               opts.Rate,
+		EnableRounding:         !opts.NoRounding,
+		RoundingPlaces:         opts.RoundingPlaces,
+		RoundingErrorTolerance: opts.RoundingErrorTolerance,
+		FirstPaymentDate:       opts.FirstPaymentDate,
+		DayCount:               strings.ToUpper(strings.ReplaceAll(opts.DayCount, "/", "")),
+		Spread:                 opts.Spread,
+		RateCap:                opts.RateCap,
+		RateFloor:              opts.RateFloor,
+		MaxRateChange:          opts.MaxRateChange,
+	}
+	frequency, ok := v1.LoanFrequency_value["FREQUENCY_"+enumName(opts.Frequency)]
+	if !ok {
+		return nil, fmt.Errorf("--frequency: unknown frequency %q", opts.Frequency)
+	}
+	c.Frequency = v1.LoanFrequency(frequency)
+	interestType, ok := v1.InterestType_value["INTEREST_TYPE_"+enumName(opts.InterestType)]
+	if !ok {
+		return nil, fmt.Errorf("--interest-type: unknown interest type %q", opts.InterestType)
+	}
+	c.InterestType = v1.InterestType(interestType)
+	paymentPeriod, ok := v1.PaymentPeriod_value["PAYMENT_PERIOD_"+enumName(opts.PaymentPeriod)]
+	if !ok {
+		return nil, fmt.Errorf("--payment-period: unknown payment period %q", opts.PaymentPeriod)
+	}
+	c.PaymentPeriod = v1.PaymentPeriod(paymentPeriod)
+	backStub, ok := v1.BackStub_value["BACK_STUB_"+enumName(opts.BackStub)]
+	if !ok {
+		return nil, fmt.Errorf("--back-stub: unknown back stub %q", opts.BackStub)
+	}
+	c.BackStub = v1.BackStub(backStub)
+	if opts.Recast != "" {
+		recast, ok := v1.RecastPolicy_value["RECAST_POLICY_"+enumName(opts.Recast)]
+		if !ok {
+			return nil, fmt.Errorf("--recast: unknown recast policy %q", opts.Recast)
+		}
+		c.Recast = v1.RecastPolicy(recast)
+	}
+	for _, r := range opts.RateReset {
+		date, rate := splitDated(r)
+		if date == "" || rate == "" {
+			return nil, fmt.Errorf("--rate-reset: %q is not DATE=RATE", r)
+		}
+		c.RateResets = append(c.RateResets, &v1.LoanRateReset{Date: date, Rate: rate})
+	}
+	if opts.ResetFrequency != "" {
+		resetFrequency, ok := v1.LoanFrequency_value["FREQUENCY_"+enumName(opts.ResetFrequency)]
+		if !ok {
+			return nil, fmt.Errorf("--reset-frequency: unknown frequency %q", opts.ResetFrequency)
+		}
+		c.ResetFrequency = v1.LoanFrequency(resetFrequency)
+	}
+	if opts.BenchmarkCurve != "" {
+		curve, err := os.ReadFile(opts.BenchmarkCurve)
+		if err != nil {
+			return nil, fmt.Errorf("--benchmark-curve: %w", err)
+		}
+		c.BenchmarkCurve = string(curve)
+	}
+	for _, p := range opts.Prepay {
+		date, amount := splitDated(p)
+		if date == "" || amount == "" {
+			return nil, fmt.Errorf("--prepay: %q is not DATE=AMOUNT", p)
+		}
+		c.Prepayments = append(c.Prepayments, &v1.LoanPrepayment{Date: date, Amount: amount})
+	}
+	if opts.Foreclose != "" {
+		c.Prepayments = append(c.Prepayments, &v1.LoanPrepayment{Date: opts.Foreclose, Foreclose: true, Charge: opts.ForeclosureCharge})
+	}
+
+	cfg, err := loan.NewConfig(c)
+	if err != nil {
+		return nil, flagError(err)
+	}
+	return cfg, nil
+}
+
+// splitDated splits the DATE=VALUE form of --prepay and --rate-reset
+func splitDated(s string) (date, value string) {
+	i := strings.Index(s, "=")
+	if i < 0 {
+		return "", ""
+	}
+	return s[:i], s[i+1:]
+}
+
+// enumName turns user input such as "half-yearly" into the suffix of an enum value name
+func enumName(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+}
+
+// flagError rephrases loan config errors in terms of the flags of the amortize command
+func flagError(err error) error {
+	var ferr *loan.FieldError
+	if !errors.As(loan.ConfigError(err), &ferr) {
+		return err
+	}
+	flag, ok := amortizeFlags[ferr.Field]
+	if !ok {
+		flag, ok = indexedFlag(ferr.Field)
+	}
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%s: %v", flag, ferr.Err)
+}
+
+// indexedFlag names the flag of a field of a rate reset or a prepayment. The foreclosure follows the part-payments.
+func indexedFlag(field string) (string, bool) {
+	var (
+		i    int
+		name string
+	)
+	if strings.HasPrefix(field, "rate_resets[") {
+		return "--rate-reset", true
+	}
+	if _, err := fmt.Sscanf(field, "prepayments[%d].%s", &i, &name); err != nil {
+		return "", false
+	}
+	switch {
+	case i < len(amortizeCmdOpts.Prepay):
+		return "--prepay", true
+	case name == "charge":
+		return "--foreclosure-charge", true
+	default:
+		return "--foreclose", true
+	}
+}
+
+func printAmortizationTable(w io.Writer, resp *v1.GenerateScheduleResponse) error {
+	fmt.Fprintln(w, "PERIOD\tSTART\tEND\tPAYMENT\tINTEREST\tPRINCIPAL\tPREPAYMENT\tCHARGE\tBALANCE")
+	for _, r := range resp.Rows {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Period, r.StartDate, r.EndDate, r.Payment, r.Interest, r.Principal, r.Prepayment, r.Charge, r.Balance)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t\t%s\t%s\t%s\t%s\t%s\t\n", resp.TotalPayment, resp.TotalInterest, resp.TotalPrincipal, resp.TotalPrepayment, resp.TotalCharge)
+	return nil
+}
+
+func printAmortizationCSV(w io.Writer, resp *v1.GenerateScheduleResponse) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"period", "start_date", "end_date", "payment", "interest", "principal", "prepayment", "charge", "balance"})
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Rows {
+		err = cw.Write([]string{strconv.FormatInt(r.Period, 10), r.StartDate, r.EndDate, r.Payment, r.Interest, r.Principal, r.Prepayment, r.Charge, r.Balance})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func init() {
+	rootCmd.AddCommand(amortizeCmd)
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Principal, "principal", "", "amount borrowed, e.g. 200000.00")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Rate, "rate", "", "annual interest rate in basis points, e.g. 850 for 8.5%")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.StartDate, "start", "", "first day of the loan as YYYY-MM-DD")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.EndDate, "end", "", "last day of the loan as YYYY-MM-DD, which must end a whole number of periods")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Frequency, "frequency", "monthly", "payment frequency: daily, weekly, fortnightly, monthly, quarterly, half-yearly or annually")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.InterestType, "interest-type", "reducing", "interest type: flat or reducing")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.PaymentPeriod, "payment-period", "ending", "when payments are made in a period: beginning or ending")
+	amortizeCmd.Flags().BoolVar(&amortizeCmdOpts.NoRounding, "no-rounding", false, "prints exact amounts instead of rounding them")
+	amortizeCmd.Flags().Int32Var(&amortizeCmdOpts.RoundingPlaces, "rounding-places", 2, "decimal places amounts are rounded to")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.RoundingErrorTolerance, "rounding-error-tolerance", "", "how much payments may differ from principal plus interest due to rounding")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.FirstPaymentDate, "first-payment", "", "first payment date as YYYY-MM-DD, turns broken periods at either end into stubs with pro-rated interest")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.BackStub, "back-stub", "short", "how a broken period at the end is paid: short (a period of its own) or long (part of the last period)")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.DayCount, "day-count", "", "day count convention interest accrues by, e.g. 30E360, ACT360, ACT365 or ACTACT. By default each period accrues a fixed share of the annual rate")
+	amortizeCmd.Flags().StringArrayVar(&amortizeCmdOpts.Prepay, "prepay", nil, "part-payment as DATE=AMOUNT, e.g. 2022-06-15=50000. Can be repeated")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Foreclose, "foreclose", "", "date as YYYY-MM-DD on which the whole outstanding balance is repaid")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.ForeclosureCharge, "foreclosure-charge", "", "foreclosure charge in basis points of the balance repaid, e.g. 200 for 2%")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Recast, "recast", "", "how prepayments and rate resets change the schedule: keep-payment (shortens the tenor, fails if a rate rise needs a higher payment) or keep-tenor (changes the payment). By default prepayments keep the payment and rate resets the tenor")
+	amortizeCmd.Flags().StringArrayVar(&amortizeCmdOpts.RateReset, "rate-reset", nil, "benchmark rate in basis points from a date on as DATE=RATE, e.g. 2022-04-08=650. Makes the loan a floating rate loan where --rate holds until the first reset. Can be repeated")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Spread, "spread", "", "spread in basis points over the benchmark rate")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.ResetFrequency, "reset-frequency", "", "how often the rate is reset, e.g. quarterly. By default it is reset every period")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.RateCap, "rate-cap", "", "highest rate in basis points a reset may set")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.RateFloor, "rate-floor", "", "lowest rate in basis points a reset may set")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.MaxRateChange, "max-rate-change", "", "most a single reset may change the rate by in basis points")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.BenchmarkCurve, "benchmark-curve", "", "file containing a term structure in JSON to project the benchmark rates from, instead of --rate-reset")
+	amortizeCmd.Flags().StringVar(&amortizeCmdOpts.Plot, "plot", "", "also writes an HTML chart of the schedule to NAME.html in the working directory")
+}
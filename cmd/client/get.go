@@ -0,0 +1,61 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"io"
+	"os"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Prints the status of an Algorithm",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		resp, err := client.GetAlgorithm(context.Background(), &v1.GetAlgorithmRequest{Name: args[0]})
+		if err != nil {
+			log.WithError(err).Fatal("cannot get algorithm")
+		}
+		err = p.Print(resp.Result, func(w io.Writer) error { return printStatusDetails(w, resp.Result) })
+		if err != nil {
+			log.WithError(err).Fatal("cannot print algorithm")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"io"
+	"os"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var listCmdOpts struct {
+	Filter []string
+	Order  []string
+	Limit  int
+}
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists Algorithms",
+	Long: `Lists Algorithms matching all filters, e.g.
+  finance list --filter phase==running --filter metadata.owner==alice
+
+Filters have the form field<op>value. Supported operators are == (equals),
+!= (does not equal), ~= (contains), |= (starts with) and =| (ends with).
+A field on its own matches if the field is set, a leading ! negates a filter.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filter, err := parseFilter(listCmdOpts.Filter)
+		if err != nil {
+			log.WithError(err).Fatal("invalid filter")
+		}
+		var order []*v1.OrderExpression
+		for _, o := range listCmdOpts.Order {
+			expr, err := query.ParseOrder(o)
+			if err != nil {
+				log.WithError(err).Fatal("invalid order")
+			}
+			order = append(order, expr)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		resp, err := client.ListAlgorithm(context.Background(), &v1.ListAlgorithmRequest{
+			Filter: filter,
+			Order:  order,
+			Limit:  int32(listCmdOpts.Limit),
+		})
+		if err != nil {
+			log.WithError(err).Fatal("cannot list algorithms")
+		}
+		err = p.Print(resp, func(w io.Writer) error { return printStatusTable(w, resp.Result) })
+		if err != nil {
+			log.WithError(err).Fatal("cannot print algorithms")
+		}
+	},
+}
+
+// parseFilter turns filter terms given on the command line into a filter which matches
+// if all terms match
+func parseFilter(terms []string) ([]*v1.FilterExpression, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	expr := &v1.FilterExpression{}
+	for _, t := range terms {
+		term, err := query.ParseFilterTerm(t)
+		if err != nil {
+			return nil, err
+		}
+		expr.Terms = append(expr.Terms, term)
+	}
+	return []*v1.FilterExpression{expr}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringArrayVar(&listCmdOpts.Filter, "filter", nil, "only list Algorithms matching this filter (field==value), can be repeated")
+	listCmd.Flags().StringArrayVar(&listCmdOpts.Order, "order", []string{"metadata.created:desc"}, "order Algorithms by a field (field:asc or field:desc), can be repeated")
+	listCmd.Flags().IntVar(&listCmdOpts.Limit, "limit", 50, "maximum number of Algorithms to list, zero means no limit")
+}
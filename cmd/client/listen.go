@@ -0,0 +1,150 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// listenCmd represents the listen command
+var listenCmd = &cobra.Command{
+	Use:   "listen <name>",
+	Short: "Follows the log output of an Algorithm",
+	Long: `Prints the log output of an Algorithm as it is produced, until the Algorithm is done.
+The command fails if the Algorithm does not finish successfully. Using json or yaml
+output, all log slices and status updates are printed instead.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		status, err := listenAlgorithm(context.Background(), client, args[0], p)
+		if err != nil {
+			log.WithError(err).Fatal("cannot listen to algorithm")
+		}
+		exitOnFailure(status)
+	},
+}
+
+// listenAlgorithm prints the log output of an Algorithm until it's done and returns its last known status.
+// If the connection breaks it reconnects and continues where it left off.
+func listenAlgorithm(ctx context.Context, client v1.FinanceServiceClient, name string, p *printer) (*v1.AlgorithmStatus, error) {
+	logs := v1.ListenRequestLogs_LOGS_UNSLICED
+	if p.Format != outputTable {
+		logs = v1.ListenRequestLogs_LOGS_RAW
+	}
+
+	var (
+		status *v1.AlgorithmStatus
+		// printed is how much of the log we have printed so far: bytes when printing a table,
+		// slice events otherwise. Every Listen call replays the log from the start.
+		printed int
+	)
+	err := keepStreaming(ctx, "listening to "+name, func(ctx context.Context) error {
+		sub, err := client.Listen(ctx, &v1.ListenRequest{Name: name, Updates: true, Logs: logs}, grpc.WaitForReady(true))
+		if err != nil {
+			return err
+		}
+
+		var replayed int
+		for {
+			resp, err := sub.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if u := resp.GetUpdate(); u != nil {
+				if proto.Equal(status, u) {
+					continue
+				}
+				if status == nil || status.Phase != u.Phase {
+					log.WithField("name", u.Name).WithField("phase", query.PhaseString(u.Phase)).Debug("algorithm update")
+				}
+				status = u
+			}
+
+			slice := resp.GetSlice()
+			if p.Format != outputTable {
+				if slice != nil {
+					replayed++
+					if replayed <= printed {
+						continue
+					}
+					printed++
+				}
+				err = p.PrintStreamed(resp, "", nil)
+			} else if slice != nil {
+				payload := slice.Payload
+				if skip := printed - replayed; skip > 0 {
+					if skip > len(payload) {
+						skip = len(payload)
+					}
+					payload = payload[skip:]
+				}
+				replayed += len(slice.Payload)
+				printed += len(payload)
+				_, err = io.WriteString(p.Out, payload)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+	return status, err
+}
+
+// exitOnFailure terminates the process with a non-zero exit code unless the Algorithm finished successfully
+func exitOnFailure(status *v1.AlgorithmStatus) {
+	if status == nil || status.Phase != v1.AlgorithmPhase_PHASE_DONE {
+		fmt.Fprintln(os.Stderr, "algorithm did not finish")
+		os.Exit(1)
+	}
+	if !status.GetConditions().GetSuccess() {
+		details := status.Details
+		if details == "" {
+			details = "failed"
+		}
+		fmt.Fprintf(os.Stderr, "algorithm %s: %s\n", status.Name, details)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(listenCmd)
+}
@@ -0,0 +1,273 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// printer renders API messages in the output format chosen by the user
+type printer struct {
+	Format string
+	Out    io.Writer
+
+	// headerDone is set once a table header was printed for a stream of messages
+	headerDone bool
+}
+
+func newPrinter(out io.Writer) (*printer, error) {
+	switch rootCmdOpts.Output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return nil, fmt.Errorf("unknown output format %q: valid formats are %s, %s and %s", rootCmdOpts.Output, outputTable, outputJSON, outputYAML)
+	}
+	return &printer{Format: rootCmdOpts.Output, Out: out}, nil
+}
+
+// Print renders a single message. In table format, table is used to render it.
+func (p *printer) Print(msg proto.Message, table func(w io.Writer) error) error {
+	switch p.Format {
+	case outputJSON:
+		content, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitUnpopulated: true}.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.Out, "%s\n", content)
+		return err
+	case outputYAML:
+		content, err := toYAML(msg)
+		if err != nil {
+			return err
+		}
+		_, err = p.Out.Write(content)
+		return err
+	default:
+		tw := tabwriter.NewWriter(p.Out, 0, 4, 2, ' ', 0)
+		if err := table(tw); err != nil {
+			return err
+		}
+		return tw.Flush()
+	}
+}
+
+// PrintStreamed renders a message which is part of a stream. JSON messages are printed
+// one per line, YAML messages as separate documents and table rows share a single header.
+func (p *printer) PrintStreamed(msg proto.Message, header string, row func() string) error {
+	switch p.Format {
+	case outputJSON:
+		content, err := protojson.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.Out, "%s\n", content)
+		return err
+	case outputYAML:
+		content, err := toYAML(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.Out, "---\n%s", content)
+		return err
+	default:
+		// every row is flushed on its own, hence the columns are padded to a fixed width
+		tw := tabwriter.NewWriter(p.Out, 16, 4, 2, ' ', 0)
+		if !p.headerDone {
+			fmt.Fprintln(tw, header)
+			p.headerDone = true
+		}
+		fmt.Fprintln(tw, row())
+		return tw.Flush()
+	}
+}
+
+func toYAML(msg proto.Message) ([]byte, error) {
+	content, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(content)
+}
+
+const statusHeader = "NAME\tOWNER\tPHASE\tSUCCESS\tFAILURES\tCREATED\tFINISHED"
+
+func statusRow(s *v1.AlgorithmStatus) string {
+	var success string
+	if s.Phase == v1.AlgorithmPhase_PHASE_DONE {
+		success = strconv.FormatBool(s.GetConditions().GetSuccess())
+	}
+	return strings.Join([]string{
+		s.Name,
+		s.GetMetadata().GetOwner(),
+		query.PhaseString(s.Phase),
+		success,
+		strconv.Itoa(int(s.GetConditions().GetFailureCount())),
+		formatTime(s.GetMetadata().GetCreated()),
+		formatTime(s.GetMetadata().GetFinished()),
+	}, "\t")
+}
+
+// printStatusTable renders a list of Algorithms as table
+func printStatusTable(w io.Writer, statuses []*v1.AlgorithmStatus) error {
+	fmt.Fprintln(w, statusHeader)
+	for _, s := range statuses {
+		fmt.Fprintln(w, statusRow(s))
+	}
+	return nil
+}
+
+// printStatusDetails renders all details of a single Algorithm
+func printStatusDetails(w io.Writer, s *v1.AlgorithmStatus) error {
+	var (
+		md    = s.GetMetadata()
+		conds = s.GetConditions()
+		repo  = md.GetRepository()
+	)
+	fields := [][2]string{
+		{"Name", s.Name},
+		{"Phase", query.PhaseString(s.Phase)},
+		{"Details", s.Details},
+		{"Owner", md.GetOwner()},
+		{"Spec", md.GetAlgorithmSpecName()},
+		{"Trigger", query.TriggerString(md.GetTrigger())},
+	}
+	if repo != nil {
+		rev := repo.Revision
+		if rev == "" {
+			rev = repo.Ref
+		}
+		fields = append(fields, [2]string{"Repository", fmt.Sprintf("%s/%s/%s@%s", repo.Host, repo.Owner, repo.Repo, rev)})
+	}
+	fields = append(fields,
+		[2]string{"Created", formatTime(md.GetCreated())},
+		[2]string{"Finished", formatTime(md.GetFinished())},
+		[2]string{"Wait until", formatTime(conds.GetWaitUntil())},
+		[2]string{"Success", strconv.FormatBool(conds.GetSuccess())},
+		[2]string{"Failure count", strconv.Itoa(int(conds.GetFailureCount()))},
+		[2]string{"Did execute", strconv.FormatBool(conds.GetDidExecute())},
+		[2]string{"Can replay", strconv.FormatBool(conds.GetCanReplay())},
+	)
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+	if annotations := md.GetAnnotations(); len(annotations) > 0 {
+		fmt.Fprintln(w, "Annotations:")
+		for _, a := range annotations {
+			fmt.Fprintf(w, "  %s:\t%s\n", a.Key, a.Value)
+		}
+	}
+	if len(s.Results) > 0 {
+		fmt.Fprintln(w, "Results:")
+		for _, r := range s.Results {
+			fmt.Fprintf(w, "  %s:\t%s\t%s\n", r.Type, r.Payload, r.Description)
+		}
+	}
+	return nil
+}
+
+func formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Local().Format(time.RFC3339)
+}
+
+const scheduleHeader = "NAME\tCRON\tTIME ZONE\tSPEC\tCONCURRENCY\tSUSPENDED\tNEXT RUN\tLAST ALGORITHM"
+
+func scheduleRow(s *v1.Schedule) string {
+	tz := s.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	return strings.Join([]string{
+		s.Name,
+		s.Cron,
+		tz,
+		s.AlgorithmSpecName,
+		concurrencyString(s.ConcurrencyPolicy),
+		strconv.FormatBool(s.Suspended),
+		formatTime(s.GetStatus().GetNextRun()),
+		s.GetStatus().GetLastAlgorithm(),
+	}, "\t")
+}
+
+// printScheduleTable renders a list of schedules as table
+func printScheduleTable(w io.Writer, schedules []*v1.Schedule) error {
+	fmt.Fprintln(w, scheduleHeader)
+	for _, s := range schedules {
+		fmt.Fprintln(w, scheduleRow(s))
+	}
+	return nil
+}
+
+// printScheduleDetails renders all details of a single schedule
+func printScheduleDetails(w io.Writer, s *v1.Schedule) error {
+	tz := s.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	st := s.GetStatus()
+	fields := [][2]string{
+		{"Name", s.Name},
+		{"Cron", s.Cron},
+		{"Time zone", tz},
+		{"Spec", s.AlgorithmSpecName},
+		{"Concurrency", concurrencyString(s.ConcurrencyPolicy)},
+		{"Suspended", strconv.FormatBool(s.Suspended)},
+		{"Owner", s.Owner},
+		{"Next run", formatTime(st.GetNextRun())},
+		{"Last run", formatTime(st.GetLastRun())},
+		{"Last algorithm", st.GetLastAlgorithm()},
+		{"Last details", st.GetLastDetails()},
+	}
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+	if len(s.Annotations) > 0 {
+		fmt.Fprintln(w, "Annotations:")
+		for _, a := range s.Annotations {
+			fmt.Fprintf(w, "  %s:\t%s\n", a.Key, a.Value)
+		}
+	}
+	return nil
+}
+
+// concurrencyString returns the name of a concurrency policy as used on the command line, e.g. "forbid"
+func concurrencyString(p v1.ConcurrencyPolicy) string {
+	return strings.ToLower(strings.TrimPrefix(p.String(), "CONCURRENCY_"))
+}
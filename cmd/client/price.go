@@ -0,0 +1,130 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/pricing"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+)
+
+var priceCmdOpts struct {
+	Curve      string
+	Instrument string
+}
+
+// priceCmd represents the price command
+var priceCmd = &cobra.Command{
+	Use:   "price",
+	Short: "Values an instrument on a term structure locally",
+	Long: `Values an instrument on a term structure without talking to a server and prints its
+present value and risk measures.
+
+The curve file holds the term structure in JSON, e.g. {"r": 2.5, "spread": 0} for a flat
+curve or the Nelson-Siegel-Svensson parameters of pkg/securities/term.json. The instrument
+file holds a v1.Instrument in JSON or YAML, e.g.
+
+  straightBond:
+    schedule: {settlement: "2021-04-01T00:00:00Z", maturity: "2031-06-30T00:00:00Z", frequency: 1}
+    coupon: 1.5
+    redemption: 100`,
+	Example: "  finance price --curve pkg/securities/term.json --instrument bond.yaml",
+	Args:    cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		curve, err := os.ReadFile(priceCmdOpts.Curve)
+		if err != nil {
+			log.WithError(err).Fatal("cannot read curve")
+		}
+		instrument, err := readInstrument(priceCmdOpts.Instrument)
+		if err != nil {
+			log.WithError(err).Fatal("cannot read instrument")
+		}
+
+		resp, err := pricing.NewService().Price(context.Background(), &v1.PriceRequest{
+			TermStructure: string(curve),
+			Instrument:    instrument,
+		})
+		if err != nil {
+			log.Fatalf("cannot price instrument: %s", status.Convert(err).Message())
+		}
+		err = p.Print(resp, func(w io.Writer) error {
+			fmt.Fprintf(w, "Present value:\t%.6f\n", resp.PresentValue)
+			if resp.Accrued != 0 {
+				fmt.Fprintf(w, "Accrued:\t%.6f\n", resp.Accrued)
+			}
+			if r := resp.TermRisk; r != nil {
+				fmt.Fprintf(w, "Duration:\t%.6f\n", r.Duration)
+				fmt.Fprintf(w, "Convexity:\t%.6f\n", r.Convexity)
+				fmt.Fprintf(w, "PVBP:\t%.6f\n", r.Pvbp)
+			}
+			if g := resp.Greeks; g != nil {
+				fmt.Fprintf(w, "Delta:\t%.6f\n", g.Delta)
+				fmt.Fprintf(w, "Gamma:\t%.6f\n", g.Gamma)
+				fmt.Fprintf(w, "Rho:\t%.6f\n", g.Rho)
+				fmt.Fprintf(w, "Vega:\t%.6f\n", g.Vega)
+			}
+			return nil
+		})
+		if err != nil {
+			log.WithError(err).Fatal("cannot print price")
+		}
+	},
+}
+
+// readInstrument reads an instrument spec in JSON or YAML
+func readInstrument(fn string) (*v1.Instrument, error) {
+	content, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	var res v1.Instrument
+	err = protojson.Unmarshal(content, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func init() {
+	rootCmd.AddCommand(priceCmd)
+	priceCmd.Flags().StringVar(&priceCmdOpts.Curve, "curve", "", "file containing the term structure in JSON")
+	priceCmd.Flags().StringVar(&priceCmdOpts.Instrument, "instrument", "", "file containing the instrument in JSON or YAML")
+	_ = priceCmd.MarkFlagRequired("curve")
+	_ = priceCmd.MarkFlagRequired("instrument")
+}
@@ -23,6 +23,8 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
@@ -36,6 +38,8 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -57,6 +61,13 @@ var rootCmdOpts struct {
 	K8sLabelSelector string
 	K8sPodPort       string
 	DialMode         string
+	Output           string
+	TLS              bool
+	TLSCA            string
+	TLSCert          string
+	TLSKey           string
+	TLSServerName    string
+	Token            string
 }
 
 // rootCmd represents the base command when called without any subcommands
@@ -119,6 +130,13 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.DialMode, "dial-mode", dialMode, "dial mode that determines how we connect to Bhojpur Finance. Valid values are \"host\" or \"kubernetes\" (defaults to FINANCE_DIAL_MODE env var).")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.Host, "host", financeHost, "[host dial mode] Bhojpur Finance host to talk to (defaults to FINANCE_HOST env var)")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.Kubeconfig, "kubeconfig", financeKubeconfig, "[kubernetes dial mode] kubeconfig file to use (defaults to KUEBCONFIG env var)")
+	rootCmd.PersistentFlags().BoolVar(&rootCmdOpts.TLS, "tls", os.Getenv("FINANCE_TLS") == "true", "connect using TLS, implied by --tls-ca and --tls-cert (defaults to FINANCE_TLS env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSCA, "tls-ca", os.Getenv("FINANCE_TLS_CA"), "PEM CA file to verify the server certificate with instead of the system's CAs (defaults to FINANCE_TLS_CA env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSCert, "tls-cert", os.Getenv("FINANCE_TLS_CERT"), "PEM client certificate file for mutual TLS (defaults to FINANCE_TLS_CERT env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSKey, "tls-key", os.Getenv("FINANCE_TLS_KEY"), "PEM key file of the client certificate (defaults to FINANCE_TLS_KEY env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSServerName, "tls-server-name", os.Getenv("FINANCE_TLS_SERVER_NAME"), "name to verify the server certificate against, e.g. when dialing through a port-forward (defaults to FINANCE_TLS_SERVER_NAME env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.Token, "token", os.Getenv("FINANCE_TOKEN"), "bearer token to authenticate with (defaults to FINANCE_TOKEN env var)")
+	rootCmd.PersistentFlags().StringVarP(&rootCmdOpts.Output, "output", "o", outputTable, "output format: table, json or yaml")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.K8sNamespace, "k8s-namespace", financeNamespace, "[kubernetes dial mode] Kubernetes namespace in which to look for the Bhojpur Finance pods (defaults to FINANCE_K8S_NAMESPACE env var, or configured kube context namespace)")
 	// The following are such specific flags that really only matters if one doesn't use the stock helm charts.
 	// They can still be set using an env var, but there's no need to clutter the CLI with them.
@@ -132,12 +150,15 @@ type closableGrpcClientConnInterface interface {
 }
 
 func dial() (res closableGrpcClientConnInterface) {
-	var err error
+	opts, err := dialOptions()
+	if err != nil {
+		log.WithError(err).Fatal("cannot set up connection security")
+	}
 	switch rootCmdOpts.DialMode {
 	case dialModeHost:
-		res, err = grpc.Dial(rootCmdOpts.Host, grpc.WithInsecure())
+		res, err = grpc.Dial(rootCmdOpts.Host, opts...)
 	case dialModeKubernetes:
-		res, err = dialKubernetes()
+		res, err = dialKubernetes(opts)
 	default:
 		log.Fatalf("unknown dial mode: %s", rootCmdOpts.DialMode)
 	}
@@ -148,7 +169,59 @@ func dial() (res closableGrpcClientConnInterface) {
 	return
 }
 
-func dialKubernetes() (closableGrpcClientConnInterface, error) {
+// dialOptions configures transport security and authentication according to the flags
+func dialOptions() ([]grpc.DialOption, error) {
+	var (
+		opts   []grpc.DialOption
+		useTLS = rootCmdOpts.TLS || rootCmdOpts.TLSCA != "" || rootCmdOpts.TLSCert != ""
+	)
+	if useTLS {
+		cfg := &tls.Config{
+			ServerName: rootCmdOpts.TLSServerName,
+			MinVersion: tls.VersionTLS12,
+		}
+		if rootCmdOpts.TLSCA != "" {
+			pem, err := os.ReadFile(rootCmdOpts.TLSCA)
+			if err != nil {
+				return nil, err
+			}
+			cfg.RootCAs = x509.NewCertPool()
+			if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", rootCmdOpts.TLSCA)
+			}
+		}
+		if rootCmdOpts.TLSCert != "" || rootCmdOpts.TLSKey != "" {
+			cert, err := tls.LoadX509KeyPair(rootCmdOpts.TLSCert, rootCmdOpts.TLSKey)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Certificates = []tls.Certificate{cert}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if rootCmdOpts.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{Token: rootCmdOpts.Token, Secure: useTLS}))
+	}
+	return opts, nil
+}
+
+// tokenCredentials sends a bearer token with every call
+type tokenCredentials struct {
+	Token  string
+	Secure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.Token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.Secure
+}
+
+func dialKubernetes(opts []grpc.DialOption) (closableGrpcClientConnInterface, error) {
 	kubecfg, namespace, err := getKubeconfig(rootCmdOpts.Kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load kubeconfig %s: %w", rootCmdOpts.Kubeconfig, err)
@@ -162,31 +235,30 @@ func dialKubernetes() (closableGrpcClientConnInterface, error) {
 		return nil, err
 	}
 
-	pod, err := findFinancePod(clientSet, namespace, rootCmdOpts.K8sLabelSelector)
-	if err != nil {
-		return nil, fmt.Errorf("cannot find Bhojpur Finance pod: %w", err)
+	d := &podDialer{
+		Config:    kubecfg,
+		Client:    clientSet,
+		Namespace: namespace,
+		Selector:  rootCmdOpts.K8sLabelSelector,
+		PodPort:   rootCmdOpts.K8sPodPort,
 	}
-
-	localPort, err := findFreeLocalPort()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	readychan, errchan := forwardPort(ctx, kubecfg, namespace, pod, fmt.Sprintf("%d:%s", localPort, rootCmdOpts.K8sPodPort))
-	select {
-	case err := <-errchan:
-		cancel()
+	// forward right away so that a missing pod is reported before any call is made
+	if _, err := d.forward(context.Background()); err != nil {
 		return nil, err
-	case <-readychan:
 	}
 
-	res, err := grpc.Dial(fmt.Sprintf("localhost:%d", localPort), grpc.WithInsecure())
+	res, err := grpc.Dial("passthrough:///localhost", append(opts, grpc.WithContextDialer(d.Dial))...)
 	if err != nil {
-		cancel()
+		d.Close()
 		return nil, fmt.Errorf("cannot dial forwarded connection: %w", err)
 	}
 
 	return closableConn{
 		ClientConnInterface: res,
-		Closer:              func() error { cancel(); return nil },
+		Closer: func() error {
+			res.Close()
+			return d.Close()
+		},
 	}, nil
 }
 
@@ -199,21 +271,6 @@ func (c closableConn) Close() error {
 	return c.Closer()
 }
 
-func findFreeLocalPort() (int, error) {
-	const (
-		start = 30000
-		end   = 60000
-	)
-	for p := start; p <= end; p++ {
-		l, err := net.Listen("tcp", fmt.Sprintf(":%d", p))
-		if err == nil {
-			l.Close()
-			return p, nil
-		}
-	}
-	return 0, fmt.Errorf("no free local port found")
-}
-
 // GetKubeconfig loads kubernetes connection config from a kubeconfig file
 func getKubeconfig(kubeconfig string) (res *rest.Config, namespace string, err error) {
 	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
@@ -233,73 +290,176 @@ func getKubeconfig(kubeconfig string) (res *rest.Config, namespace string, err e
 	return res, namespace, nil
 }
 
-// findFinancePod returns the first pod we found for a particular component
-func findFinancePod(clientSet kubernetes.Interface, namespace, selector string) (podName string, err error) {
-	pods, err := clientSet.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+// findFinancePod returns the name of a ready pod matching the selector
+func findFinancePod(ctx context.Context, clientSet kubernetes.Interface, namespace, selector string) (podName string, err error) {
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: selector,
 	})
 	if err != nil {
 		return "", err
 	}
-	if len(pods.Items) == 0 {
-		return "", fmt.Errorf("no pod in %s with label component=%s", namespace, selector)
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && isPodReady(&pod) {
+			return pod.Name, nil
+		}
 	}
-	return pods.Items[0].Name, nil
+	return "", fmt.Errorf("no ready pod in %s matching %s", namespace, selector)
 }
 
-// ForwardPort establishes a TCP port forwarding to a Kubernetes pod
-func forwardPort(ctx context.Context, config *rest.Config, namespace, pod, port string) (readychan chan struct{}, errchan chan error) {
-	errchan = make(chan error, 1)
-	readychan = make(chan struct{}, 1)
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
 
-	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
+// podDialer connects to a ready Bhojpur Finance pod through a port-forward. Once the
+// forward ends, e.g. because the pod restarted, the next dial selects a pod anew.
+type podDialer struct {
+	Config    *rest.Config
+	Client    kubernetes.Interface
+	Namespace string
+	Selector  string
+	PodPort   string
+
+	mu  sync.Mutex
+	fwd *portForward
+}
+
+// forward returns the current port-forward, establishing a new one if there is none
+func (d *podDialer) forward(ctx context.Context) (*portForward, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.fwd != nil && !d.fwd.Closed() {
+		return d.fwd, nil
+	}
+
+	pod, err := findFinancePod(ctx, d.Client, d.Namespace, d.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find Bhojpur Finance pod: %w", err)
+	}
+	fwd, err := forwardPort(ctx, d.Config, d.Namespace, pod, d.PodPort)
 	if err != nil {
-		errchan <- err
-		return
+		return nil, fmt.Errorf("cannot forward port to %s: %w", pod, err)
 	}
+	log.WithField("pod", pod).WithField("port", fwd.LocalPort).Debug("forwarding to Bhojpur Finance pod")
+	d.fwd = fwd
+	return fwd, nil
+}
 
-	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace, pod)
-	hostIP := strings.TrimLeft(config.Host, "https://")
-	serverURL := url.URL{Scheme: "https", Path: path, Host: hostIP}
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, http.MethodPost, &serverURL)
+// Dial is a grpc context dialer which ignores the address and connects through the port-forward
+func (d *podDialer) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	fwd, err := d.forward(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	stopChan := make(chan struct{}, 1)
-	fwdReadyChan := make(chan struct{}, 1)
-	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
-	forwarder, err := portforward.New(dialer, []string{port}, stopChan, fwdReadyChan, out, errOut)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("127.0.0.1:%d", fwd.LocalPort))
 	if err != nil {
-		panic(err)
+		// the forward is of no use anymore - the next attempt sets up a new one
+		fwd.Stop()
+		return nil, err
 	}
+	return conn, nil
+}
 
-	var once sync.Once
-	go func() {
-		err := forwarder.ForwardPorts()
-		if err != nil {
-			errchan <- err
-		}
-		once.Do(func() { close(readychan) })
-	}()
+// Close stops the current port-forward
+func (d *podDialer) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-	go func() {
-		select {
-		case <-readychan:
-			// we're out of here
-		case <-ctx.Done():
-			close(stopChan)
-		}
-	}()
+	if d.fwd != nil {
+		d.fwd.Stop()
+	}
+	return nil
+}
 
-	go func() {
-		for range fwdReadyChan {
-		}
+// portForward is a TCP port-forward from a local port to a pod
+type portForward struct {
+	LocalPort uint16
+	Done      <-chan struct{}
 
-		if errOut.Len() != 0 {
-			errchan <- fmt.Errorf(errOut.String())
-			return
-		}
+	stop chan struct{}
+	once sync.Once
+}
+
+// Stop ends the port-forward
+func (f *portForward) Stop() {
+	f.once.Do(func() { close(f.stop) })
+}
+
+// Closed returns true once the port-forward was stopped or has ended by itself
+func (f *portForward) Closed() bool {
+	select {
+	case <-f.stop:
+		return true
+	case <-f.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// forwardPort establishes a TCP port-forward from a local port chosen by the OS to a Kubernetes pod
+func forwardPort(ctx context.Context, config *rest.Config, namespace, pod, podPort string) (*portForward, error) {
+	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+	if serverURL.Host == "" {
+		serverURL = &url.URL{Scheme: "https", Host: config.Host}
+	}
+	serverURL.Path = strings.TrimSuffix(serverURL.Path, "/") + fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace, pod)
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, http.MethodPost, serverURL)
+
+	var (
+		stop   = make(chan struct{})
+		ready  = make(chan struct{})
+		done   = make(chan struct{})
+		errOut bytes.Buffer
+	)
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{":" + podPort}, stop, ready, io.Discard, &errOut)
+	if err != nil {
+		return nil, err
+	}
 
-		once.Do(func() { close(readychan) })
+	var fwdErr error
+	go func() {
+		fwdErr = forwarder.ForwardPorts()
+		close(done)
 	}()
 
-	return
+	res := &portForward{Done: done, stop: stop}
+	select {
+	case <-ready:
+	case <-done:
+		if fwdErr == nil {
+			fwdErr = fmt.Errorf("port-forward ended unexpectedly: %s", strings.TrimSpace(errOut.String()))
+		}
+		return nil, fwdErr
+	case <-ctx.Done():
+		res.Stop()
+		return nil, ctx.Err()
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		res.Stop()
+		return nil, err
+	}
+	if len(ports) == 0 {
+		res.Stop()
+		return nil, fmt.Errorf("port-forward has no local port")
+	}
+	res.LocalPort = ports[0].Local
+	return res, nil
 }
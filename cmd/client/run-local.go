@@ -0,0 +1,160 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/archive"
+	"github.com/bhojpur/finance/pkg/finance"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// uploadChunkSize is the size of the application tar chunks sent to the server
+const uploadChunkSize = 32 * 1024
+
+var runLocalCmdOpts struct {
+	ConfigPath    string
+	AlgorithmPath string
+	Annotations   []string
+}
+
+// runLocalCmd represents the run local command
+var runLocalCmd = &cobra.Command{
+	Use:   "local",
+	Short: "Uploads the working directory and starts an Algorithm from it",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		wd, err := os.Getwd()
+		if err != nil {
+			log.WithError(err).Fatal("cannot determine working directory")
+		}
+
+		configYAML, err := os.ReadFile(filepath.Join(wd, runLocalCmdOpts.ConfigPath))
+		if err != nil {
+			log.WithError(err).Fatal("cannot read config")
+		}
+		cfg, err := finance.ParseRepoConfig(configYAML)
+		if err != nil {
+			log.WithError(err).Fatal("cannot read config")
+		}
+		algorithmPath := runLocalCmdOpts.AlgorithmPath
+		if algorithmPath == "" {
+			algorithmPath = cfg.DefaultAlgorithm
+		}
+		if algorithmPath == "" {
+			log.Fatalf("%s has no defaultAlgorithm - please use --algorithm", runLocalCmdOpts.ConfigPath)
+		}
+		algorithmYAML, err := os.ReadFile(filepath.Join(wd, algorithmPath))
+		if err != nil {
+			log.WithError(err).Fatal("cannot read algorithm")
+		}
+		annotations, err := parseAnnotations(runLocalCmdOpts.Annotations)
+		if err != nil {
+			log.WithError(err).Fatal("invalid annotation")
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		status, err := uploadLocalAlgorithm(ctx, client, wd, &v1.AlgorithmMetadata{
+			Owner:             currentUser(),
+			Trigger:           v1.AlgorithmTrigger_TRIGGER_MANUAL,
+			Annotations:       annotations,
+			AlgorithmSpecName: finance.SpecNameFromPath(algorithmPath),
+		}, configYAML, algorithmYAML)
+		if err != nil {
+			log.WithError(err).Fatal("cannot start algorithm")
+		}
+		fmt.Println(status.Name)
+	},
+}
+
+// uploadLocalAlgorithm streams the application in dir to the server in the order the protocol demands
+func uploadLocalAlgorithm(ctx context.Context, client v1.FinanceServiceClient, dir string, md *v1.AlgorithmMetadata, configYAML, algorithmYAML []byte) (*v1.AlgorithmStatus, error) {
+	stream, err := client.StartLocalAlgorithm(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := []*v1.StartLocalAlgorithmRequest{
+		{Content: &v1.StartLocalAlgorithmRequest_Metadata{Metadata: md}},
+		{Content: &v1.StartLocalAlgorithmRequest_ConfigYaml{ConfigYaml: configYAML}},
+		{Content: &v1.StartLocalAlgorithmRequest_AlgorithmYaml{AlgorithmYaml: algorithmYAML}},
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		err := archive.Create(w, dir, func(path string) bool { return path == ".git" })
+		w.CloseWithError(err)
+	}()
+	defer r.Close()
+
+	buf := make([]byte, uploadChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			serr := stream.Send(&v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_ApplicationTar{ApplicationTar: chunk}})
+			if serr != nil {
+				return nil, serr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot archive %s: %w", dir, err)
+		}
+	}
+
+	err = stream.Send(&v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_ApplicationTarDone{ApplicationTarDone: true}})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+func init() {
+	runCmd.AddCommand(runLocalCmd)
+	runLocalCmd.Flags().StringVar(&runLocalCmdOpts.ConfigPath, "config", "finance/config.yaml", "path of the config file relative to the working directory")
+	runLocalCmd.Flags().StringVar(&runLocalCmdOpts.AlgorithmPath, "algorithm", "", "path of the Algorithm YAML relative to the working directory (defaults to the config's defaultAlgorithm)")
+	runLocalCmd.Flags().StringArrayVarP(&runLocalCmdOpts.Annotations, "annotation", "a", nil, "adds an annotation to the Algorithm (key=value)")
+}
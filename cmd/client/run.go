@@ -0,0 +1,62 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Starts the execution of an Algorithm",
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+}
+
+// parseAnnotations turns a list of key=value pairs into annotations
+func parseAnnotations(pairs []string) ([]*v1.Annotation, error) {
+	res := make([]*v1.Annotation, 0, len(pairs))
+	for _, p := range pairs {
+		segs := strings.SplitN(p, "=", 2)
+		if len(segs) != 2 || segs[0] == "" {
+			return nil, fmt.Errorf("invalid annotation %q: expected key=value", p)
+		}
+		res = append(res, &v1.Annotation{Key: segs[0], Value: segs[1]})
+	}
+	return res, nil
+}
+
+// currentUser returns the name of the user running this command
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
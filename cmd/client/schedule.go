@@ -0,0 +1,246 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmdOpts struct {
+	Cron        string
+	TimeZone    string
+	Spec        string
+	Annotations []string
+	Concurrency string
+	Suspended   bool
+}
+
+// scheduleCmd represents the schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manages schedules which start Algorithms on a cron calendar",
+	Args:  cobra.ExactArgs(0),
+}
+
+// scheduleCreateCmd represents the schedule create command
+var scheduleCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Creates a schedule",
+	Long: `Creates a schedule which starts an Algorithm of the server's specs directory on a cron calendar.
+Cron expressions have five fields (minute, hour, day of month, month and day of week), or are one
+of @yearly, @monthly, @weekly, @daily and @hourly.`,
+	Example: `  finance schedule create nightly-revaluation --spec revaluation --cron "0 22 * * mon-fri" --time-zone Asia/Kolkata -a desk=rates --concurrency forbid`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sched := &v1.Schedule{Name: args[0], Owner: currentUser()}
+		if err := applyScheduleFlags(cmd, sched); err != nil {
+			log.Fatal(err)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		resp, err := client.CreateSchedule(context.Background(), &v1.CreateScheduleRequest{Schedule: sched})
+		if err != nil {
+			log.WithError(err).Fatal("cannot create schedule")
+		}
+		err = p.Print(resp.Schedule, func(w io.Writer) error { return printScheduleDetails(w, resp.Schedule) })
+		if err != nil {
+			log.WithError(err).Fatal("cannot print schedule")
+		}
+	},
+}
+
+// scheduleUpdateCmd represents the schedule update command
+var scheduleUpdateCmd = &cobra.Command{
+	Use:     "update <name>",
+	Short:   "Changes a schedule",
+	Long:    `Changes the fields of a schedule which are given as flags. Annotations, if given, replace all previous annotations.`,
+	Example: `  finance schedule update nightly-revaluation --suspended`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+		ctx := context.Background()
+
+		prev, err := client.GetSchedule(ctx, &v1.GetScheduleRequest{Name: args[0]})
+		if err != nil {
+			log.WithError(err).Fatal("cannot get schedule")
+		}
+		sched := prev.Schedule
+		if err := applyScheduleFlags(cmd, sched); err != nil {
+			log.Fatal(err)
+		}
+		resp, err := client.UpdateSchedule(ctx, &v1.UpdateScheduleRequest{Schedule: sched})
+		if err != nil {
+			log.WithError(err).Fatal("cannot update schedule")
+		}
+		err = p.Print(resp.Schedule, func(w io.Writer) error { return printScheduleDetails(w, resp.Schedule) })
+		if err != nil {
+			log.WithError(err).Fatal("cannot print schedule")
+		}
+	},
+}
+
+// scheduleListCmd represents the schedule list command
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists all schedules",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		resp, err := client.ListSchedules(context.Background(), &v1.ListSchedulesRequest{})
+		if err != nil {
+			log.WithError(err).Fatal("cannot list schedules")
+		}
+		err = p.Print(resp, func(w io.Writer) error { return printScheduleTable(w, resp.Schedules) })
+		if err != nil {
+			log.WithError(err).Fatal("cannot print schedules")
+		}
+	},
+}
+
+// scheduleGetCmd represents the schedule get command
+var scheduleGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Prints a schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		resp, err := client.GetSchedule(context.Background(), &v1.GetScheduleRequest{Name: args[0]})
+		if err != nil {
+			log.WithError(err).Fatal("cannot get schedule")
+		}
+		err = p.Print(resp.Schedule, func(w io.Writer) error { return printScheduleDetails(w, resp.Schedule) })
+		if err != nil {
+			log.WithError(err).Fatal("cannot print schedule")
+		}
+	},
+}
+
+// scheduleDeleteCmd represents the schedule delete command
+var scheduleDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Deletes a schedule, Algorithms it started keep running",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		_, err := client.DeleteSchedule(context.Background(), &v1.DeleteScheduleRequest{Name: args[0]})
+		if err != nil {
+			log.WithError(err).Fatal("cannot delete schedule")
+		}
+	},
+}
+
+// applyScheduleFlags sets the fields of a schedule which were given as flags
+func applyScheduleFlags(cmd *cobra.Command, sched *v1.Schedule) error {
+	flags := cmd.Flags()
+	if flags.Changed("cron") {
+		sched.Cron = scheduleCmdOpts.Cron
+	}
+	if flags.Changed("time-zone") {
+		sched.TimeZone = scheduleCmdOpts.TimeZone
+	}
+	if flags.Changed("spec") {
+		sched.AlgorithmSpecName = scheduleCmdOpts.Spec
+	}
+	if flags.Changed("annotation") {
+		annotations, err := parseAnnotations(scheduleCmdOpts.Annotations)
+		if err != nil {
+			return err
+		}
+		sched.Annotations = annotations
+	}
+	if flags.Changed("concurrency") {
+		policy, err := parseConcurrencyPolicy(scheduleCmdOpts.Concurrency)
+		if err != nil {
+			return err
+		}
+		sched.ConcurrencyPolicy = policy
+	}
+	if flags.Changed("suspended") {
+		sched.Suspended = scheduleCmdOpts.Suspended
+	}
+	return nil
+}
+
+// parseConcurrencyPolicy parses allow, forbid or replace
+func parseConcurrencyPolicy(s string) (v1.ConcurrencyPolicy, error) {
+	res, ok := v1.ConcurrencyPolicy_value["CONCURRENCY_"+strings.ToUpper(s)]
+	if !ok {
+		return 0, fmt.Errorf("invalid concurrency policy %q: valid policies are allow, forbid and replace", s)
+	}
+	return v1.ConcurrencyPolicy(res), nil
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleCreateCmd, scheduleUpdateCmd, scheduleListCmd, scheduleGetCmd, scheduleDeleteCmd)
+
+	for _, cmd := range []*cobra.Command{scheduleCreateCmd, scheduleUpdateCmd} {
+		cmd.Flags().StringVar(&scheduleCmdOpts.Cron, "cron", "", "cron expression which determines when the Algorithm is started")
+		cmd.Flags().StringVar(&scheduleCmdOpts.TimeZone, "time-zone", "", "IANA time zone the cron expression is evaluated in (defaults to UTC)")
+		cmd.Flags().StringVar(&scheduleCmdOpts.Spec, "spec", "", "name of the Algorithm spec in the server's specs directory")
+		cmd.Flags().StringArrayVarP(&scheduleCmdOpts.Annotations, "annotation", "a", nil, "adds an annotation to the started Algorithms (key=value)")
+		cmd.Flags().StringVar(&scheduleCmdOpts.Concurrency, "concurrency", "allow", "what to do while a previous run is still running: allow, forbid or replace")
+		cmd.Flags().BoolVar(&scheduleCmdOpts.Suspended, "suspended", false, "suspends the schedule, i.e. it doesn't start Algorithms")
+	}
+	_ = scheduleCreateCmd.MarkFlagRequired("cron")
+	_ = scheduleCreateCmd.MarkFlagRequired("spec")
+}
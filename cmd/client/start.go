@@ -0,0 +1,152 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/finance"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var startCmdOpts struct {
+	Annotations []string
+	NameSuffix  string
+	WaitUntil   string
+	From        string
+	GitOpsToken string
+	Follow      bool
+}
+
+// startCmd represents the start command
+var startCmd = &cobra.Command{
+	Use:   "start [algorithm.yaml]",
+	Short: "Starts an Algorithm from its YAML file or from a previous Algorithm",
+	Example: `  finance start algorithms/eod-curve.yaml -a curve=EUR
+  finance start --from eod-curve.3 --wait-until 30m`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if startCmdOpts.From != "" {
+			if len(startCmdOpts.Annotations) > 0 || startCmdOpts.NameSuffix != "" {
+				return fmt.Errorf("--annotation and --suffix cannot be used with --from")
+			}
+			return cobra.ExactArgs(0)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		waitUntil, err := parseWaitUntil(startCmdOpts.WaitUntil, time.Now())
+		if err != nil {
+			log.WithError(err).Fatal("invalid --wait-until")
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+		ctx := context.Background()
+
+		var resp *v1.StartAlgorithmResponse
+		if startCmdOpts.From != "" {
+			resp, err = client.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{
+				PreviousAlgorithm: startCmdOpts.From,
+				GitopsToken:       startCmdOpts.GitOpsToken,
+				WaitUntil:         waitUntil,
+			})
+		} else {
+			var algorithmYAML []byte
+			algorithmYAML, err = os.ReadFile(args[0])
+			if err != nil {
+				log.WithError(err).Fatal("cannot read algorithm")
+			}
+			annotations, err := parseAnnotations(startCmdOpts.Annotations)
+			if err != nil {
+				log.WithError(err).Fatal("invalid annotation")
+			}
+			resp, err = client.StartAlgorithm(ctx, &v1.StartAlgorithmRequest{
+				Metadata: &v1.AlgorithmMetadata{
+					Owner:             currentUser(),
+					Trigger:           v1.AlgorithmTrigger_TRIGGER_MANUAL,
+					Annotations:       annotations,
+					AlgorithmSpecName: finance.SpecNameFromPath(args[0]),
+				},
+				AlgorithmPath: args[0],
+				AlgorithmYaml: algorithmYAML,
+				NameSuffix:    startCmdOpts.NameSuffix,
+				WaitUntil:     waitUntil,
+			})
+		}
+		if err != nil {
+			log.WithError(err).Fatal("cannot start algorithm")
+		}
+
+		err = p.Print(resp.Status, func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, resp.Status.Name)
+			return err
+		})
+		if err != nil {
+			log.WithError(err).Fatal("cannot print algorithm")
+		}
+		if !startCmdOpts.Follow {
+			return
+		}
+
+		status, err := listenAlgorithm(ctx, client, resp.Status.Name, p)
+		if err != nil {
+			log.WithError(err).Fatal("cannot listen to algorithm")
+		}
+		exitOnFailure(status)
+	},
+}
+
+// parseWaitUntil parses either a point in time (RFC3339) or a duration relative to now
+func parseWaitUntil(s string, now time.Time) (*timestamppb.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return timestamppb.New(now.Add(d)), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither a duration nor an RFC3339 time", s)
+	}
+	return timestamppb.New(t), nil
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().StringArrayVarP(&startCmdOpts.Annotations, "annotation", "a", nil, "adds an annotation to the Algorithm (key=value)")
+	startCmd.Flags().StringVar(&startCmdOpts.NameSuffix, "suffix", "", "suffix to add to the Algorithm's name")
+	startCmd.Flags().StringVar(&startCmdOpts.WaitUntil, "wait-until", "", "delays the start until this time (RFC3339) or for this duration (e.g. 30m)")
+	startCmd.Flags().StringVar(&startCmdOpts.From, "from", "", "starts the Algorithm with the spec and metadata of a previous one")
+	startCmd.Flags().StringVar(&startCmdOpts.GitOpsToken, "gitops-token", os.Getenv("FINANCE_GITOPS_TOKEN"), "with --from, keeps the Git trigger of the previous Algorithm (defaults to FINANCE_GITOPS_TOKEN env var)")
+	startCmd.Flags().BoolVarP(&startCmdOpts.Follow, "follow", "f", false, "follows the log output of the Algorithm once started")
+}
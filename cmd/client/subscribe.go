@@ -0,0 +1,128 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+var subscribeCmdOpts struct {
+	Filter []string
+}
+
+// subscribeCmd represents the subscribe command
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe",
+	Short: "Prints updates of Algorithms as they happen",
+	Long: `Prints updates of all Algorithms matching the filters until interrupted.
+Filters have the same syntax as those of the list command.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := newPrinter(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filter, err := parseFilter(subscribeCmdOpts.Filter)
+		if err != nil {
+			log.WithError(err).Fatal("invalid filter")
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewFinanceServiceClient(conn)
+
+		err = subscribe(context.Background(), client, filter, p)
+		if err != nil {
+			log.WithError(err).Fatal("subscription failed")
+		}
+	},
+}
+
+// subscribe prints updates of all Algorithms matching the filter. If the connection breaks it
+// reconnects and prints what changed in the meantime, so that no update goes unnoticed.
+func subscribe(ctx context.Context, client v1.FinanceServiceClient, filter []*v1.FilterExpression, p *printer) error {
+	var (
+		since       = time.Now()
+		last        = make(map[string]*v1.AlgorithmStatus)
+		printStatus = func(s *v1.AlgorithmStatus) error {
+			if proto.Equal(last[s.Name], s) {
+				return nil
+			}
+			last[s.Name] = s
+			return p.PrintStreamed(s, statusHeader, func() string { return statusRow(s) })
+		}
+		reconnected bool
+	)
+	return keepStreaming(ctx, "subscription", func(ctx context.Context) error {
+		sub, err := client.Subscribe(ctx, &v1.SubscribeRequest{Filter: filter}, grpc.WaitForReady(true))
+		if err != nil {
+			return err
+		}
+
+		if reconnected {
+			// catch up on the updates we missed while we were disconnected
+			resp, err := client.ListAlgorithm(ctx, &v1.ListAlgorithmRequest{
+				Filter: filter,
+				Order:  []*v1.OrderExpression{{Field: "metadata.created", Ascending: true}},
+			})
+			if err != nil {
+				return err
+			}
+			for _, s := range resp.Result {
+				_, known := last[s.Name]
+				if !known && s.GetMetadata().GetCreated().AsTime().Before(since) {
+					continue
+				}
+				if err := printStatus(s); err != nil {
+					return err
+				}
+			}
+		}
+		reconnected = true
+
+		for {
+			resp, err := sub.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := printStatus(resp.Result); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(subscribeCmd)
+	subscribeCmd.Flags().StringArrayVar(&subscribeCmdOpts.Filter, "filter", nil, "only print updates of Algorithms matching this filter (field==value), can be repeated")
+}
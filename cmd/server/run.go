@@ -0,0 +1,429 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"github.com/bhojpur/finance/pkg/executor/kubernetes"
+	"github.com/bhojpur/finance/pkg/executor/local"
+	"github.com/bhojpur/finance/pkg/finance"
+	"github.com/bhojpur/finance/pkg/loan"
+	"github.com/bhojpur/finance/pkg/pricing"
+	"github.com/bhojpur/finance/pkg/store/postgres"
+	"github.com/bhojpur/finance/pkg/telemetry"
+	"github.com/bhojpur/finance/pkg/webhook"
+	"github.com/bhojpur/finance/pkg/webui"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var runCmdOpts struct {
+	Port          int
+	WorkspaceDir  string
+	MaxUploadSize int64
+	DBDSN         string
+	SpecsDir      string
+	ReadOnly      bool
+	Executor      string
+	K8sNamespace  string
+	K8sImage      string
+	K8sKeepPods   time.Duration
+	TLSCert       string
+	TLSKey        string
+	TLSClientCA   string
+	AuthTokens    string
+	Admins        []string
+	WebhookSecret string
+	GitOpsToken   string
+	Retention     finance.RetentionPolicy
+	PruneInterval time.Duration
+	MetricsPort   int
+}
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Starts the Bhojpur Finance server",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		l, err := net.Listen("tcp", fmt.Sprintf(":%d", runCmdOpts.Port))
+		if err != nil {
+			log.WithError(err).Fatal("cannot start server")
+		}
+
+		cfg := finance.Config{
+			WorkspaceDir:  runCmdOpts.WorkspaceDir,
+			MaxUploadSize: runCmdOpts.MaxUploadSize,
+			SpecsDir:      runCmdOpts.SpecsDir,
+			ReadOnly:      runCmdOpts.ReadOnly,
+			GitOpsToken:   runCmdOpts.GitOpsToken,
+			Retention:     runCmdOpts.Retention,
+		}
+		if runCmdOpts.DBDSN != "" {
+			db, err := sql.Open("postgres", runCmdOpts.DBDSN)
+			if err != nil {
+				log.WithError(err).Fatal("cannot connect to database")
+			}
+			err = db.Ping()
+			if err != nil {
+				log.WithError(err).Fatal("cannot connect to database")
+			}
+			err = postgres.Migrate(db)
+			if err != nil {
+				log.WithError(err).Fatal("cannot migrate database")
+			}
+			cfg.Algorithms = postgres.NewAlgorithms(db)
+			cfg.Logs = postgres.NewLogs(db)
+			cfg.Schedules = postgres.NewSchedules(db)
+		} else {
+			log.Warn("no database configured, algorithms will be kept in memory only")
+		}
+		// every executor runs whatever callers start, only trusted callers may do so
+		if runCmdOpts.Executor != "none" && runCmdOpts.AuthTokens == "" && runCmdOpts.TLSClientCA == "" {
+			log.WithField("executor", runCmdOpts.Executor).Fatal("the executor runs the commands of any caller, it requires --auth-tokens or --tls-client-ca")
+		}
+		switch runCmdOpts.Executor {
+		case "local":
+			cfg.Executor = local.NewExecutor(local.Config{})
+		case "kubernetes":
+			exec, err := newKubernetesExecutor()
+			if err != nil {
+				log.WithError(err).Fatal("cannot connect to Kubernetes")
+			}
+			go collectGarbage(exec)
+			cfg.Executor = exec
+		case "none":
+			log.Warn("no executor configured, algorithms will not run")
+		default:
+			log.WithField("executor", runCmdOpts.Executor).Fatal("unknown executor")
+		}
+
+		service := finance.NewService(cfg)
+		if err := service.Start(); err != nil {
+			log.WithError(err).Fatal("cannot start service")
+		}
+		if runCmdOpts.Retention != (finance.RetentionPolicy{}) && !runCmdOpts.ReadOnly {
+			go pruneAlgorithms(service, runCmdOpts.PruneInterval)
+		}
+		authenticator, err := newAuthenticator()
+		if err != nil {
+			log.WithError(err).Fatal("cannot set up authentication")
+		}
+		tlsConfig, err := serverTLSConfig()
+		if err != nil {
+			log.WithError(err).Fatal("cannot set up TLS")
+		}
+		if authenticator.Enabled() && tlsConfig == nil {
+			log.Warn("authentication is enabled without TLS, tokens will be sent in plaintext")
+		}
+		ui := finance.NewUIService(service.Config)
+		pricingService := pricing.NewService()
+		loanService := loan.NewService()
+		healthService := health.NewServer()
+		interceptors := telemetry.NewInterceptors()
+		newGRPCServer := func() *grpc.Server {
+			// requests are measured before authentication so that rejected ones count as well
+			res := grpc.NewServer(
+				grpc.ChainUnaryInterceptor(interceptors.UnaryInterceptor(), authenticator.UnaryInterceptor()),
+				grpc.ChainStreamInterceptor(interceptors.StreamInterceptor(), authenticator.StreamInterceptor()),
+			)
+			grpc_health_v1.RegisterHealthServer(res, healthService)
+			v1.RegisterFinanceServiceServer(res, service)
+			v1.RegisterFinanceUIServer(res, ui)
+			v1.RegisterPricingServiceServer(res, pricingService)
+			v1.RegisterLoanServiceServer(res, loanService)
+			return res
+		}
+		grpcServer := newGRPCServer()
+
+		// The web UI talks to the gRPC services through a plaintext listener which only accepts local
+		// connections. Its users authenticate with the token the UI forwards, not with client certificates.
+		internalListener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.WithError(err).Fatal("cannot start internal listener")
+		}
+		internalServer := newGRPCServer()
+		go func() {
+			err := internalServer.Serve(internalListener)
+			if err != nil {
+				log.WithError(err).Fatal("cannot serve internal listener")
+			}
+		}()
+		conn, err := grpc.Dial(internalListener.Addr().String(), grpc.WithInsecure())
+		if err != nil {
+			log.WithError(err).Fatal("cannot connect web UI to gRPC services")
+		}
+		defer conn.Close()
+		web := webui.Handler(v1.NewFinanceServiceClient(conn), v1.NewFinanceUIClient(conn), v1.NewPricingServiceClient(conn), v1.NewLoanServiceClient(conn))
+		if runCmdOpts.WebhookSecret != "" {
+			// deliveries are verified by their signature, not by the authenticator
+			mux := http.NewServeMux()
+			mux.Handle("/webhook", &webhook.Handler{Secret: runCmdOpts.WebhookSecret, OnEvent: service.StartFromEvent})
+			mux.Handle("/", web)
+			web = mux
+		}
+
+		httpServer := &http.Server{
+			Handler:   h2c.NewHandler(grpcOrHTTP(grpcServer, web), &http2.Server{}),
+			TLSConfig: tlsConfig,
+		}
+		go func() {
+			var err error
+			if tlsConfig != nil {
+				err = httpServer.ServeTLS(l, "", "")
+			} else {
+				err = httpServer.Serve(l)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.WithError(err).Fatal("cannot serve Bhojpur Finance")
+			}
+		}()
+		if runCmdOpts.MetricsPort != 0 {
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(
+				prometheus.NewGoCollector(),
+				prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+				service,
+				interceptors,
+			)
+			go serveMetrics(registry, runCmdOpts.MetricsPort)
+		}
+		log.WithField("port", runCmdOpts.Port).WithField("readOnly", runCmdOpts.ReadOnly).WithField("tls", tlsConfig != nil).WithField("auth", authenticator.Enabled()).Info("Bhojpur Finance is up and running")
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+		log.Info("shutting down")
+		healthService.Shutdown()
+
+		// streams such as Listen can be open for a long time, hence we don't wait for them forever
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err = httpServer.Shutdown(ctx)
+		if err != nil {
+			log.WithError(err).Warn("cannot shut down gracefully")
+		}
+		grpcServer.Stop()
+		internalServer.Stop()
+	},
+}
+
+// serverTLSConfig loads the server certificate and, for mutual TLS, the CA which signs client certificates.
+// Without a server certificate it returns nil.
+func serverTLSConfig() (*tls.Config, error) {
+	if runCmdOpts.TLSCert == "" && runCmdOpts.TLSKey == "" {
+		if runCmdOpts.TLSClientCA != "" {
+			return nil, fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(runCmdOpts.TLSCert, runCmdOpts.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	res := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if runCmdOpts.TLSClientCA != "" {
+		pem, err := os.ReadFile(runCmdOpts.TLSClientCA)
+		if err != nil {
+			return nil, err
+		}
+		res.ClientCAs = x509.NewCertPool()
+		if !res.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", runCmdOpts.TLSClientCA)
+		}
+		res.ClientAuth = tls.RequireAndVerifyClientCert
+		if runCmdOpts.AuthTokens != "" {
+			// callers may authenticate with a token instead
+			res.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	return res, nil
+}
+
+// newAuthenticator authenticates callers by token and/or client certificate, depending on the flags
+func newAuthenticator() (*auth.Authenticator, error) {
+	cfg := auth.Config{
+		ClientCerts: runCmdOpts.TLSClientCA != "",
+		Admins:      runCmdOpts.Admins,
+	}
+	if runCmdOpts.AuthTokens != "" {
+		tokens, err := auth.LoadTokens(runCmdOpts.AuthTokens)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Tokens = tokens
+	}
+	return auth.NewAuthenticator(cfg), nil
+}
+
+// newKubernetesExecutor connects to the cluster the server runs in, or the one of the current kubeconfig context
+func newKubernetesExecutor() (*kubernetes.Executor, error) {
+	kubecfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	restcfg, err := kubecfg.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	namespace := runCmdOpts.K8sNamespace
+	if namespace == "" {
+		namespace, _, err = kubecfg.Namespace()
+		if err != nil {
+			return nil, err
+		}
+	}
+	client, err := k8s.NewForConfig(restcfg)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewExecutor(client, kubernetes.Config{
+		Namespace:    namespace,
+		DefaultImage: runCmdOpts.K8sImage,
+		KeepFinished: runCmdOpts.K8sKeepPods,
+	}), nil
+}
+
+// collectGarbage periodically deletes the finished pods of algorithms
+func collectGarbage(exec *kubernetes.Executor) {
+	for {
+		err := exec.CollectGarbage(context.Background())
+		if err != nil {
+			log.WithError(err).Warn("cannot delete finished pods")
+		}
+		time.Sleep(time.Minute)
+	}
+}
+
+// pruneAlgorithms periodically applies the retention policy
+func pruneAlgorithms(service *finance.Service, interval time.Duration) {
+	for {
+		_, err := service.Prune(context.Background(), false)
+		if err != nil {
+			log.WithError(err).Warn("cannot prune algorithms")
+		}
+		time.Sleep(interval)
+	}
+}
+
+// serveMetrics serves the Prometheus metrics on a port of their own, so that they aren't exposed along with the API
+func serveMetrics(registry *prometheus.Registry, port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	log.WithField("port", port).Info("serving metrics")
+	err := http.ListenAndServe(fmt.Sprintf(":%d", port), mux)
+	if err != nil {
+		log.WithError(err).Fatal("cannot serve metrics")
+	}
+}
+
+// grpcOrHTTP sends gRPC requests to the gRPC server and all other requests to the HTTP handler
+func grpcOrHTTP(grpcServer *grpc.Server, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func init() {
+	port := 7777
+	if p := os.Getenv("FINANCE_PORT"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			log.WithError(err).WithField("FINANCE_PORT", p).Warn("invalid port, using default")
+		} else {
+			port = v
+		}
+	}
+
+	metricsPort := 9500
+	if p := os.Getenv("FINANCE_METRICS_PORT"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			log.WithError(err).WithField("FINANCE_METRICS_PORT", p).Warn("invalid metrics port, using default")
+		} else {
+			metricsPort = v
+		}
+	}
+
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().IntVar(&runCmdOpts.Port, "port", port, "port to serve the gRPC API and web UI on (defaults to FINANCE_PORT env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.WorkspaceDir, "workspace-dir", os.Getenv("FINANCE_WORKSPACE_DIR"), "directory in which uploaded applications are unpacked (defaults to FINANCE_WORKSPACE_DIR env var, or a temporary directory)")
+	runCmd.Flags().Int64Var(&runCmdOpts.MaxUploadSize, "max-upload-size", 256*1024*1024, "maximum size in bytes of an uploaded application")
+	runCmd.Flags().StringVar(&runCmdOpts.DBDSN, "db-dsn", os.Getenv("FINANCE_DB_DSN"), "PostgreSQL connection string to store algorithms in (defaults to FINANCE_DB_DSN env var, or an in-memory store)")
+	runCmd.Flags().StringVar(&runCmdOpts.SpecsDir, "specs-dir", os.Getenv("FINANCE_SPECS_DIR"), "directory of algorithm YAML files offered by the UI (defaults to FINANCE_SPECS_DIR env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.Executor, "executor", "none", "how algorithms are run: local (as subprocesses of the server), kubernetes (as pods) or none. As algorithms run arbitrary commands, enable authentication before choosing one")
+	runCmd.Flags().StringVar(&runCmdOpts.K8sNamespace, "k8s-namespace", os.Getenv("FINANCE_K8S_NAMESPACE"), "[kubernetes executor] namespace to run algorithm pods in (defaults to FINANCE_K8S_NAMESPACE env var, or the namespace of the server)")
+	runCmd.Flags().StringVar(&runCmdOpts.K8sImage, "k8s-image", os.Getenv("FINANCE_K8S_IMAGE"), "[kubernetes executor] image of algorithms which don't name one (defaults to FINANCE_K8S_IMAGE env var)")
+	runCmd.Flags().DurationVar(&runCmdOpts.K8sKeepPods, "k8s-keep-finished", 0, "[kubernetes executor] time to keep the pods of finished algorithms around for")
+	runCmd.Flags().StringVar(&runCmdOpts.TLSCert, "tls-cert", os.Getenv("FINANCE_TLS_CERT"), "PEM certificate file to serve TLS with (defaults to FINANCE_TLS_CERT env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.TLSKey, "tls-key", os.Getenv("FINANCE_TLS_KEY"), "PEM key file of the TLS certificate (defaults to FINANCE_TLS_KEY env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.TLSClientCA, "tls-client-ca", os.Getenv("FINANCE_TLS_CLIENT_CA"), "PEM CA file to verify client certificates with, enables mutual TLS and identifies callers by the common name of their certificate (defaults to FINANCE_TLS_CLIENT_CA env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.AuthTokens, "auth-tokens", os.Getenv("FINANCE_AUTH_TOKENS"), "file of \"<token>,<identity>\" lines callers authenticate with as bearer token or API key (defaults to FINANCE_AUTH_TOKENS env var)")
+	runCmd.Flags().StringSliceVar(&runCmdOpts.Admins, "admins", splitList(os.Getenv("FINANCE_ADMINS")), "identities which may stop the algorithms of others (defaults to FINANCE_ADMINS env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.WebhookSecret, "webhook-secret", os.Getenv("FINANCE_WEBHOOK_SECRET"), "secret Git webhook deliveries to /webhook are signed with, the endpoint is disabled without it (defaults to FINANCE_WEBHOOK_SECRET env var)")
+	runCmd.Flags().StringVar(&runCmdOpts.GitOpsToken, "gitops-token", os.Getenv("FINANCE_GITOPS_TOKEN"), "token which lets replays of push-triggered algorithms keep their trigger (defaults to FINANCE_GITOPS_TOKEN env var)")
+	runCmd.Flags().IntVar(&runCmdOpts.Retention.KeepPerSpec, "keep-per-spec", 0, "number of finished algorithms to keep per spec, zero keeps all")
+	runCmd.Flags().DurationVar(&runCmdOpts.Retention.MaxAge, "max-age", 0, "time to keep finished algorithms for, zero keeps them forever")
+	runCmd.Flags().Int64Var(&runCmdOpts.Retention.MaxLogSize, "max-log-size", 0, "maximum size in bytes of the log kept per algorithm, longer logs keep their end, zero keeps complete logs")
+	runCmd.Flags().DurationVar(&runCmdOpts.PruneInterval, "prune-interval", time.Hour, "how often finished algorithms are pruned according to --keep-per-spec, --max-age and --max-log-size")
+	runCmd.Flags().IntVar(&runCmdOpts.MetricsPort, "metrics-port", metricsPort, "port to serve Prometheus metrics on at /metrics, zero disables them (defaults to FINANCE_METRICS_PORT env var)")
+	runCmd.Flags().BoolVar(&runCmdOpts.ReadOnly, "read-only", false, "reject all requests which start or stop algorithms")
+}
+
+// splitList splits a comma separated list, e.g. from an env var
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,1218 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.19.2
+// source: loan.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RecastPolicy decides how the schedule changes after a prepayment
+type RecastPolicy int32
+
+const (
+	// Unspecified policies keep the payment after prepayments and the tenor at rate resets
+	RecastPolicy_RECAST_POLICY_UNSPECIFIED RecastPolicy = 0
+	// Keeping the payment shortens the tenor. Schedules never run past the end date, so a rate
+	// rise after which the payment no longer repays the loan in time fails the schedule.
+	RecastPolicy_RECAST_POLICY_KEEP_PAYMENT RecastPolicy = 1
+	// Keeping the tenor reduces the payment
+	RecastPolicy_RECAST_POLICY_KEEP_TENOR RecastPolicy = 2
+)
+
+// Enum value maps for RecastPolicy.
+var (
+	RecastPolicy_name = map[int32]string{
+		0: "RECAST_POLICY_UNSPECIFIED",
+		1: "RECAST_POLICY_KEEP_PAYMENT",
+		2: "RECAST_POLICY_KEEP_TENOR",
+	}
+	RecastPolicy_value = map[string]int32{
+		"RECAST_POLICY_UNSPECIFIED":  0,
+		"RECAST_POLICY_KEEP_PAYMENT": 1,
+		"RECAST_POLICY_KEEP_TENOR":   2,
+	}
+)
+
+func (x RecastPolicy) Enum() *RecastPolicy {
+	p := new(RecastPolicy)
+	*p = x
+	return p
+}
+
+func (x RecastPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecastPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_loan_proto_enumTypes[0].Descriptor()
+}
+
+func (RecastPolicy) Type() protoreflect.EnumType {
+	return &file_loan_proto_enumTypes[0]
+}
+
+func (x RecastPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecastPolicy.Descriptor instead.
+func (RecastPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{0}
+}
+
+// BackStub decides how a broken period after the last regular payment date is paid
+type BackStub int32
+
+const (
+	// Unspecified back stubs are short
+	BackStub_BACK_STUB_UNSPECIFIED BackStub = 0
+	// A short back stub is a period of its own
+	BackStub_BACK_STUB_SHORT BackStub = 1
+	// A long back stub extends the last regular period
+	BackStub_BACK_STUB_LONG BackStub = 2
+)
+
+// Enum value maps for BackStub.
+var (
+	BackStub_name = map[int32]string{
+		0: "BACK_STUB_UNSPECIFIED",
+		1: "BACK_STUB_SHORT",
+		2: "BACK_STUB_LONG",
+	}
+	BackStub_value = map[string]int32{
+		"BACK_STUB_UNSPECIFIED": 0,
+		"BACK_STUB_SHORT":       1,
+		"BACK_STUB_LONG":        2,
+	}
+)
+
+func (x BackStub) Enum() *BackStub {
+	p := new(BackStub)
+	*p = x
+	return p
+}
+
+func (x BackStub) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BackStub) Descriptor() protoreflect.EnumDescriptor {
+	return file_loan_proto_enumTypes[1].Descriptor()
+}
+
+func (BackStub) Type() protoreflect.EnumType {
+	return &file_loan_proto_enumTypes[1]
+}
+
+func (x BackStub) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BackStub.Descriptor instead.
+func (BackStub) EnumDescriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{1}
+}
+
+type LoanFrequency int32
+
+const (
+	LoanFrequency_FREQUENCY_UNSPECIFIED LoanFrequency = 0
+	LoanFrequency_FREQUENCY_DAILY       LoanFrequency = 1
+	LoanFrequency_FREQUENCY_WEEKLY      LoanFrequency = 2
+	LoanFrequency_FREQUENCY_MONTHLY     LoanFrequency = 3
+	LoanFrequency_FREQUENCY_ANNUALLY    LoanFrequency = 4
+	LoanFrequency_FREQUENCY_FORTNIGHTLY LoanFrequency = 5
+	LoanFrequency_FREQUENCY_QUARTERLY   LoanFrequency = 6
+	LoanFrequency_FREQUENCY_HALF_YEARLY LoanFrequency = 7
+)
+
+// Enum value maps for LoanFrequency.
+var (
+	LoanFrequency_name = map[int32]string{
+		0: "FREQUENCY_UNSPECIFIED",
+		1: "FREQUENCY_DAILY",
+		2: "FREQUENCY_WEEKLY",
+		3: "FREQUENCY_MONTHLY",
+		4: "FREQUENCY_ANNUALLY",
+		5: "FREQUENCY_FORTNIGHTLY",
+		6: "FREQUENCY_QUARTERLY",
+		7: "FREQUENCY_HALF_YEARLY",
+	}
+	LoanFrequency_value = map[string]int32{
+		"FREQUENCY_UNSPECIFIED": 0,
+		"FREQUENCY_DAILY":       1,
+		"FREQUENCY_WEEKLY":      2,
+		"FREQUENCY_MONTHLY":     3,
+		"FREQUENCY_ANNUALLY":    4,
+		"FREQUENCY_FORTNIGHTLY": 5,
+		"FREQUENCY_QUARTERLY":   6,
+		"FREQUENCY_HALF_YEARLY": 7,
+	}
+)
+
+func (x LoanFrequency) Enum() *LoanFrequency {
+	p := new(LoanFrequency)
+	*p = x
+	return p
+}
+
+func (x LoanFrequency) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LoanFrequency) Descriptor() protoreflect.EnumDescriptor {
+	return file_loan_proto_enumTypes[2].Descriptor()
+}
+
+func (LoanFrequency) Type() protoreflect.EnumType {
+	return &file_loan_proto_enumTypes[2]
+}
+
+func (x LoanFrequency) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LoanFrequency.Descriptor instead.
+func (LoanFrequency) EnumDescriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{2}
+}
+
+type InterestType int32
+
+const (
+	InterestType_INTEREST_TYPE_UNSPECIFIED InterestType = 0
+	InterestType_INTEREST_TYPE_FLAT        InterestType = 1
+	InterestType_INTEREST_TYPE_REDUCING    InterestType = 2
+)
+
+// Enum value maps for InterestType.
+var (
+	InterestType_name = map[int32]string{
+		0: "INTEREST_TYPE_UNSPECIFIED",
+		1: "INTEREST_TYPE_FLAT",
+		2: "INTEREST_TYPE_REDUCING",
+	}
+	InterestType_value = map[string]int32{
+		"INTEREST_TYPE_UNSPECIFIED": 0,
+		"INTEREST_TYPE_FLAT":        1,
+		"INTEREST_TYPE_REDUCING":    2,
+	}
+)
+
+func (x InterestType) Enum() *InterestType {
+	p := new(InterestType)
+	*p = x
+	return p
+}
+
+func (x InterestType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (InterestType) Descriptor() protoreflect.EnumDescriptor {
+	return file_loan_proto_enumTypes[3].Descriptor()
+}
+
+func (InterestType) Type() protoreflect.EnumType {
+	return &file_loan_proto_enumTypes[3]
+}
+
+func (x InterestType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use InterestType.Descriptor instead.
+func (InterestType) EnumDescriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{3}
+}
+
+type PaymentPeriod int32
+
+const (
+	// Unspecified payments are made at the end of a period
+	PaymentPeriod_PAYMENT_PERIOD_UNSPECIFIED PaymentPeriod = 0
+	PaymentPeriod_PAYMENT_PERIOD_BEGINNING   PaymentPeriod = 1
+	PaymentPeriod_PAYMENT_PERIOD_ENDING      PaymentPeriod = 2
+)
+
+// Enum value maps for PaymentPeriod.
+var (
+	PaymentPeriod_name = map[int32]string{
+		0: "PAYMENT_PERIOD_UNSPECIFIED",
+		1: "PAYMENT_PERIOD_BEGINNING",
+		2: "PAYMENT_PERIOD_ENDING",
+	}
+	PaymentPeriod_value = map[string]int32{
+		"PAYMENT_PERIOD_UNSPECIFIED": 0,
+		"PAYMENT_PERIOD_BEGINNING":   1,
+		"PAYMENT_PERIOD_ENDING":      2,
+	}
+)
+
+func (x PaymentPeriod) Enum() *PaymentPeriod {
+	p := new(PaymentPeriod)
+	*p = x
+	return p
+}
+
+func (x PaymentPeriod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PaymentPeriod) Descriptor() protoreflect.EnumDescriptor {
+	return file_loan_proto_enumTypes[4].Descriptor()
+}
+
+func (PaymentPeriod) Type() protoreflect.EnumType {
+	return &file_loan_proto_enumTypes[4]
+}
+
+func (x PaymentPeriod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PaymentPeriod.Descriptor instead.
+func (PaymentPeriod) EnumDescriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{4}
+}
+
+type GenerateScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Config *LoanConfig `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *GenerateScheduleRequest) Reset() {
+	*x = GenerateScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_loan_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateScheduleRequest) ProtoMessage() {}
+
+func (x *GenerateScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loan_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateScheduleRequest.ProtoReflect.Descriptor instead.
+func (*GenerateScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GenerateScheduleRequest) GetConfig() *LoanConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// LoanConfig describes a loan. Dates are formatted as YYYY-MM-DD, amounts are decimals
+// formatted as strings, e.g. "200000.00", so that no precision is lost.
+type LoanConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// start_date is the first day of the schedule (inclusive)
+	StartDate string `protobuf:"bytes,1,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	// end_date is the last day of the schedule (inclusive). It must end a whole number of periods.
+	EndDate        string        `protobuf:"bytes,2,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Frequency      LoanFrequency `protobuf:"varint,3,opt,name=frequency,proto3,enum=v1.LoanFrequency" json:"frequency,omitempty"`
+	AmountBorrowed string        `protobuf:"bytes,4,opt,name=amount_borrowed,json=amountBorrowed,proto3" json:"amount_borrowed,omitempty"`
+	InterestType   InterestType  `protobuf:"varint,5,opt,name=interest_type,json=interestType,proto3,enum=v1.InterestType" json:"interest_type,omitempty"`
+	// interest is the annual interest rate in basis points, e.g. "1200" for 12%
+	Interest      string        `protobuf:"bytes,6,opt,name=interest,proto3" json:"interest,omitempty"`
+	PaymentPeriod PaymentPeriod `protobuf:"varint,7,opt,name=payment_period,json=paymentPeriod,proto3,enum=v1.PaymentPeriod" json:"payment_period,omitempty"`
+	// enable_rounding rounds all amounts to rounding_places decimal places
+	EnableRounding bool  `protobuf:"varint,8,opt,name=enable_rounding,json=enableRounding,proto3" json:"enable_rounding,omitempty"`
+	RoundingPlaces int32 `protobuf:"varint,9,opt,name=rounding_places,json=roundingPlaces,proto3" json:"rounding_places,omitempty"`
+	// rounding_error_tolerance is how much payment may differ from principal plus interest due to
+	// rounding. Differences up to it are adjusted against the interest. Defaults to zero.
+	RoundingErrorTolerance string `protobuf:"bytes,10,opt,name=rounding_error_tolerance,json=roundingErrorTolerance,proto3" json:"rounding_error_tolerance,omitempty"`
+	// first_payment_date is the last day of the first period. If set, payments fall due whole periods
+	// apart from it and broken periods at either end become stubs with pro-rated interest.
+	FirstPaymentDate string   `protobuf:"bytes,11,opt,name=first_payment_date,json=firstPaymentDate,proto3" json:"first_payment_date,omitempty"`
+	BackStub         BackStub `protobuf:"varint,12,opt,name=back_stub,json=backStub,proto3,enum=v1.BackStub" json:"back_stub,omitempty"`
+	// day_count is a day count convention such as "30E360", "ACT360", "ACT365" or "ACTACT". If set,
+	// every period accrues interest for the days between its dates. By default each period accrues a
+	// fixed share of the annual rate.
+	DayCount string `protobuf:"bytes,13,opt,name=day_count,json=dayCount,proto3" json:"day_count,omitempty"`
+	// prepayments repay principal ahead of the schedule, which is then recast by the recast policy
+	Prepayments []*LoanPrepayment `protobuf:"bytes,14,rep,name=prepayments,proto3" json:"prepayments,omitempty"`
+	Recast      RecastPolicy      `protobuf:"varint,15,opt,name=recast,proto3,enum=v1.RecastPolicy" json:"recast,omitempty"`
+	// rate_resets make the loan a floating rate loan. interest is the rate until the first reset,
+	// at which the rate becomes the latest benchmark rate plus spread (in basis points).
+	RateResets []*LoanRateReset `protobuf:"bytes,16,rep,name=rate_resets,json=rateResets,proto3" json:"rate_resets,omitempty"`
+	Spread     string           `protobuf:"bytes,17,opt,name=spread,proto3" json:"spread,omitempty"`
+	// reset_frequency is how often the rate is reset, counting from start_date. If unspecified, it
+	// is reset every period.
+	ResetFrequency LoanFrequency `protobuf:"varint,18,opt,name=reset_frequency,json=resetFrequency,proto3,enum=v1.LoanFrequency" json:"reset_frequency,omitempty"`
+	// rate_cap and rate_floor bound the rate resets may set and max_rate_change the change of a single
+	// reset, all in basis points. Unset or zero values don't bound the rate.
+	RateCap       string `protobuf:"bytes,19,opt,name=rate_cap,json=rateCap,proto3" json:"rate_cap,omitempty"`
+	RateFloor     string `protobuf:"bytes,20,opt,name=rate_floor,json=rateFloor,proto3" json:"rate_floor,omitempty"`
+	MaxRateChange string `protobuf:"bytes,21,opt,name=max_rate_change,json=maxRateChange,proto3" json:"max_rate_change,omitempty"`
+	// benchmark_curve is the JSON definition of a term structure as understood by the securities/term
+	// package, e.g. {"r": 6.5, "spread": 0}. If set, the benchmark rates are projected from its forward
+	// rates for every reset instead of being given by rate_resets.
+	BenchmarkCurve string `protobuf:"bytes,22,opt,name=benchmark_curve,json=benchmarkCurve,proto3" json:"benchmark_curve,omitempty"`
+}
+
+func (x *LoanConfig) Reset() {
+	*x = LoanConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_loan_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoanConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoanConfig) ProtoMessage() {}
+
+func (x *LoanConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_loan_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoanConfig.ProtoReflect.Descriptor instead.
+func (*LoanConfig) Descriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LoanConfig) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetFrequency() LoanFrequency {
+	if x != nil {
+		return x.Frequency
+	}
+	return LoanFrequency_FREQUENCY_UNSPECIFIED
+}
+
+func (x *LoanConfig) GetAmountBorrowed() string {
+	if x != nil {
+		return x.AmountBorrowed
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetInterestType() InterestType {
+	if x != nil {
+		return x.InterestType
+	}
+	return InterestType_INTEREST_TYPE_UNSPECIFIED
+}
+
+func (x *LoanConfig) GetInterest() string {
+	if x != nil {
+		return x.Interest
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetPaymentPeriod() PaymentPeriod {
+	if x != nil {
+		return x.PaymentPeriod
+	}
+	return PaymentPeriod_PAYMENT_PERIOD_UNSPECIFIED
+}
+
+func (x *LoanConfig) GetEnableRounding() bool {
+	if x != nil {
+		return x.EnableRounding
+	}
+	return false
+}
+
+func (x *LoanConfig) GetRoundingPlaces() int32 {
+	if x != nil {
+		return x.RoundingPlaces
+	}
+	return 0
+}
+
+func (x *LoanConfig) GetRoundingErrorTolerance() string {
+	if x != nil {
+		return x.RoundingErrorTolerance
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetFirstPaymentDate() string {
+	if x != nil {
+		return x.FirstPaymentDate
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetBackStub() BackStub {
+	if x != nil {
+		return x.BackStub
+	}
+	return BackStub_BACK_STUB_UNSPECIFIED
+}
+
+func (x *LoanConfig) GetDayCount() string {
+	if x != nil {
+		return x.DayCount
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetPrepayments() []*LoanPrepayment {
+	if x != nil {
+		return x.Prepayments
+	}
+	return nil
+}
+
+func (x *LoanConfig) GetRecast() RecastPolicy {
+	if x != nil {
+		return x.Recast
+	}
+	return RecastPolicy_RECAST_POLICY_UNSPECIFIED
+}
+
+func (x *LoanConfig) GetRateResets() []*LoanRateReset {
+	if x != nil {
+		return x.RateResets
+	}
+	return nil
+}
+
+func (x *LoanConfig) GetSpread() string {
+	if x != nil {
+		return x.Spread
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetResetFrequency() LoanFrequency {
+	if x != nil {
+		return x.ResetFrequency
+	}
+	return LoanFrequency_FREQUENCY_UNSPECIFIED
+}
+
+func (x *LoanConfig) GetRateCap() string {
+	if x != nil {
+		return x.RateCap
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetRateFloor() string {
+	if x != nil {
+		return x.RateFloor
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetMaxRateChange() string {
+	if x != nil {
+		return x.MaxRateChange
+	}
+	return ""
+}
+
+func (x *LoanConfig) GetBenchmarkCurve() string {
+	if x != nil {
+		return x.BenchmarkCurve
+	}
+	return ""
+}
+
+// LoanRateReset is a benchmark rate in basis points which applies from its date on
+type LoanRateReset struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Rate string `protobuf:"bytes,2,opt,name=rate,proto3" json:"rate,omitempty"`
+}
+
+func (x *LoanRateReset) Reset() {
+	*x = LoanRateReset{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_loan_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoanRateReset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoanRateReset) ProtoMessage() {}
+
+func (x *LoanRateReset) ProtoReflect() protoreflect.Message {
+	mi := &file_loan_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoanRateReset.ProtoReflect.Descriptor instead.
+func (*LoanRateReset) Descriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LoanRateReset) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *LoanRateReset) GetRate() string {
+	if x != nil {
+		return x.Rate
+	}
+	return ""
+}
+
+// LoanPrepayment is a part-payment or a foreclosure. It takes effect after the payment of the
+// period it falls in.
+type LoanPrepayment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	// amount is the principal prepaid. Amounts beyond the outstanding balance close the loan.
+	Amount string `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// foreclose repays the whole outstanding balance, amount is ignored then
+	Foreclose bool `protobuf:"varint,3,opt,name=foreclose,proto3" json:"foreclose,omitempty"`
+	// charge is charged in basis points of the principal prepaid, e.g. "200" for a 2% foreclosure charge
+	Charge string `protobuf:"bytes,4,opt,name=charge,proto3" json:"charge,omitempty"`
+}
+
+func (x *LoanPrepayment) Reset() {
+	*x = LoanPrepayment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_loan_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoanPrepayment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoanPrepayment) ProtoMessage() {}
+
+func (x *LoanPrepayment) ProtoReflect() protoreflect.Message {
+	mi := &file_loan_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoanPrepayment.ProtoReflect.Descriptor instead.
+func (*LoanPrepayment) Descriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LoanPrepayment) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *LoanPrepayment) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *LoanPrepayment) GetForeclose() bool {
+	if x != nil {
+		return x.Foreclose
+	}
+	return false
+}
+
+func (x *LoanPrepayment) GetCharge() string {
+	if x != nil {
+		return x.Charge
+	}
+	return ""
+}
+
+type GenerateScheduleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rows []*LoanScheduleRow `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	// the totals are the sums over all rows
+	TotalPayment    string `protobuf:"bytes,2,opt,name=total_payment,json=totalPayment,proto3" json:"total_payment,omitempty"`
+	TotalInterest   string `protobuf:"bytes,3,opt,name=total_interest,json=totalInterest,proto3" json:"total_interest,omitempty"`
+	TotalPrincipal  string `protobuf:"bytes,4,opt,name=total_principal,json=totalPrincipal,proto3" json:"total_principal,omitempty"`
+	TotalPrepayment string `protobuf:"bytes,5,opt,name=total_prepayment,json=totalPrepayment,proto3" json:"total_prepayment,omitempty"`
+	TotalCharge     string `protobuf:"bytes,6,opt,name=total_charge,json=totalCharge,proto3" json:"total_charge,omitempty"`
+}
+
+func (x *GenerateScheduleResponse) Reset() {
+	*x = GenerateScheduleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_loan_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateScheduleResponse) ProtoMessage() {}
+
+func (x *GenerateScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loan_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateScheduleResponse.ProtoReflect.Descriptor instead.
+func (*GenerateScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateScheduleResponse) GetRows() []*LoanScheduleRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *GenerateScheduleResponse) GetTotalPayment() string {
+	if x != nil {
+		return x.TotalPayment
+	}
+	return ""
+}
+
+func (x *GenerateScheduleResponse) GetTotalInterest() string {
+	if x != nil {
+		return x.TotalInterest
+	}
+	return ""
+}
+
+func (x *GenerateScheduleResponse) GetTotalPrincipal() string {
+	if x != nil {
+		return x.TotalPrincipal
+	}
+	return ""
+}
+
+func (x *GenerateScheduleResponse) GetTotalPrepayment() string {
+	if x != nil {
+		return x.TotalPrepayment
+	}
+	return ""
+}
+
+func (x *GenerateScheduleResponse) GetTotalCharge() string {
+	if x != nil {
+		return x.TotalCharge
+	}
+	return ""
+}
+
+// LoanScheduleRow is a single period of a schedule. Payments are negative as they are
+// made by the borrower.
+type LoanScheduleRow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Period    int64  `protobuf:"varint,1,opt,name=period,proto3" json:"period,omitempty"`
+	StartDate string `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate   string `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Payment   string `protobuf:"bytes,4,opt,name=payment,proto3" json:"payment,omitempty"`
+	Interest  string `protobuf:"bytes,5,opt,name=interest,proto3" json:"interest,omitempty"`
+	Principal string `protobuf:"bytes,6,opt,name=principal,proto3" json:"principal,omitempty"`
+	// prepayment and charge are the principal prepaid in the period and the charge on it
+	Prepayment string `protobuf:"bytes,7,opt,name=prepayment,proto3" json:"prepayment,omitempty"`
+	Charge     string `protobuf:"bytes,8,opt,name=charge,proto3" json:"charge,omitempty"`
+	// balance is the principal outstanding after the period
+	Balance string `protobuf:"bytes,9,opt,name=balance,proto3" json:"balance,omitempty"`
+}
+
+func (x *LoanScheduleRow) Reset() {
+	*x = LoanScheduleRow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_loan_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoanScheduleRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoanScheduleRow) ProtoMessage() {}
+
+func (x *LoanScheduleRow) ProtoReflect() protoreflect.Message {
+	mi := &file_loan_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoanScheduleRow.ProtoReflect.Descriptor instead.
+func (*LoanScheduleRow) Descriptor() ([]byte, []int) {
+	return file_loan_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LoanScheduleRow) GetPeriod() int64 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *LoanScheduleRow) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetPayment() string {
+	if x != nil {
+		return x.Payment
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetInterest() string {
+	if x != nil {
+		return x.Interest
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetPrepayment() string {
+	if x != nil {
+		return x.Prepayment
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetCharge() string {
+	if x != nil {
+		return x.Charge
+	}
+	return ""
+}
+
+func (x *LoanScheduleRow) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+var File_loan_proto protoreflect.FileDescriptor
+
+var file_loan_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x6c, 0x6f, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x76, 0x31,
+	0x22, 0x41, 0x0a, 0x17, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x63, 0x68, 0x65,
+	0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x06, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x6f, 0x61, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x22, 0xa2, 0x07, 0x0a, 0x0a, 0x4c, 0x6f, 0x61, 0x6e, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74,
+	0x65, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x09,
+	0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x61, 0x6e, 0x46, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x63, 0x79, 0x52, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x27, 0x0a,
+	0x0f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x62, 0x6f, 0x72, 0x72, 0x6f, 0x77, 0x65, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x42, 0x6f,
+	0x72, 0x72, 0x6f, 0x77, 0x65, 0x64, 0x12, 0x35, 0x0a, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65,
+	0x73, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e,
+	0x76, 0x31, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x0c, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x0e, 0x70, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x11, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x52, 0x0d, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x65, 0x72,
+	0x69, 0x6f, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x65, 0x6e,
+	0x61, 0x62, 0x6c, 0x65, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0f,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x50,
+	0x6c, 0x61, 0x63, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x18, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63,
+	0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x16, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x54, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x12,
+	0x2c, 0x0a, 0x12, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
+	0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a,
+	0x09, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x73, 0x74, 0x75, 0x62, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x53, 0x74, 0x75, 0x62, 0x52, 0x08,
+	0x62, 0x61, 0x63, 0x6b, 0x53, 0x74, 0x75, 0x62, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x61, 0x79, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x61, 0x79,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x34, 0x0a, 0x0b, 0x70, 0x72, 0x65, 0x70, 0x61, 0x79, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0b,
+	0x70, 0x72, 0x65, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x06, 0x72,
+	0x65, 0x63, 0x61, 0x73, 0x74, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x65, 0x63, 0x61, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x72,
+	0x65, 0x63, 0x61, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x0b, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x72, 0x65,
+	0x73, 0x65, 0x74, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x61, 0x6e, 0x52, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x0a, 0x72,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x65, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x70, 0x72,
+	0x65, 0x61, 0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61,
+	0x64, 0x12, 0x3a, 0x0a, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x74, 0x5f, 0x66, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x79, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x61, 0x6e, 0x46, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x0e, 0x72,
+	0x65, 0x73, 0x65, 0x74, 0x46, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x19, 0x0a,
+	0x08, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x63, 0x61, 0x70, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x72, 0x61, 0x74, 0x65, 0x43, 0x61, 0x70, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x61, 0x74, 0x65,
+	0x5f, 0x66, 0x6c, 0x6f, 0x6f, 0x72, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x61,
+	0x74, 0x65, 0x46, 0x6c, 0x6f, 0x6f, 0x72, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x72,
+	0x61, 0x74, 0x65, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x6d, 0x61, 0x78, 0x52, 0x61, 0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12,
+	0x27, 0x0a, 0x0f, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b, 0x5f, 0x63, 0x75, 0x72,
+	0x76, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x6d,
+	0x61, 0x72, 0x6b, 0x43, 0x75, 0x72, 0x76, 0x65, 0x22, 0x37, 0x0a, 0x0d, 0x4c, 0x6f, 0x61, 0x6e,
+	0x52, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x72, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x61, 0x74,
+	0x65, 0x22, 0x72, 0x0a, 0x0e, 0x4c, 0x6f, 0x61, 0x6e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x79, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x66, 0x6f, 0x72, 0x65, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x09, 0x66, 0x6f, 0x72, 0x65, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
+	0x68, 0x61, 0x72, 0x67, 0x65, 0x22, 0x86, 0x02, 0x0a, 0x18, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x61, 0x6e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75,
+	0x6c, 0x65, 0x52, 0x6f, 0x77, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65,
+	0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x49,
+	0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c,
+	0x12, 0x29, 0x0a, 0x10, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x65, 0x70, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x50, 0x72, 0x65, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x22, 0x89,
+	0x02, 0x0a, 0x0f, 0x4c, 0x6f, 0x61, 0x6e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52,
+	0x6f, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64,
+	0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x64,
+	0x44, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x72,
+	0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70,
+	0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x65, 0x70,
+	0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72,
+	0x65, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x72,
+	0x67, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x2a, 0x6b, 0x0a, 0x0c, 0x52, 0x65,
+	0x63, 0x61, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1d, 0x0a, 0x19, 0x52, 0x45,
+	0x43, 0x41, 0x53, 0x54, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1e, 0x0a, 0x1a, 0x52, 0x45, 0x43,
+	0x41, 0x53, 0x54, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x4b, 0x45, 0x45, 0x50, 0x5f,
+	0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18, 0x52, 0x45, 0x43,
+	0x41, 0x53, 0x54, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x4b, 0x45, 0x45, 0x50, 0x5f,
+	0x54, 0x45, 0x4e, 0x4f, 0x52, 0x10, 0x02, 0x2a, 0x4e, 0x0a, 0x08, 0x42, 0x61, 0x63, 0x6b, 0x53,
+	0x74, 0x75, 0x62, 0x12, 0x19, 0x0a, 0x15, 0x42, 0x41, 0x43, 0x4b, 0x5f, 0x53, 0x54, 0x55, 0x42,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x13,
+	0x0a, 0x0f, 0x42, 0x41, 0x43, 0x4b, 0x5f, 0x53, 0x54, 0x55, 0x42, 0x5f, 0x53, 0x48, 0x4f, 0x52,
+	0x54, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x42, 0x41, 0x43, 0x4b, 0x5f, 0x53, 0x54, 0x55, 0x42,
+	0x5f, 0x4c, 0x4f, 0x4e, 0x47, 0x10, 0x02, 0x2a, 0xd3, 0x01, 0x0a, 0x0d, 0x4c, 0x6f, 0x61, 0x6e,
+	0x46, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x19, 0x0a, 0x15, 0x46, 0x52, 0x45,
+	0x51, 0x55, 0x45, 0x4e, 0x43, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x52, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x43,
+	0x59, 0x5f, 0x44, 0x41, 0x49, 0x4c, 0x59, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x46, 0x52, 0x45,
+	0x51, 0x55, 0x45, 0x4e, 0x43, 0x59, 0x5f, 0x57, 0x45, 0x45, 0x4b, 0x4c, 0x59, 0x10, 0x02, 0x12,
+	0x15, 0x0a, 0x11, 0x46, 0x52, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x43, 0x59, 0x5f, 0x4d, 0x4f, 0x4e,
+	0x54, 0x48, 0x4c, 0x59, 0x10, 0x03, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x52, 0x45, 0x51, 0x55, 0x45,
+	0x4e, 0x43, 0x59, 0x5f, 0x41, 0x4e, 0x4e, 0x55, 0x41, 0x4c, 0x4c, 0x59, 0x10, 0x04, 0x12, 0x19,
+	0x0a, 0x15, 0x46, 0x52, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x43, 0x59, 0x5f, 0x46, 0x4f, 0x52, 0x54,
+	0x4e, 0x49, 0x47, 0x48, 0x54, 0x4c, 0x59, 0x10, 0x05, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x52, 0x45,
+	0x51, 0x55, 0x45, 0x4e, 0x43, 0x59, 0x5f, 0x51, 0x55, 0x41, 0x52, 0x54, 0x45, 0x52, 0x4c, 0x59,
+	0x10, 0x06, 0x12, 0x19, 0x0a, 0x15, 0x46, 0x52, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x43, 0x59, 0x5f,
+	0x48, 0x41, 0x4c, 0x46, 0x5f, 0x59, 0x45, 0x41, 0x52, 0x4c, 0x59, 0x10, 0x07, 0x2a, 0x61, 0x0a,
+	0x0c, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a,
+	0x19, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x45, 0x53, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12,
+	0x49, 0x4e, 0x54, 0x45, 0x52, 0x45, 0x53, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x46, 0x4c,
+	0x41, 0x54, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x45, 0x53, 0x54,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x44, 0x55, 0x43, 0x49, 0x4e, 0x47, 0x10, 0x02,
+	0x2a, 0x68, 0x0a, 0x0d, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x12, 0x1e, 0x0a, 0x1a, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x50, 0x45, 0x52,
+	0x49, 0x4f, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x1c, 0x0a, 0x18, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x50, 0x45, 0x52,
+	0x49, 0x4f, 0x44, 0x5f, 0x42, 0x45, 0x47, 0x49, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12,
+	0x19, 0x0a, 0x15, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x50, 0x45, 0x52, 0x49, 0x4f,
+	0x44, 0x5f, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x32, 0x5e, 0x0a, 0x0b, 0x4c, 0x6f,
+	0x61, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4f, 0x0a, 0x10, 0x47, 0x65, 0x6e,
+	0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x1b, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x27, 0x5a, 0x25, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x68, 0x6f, 0x6a, 0x70, 0x75, 0x72,
+	0x2f, 0x66, 0x69, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_loan_proto_rawDescOnce sync.Once
+	file_loan_proto_rawDescData = file_loan_proto_rawDesc
+)
+
+func file_loan_proto_rawDescGZIP() []byte {
+	file_loan_proto_rawDescOnce.Do(func() {
+		file_loan_proto_rawDescData = protoimpl.X.CompressGZIP(file_loan_proto_rawDescData)
+	})
+	return file_loan_proto_rawDescData
+}
+
+var file_loan_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_loan_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_loan_proto_goTypes = []interface{}{
+	(RecastPolicy)(0),                // 0: v1.RecastPolicy
+	(BackStub)(0),                    // 1: v1.BackStub
+	(LoanFrequency)(0),               // 2: v1.LoanFrequency
+	(InterestType)(0),                // 3: v1.InterestType
+	(PaymentPeriod)(0),               // 4: v1.PaymentPeriod
+	(*GenerateScheduleRequest)(nil),  // 5: v1.GenerateScheduleRequest
+	(*LoanConfig)(nil),               // 6: v1.LoanConfig
+	(*LoanRateReset)(nil),            // 7: v1.LoanRateReset
+	(*LoanPrepayment)(nil),           // 8: v1.LoanPrepayment
+	(*GenerateScheduleResponse)(nil), // 9: v1.GenerateScheduleResponse
+	(*LoanScheduleRow)(nil),          // 10: v1.LoanScheduleRow
+}
+var file_loan_proto_depIdxs = []int32{
+	6,  // 0: v1.GenerateScheduleRequest.config:type_name -> v1.LoanConfig
+	2,  // 1: v1.LoanConfig.frequency:type_name -> v1.LoanFrequency
+	3,  // 2: v1.LoanConfig.interest_type:type_name -> v1.InterestType
+	4,  // 3: v1.LoanConfig.payment_period:type_name -> v1.PaymentPeriod
+	1,  // 4: v1.LoanConfig.back_stub:type_name -> v1.BackStub
+	8,  // 5: v1.LoanConfig.prepayments:type_name -> v1.LoanPrepayment
+	0,  // 6: v1.LoanConfig.recast:type_name -> v1.RecastPolicy
+	7,  // 7: v1.LoanConfig.rate_resets:type_name -> v1.LoanRateReset
+	2,  // 8: v1.LoanConfig.reset_frequency:type_name -> v1.LoanFrequency
+	10, // 9: v1.GenerateScheduleResponse.rows:type_name -> v1.LoanScheduleRow
+	5,  // 10: v1.LoanService.GenerateSchedule:input_type -> v1.GenerateScheduleRequest
+	9,  // 11: v1.LoanService.GenerateSchedule:output_type -> v1.GenerateScheduleResponse
+	11, // [11:12] is the sub-list for method output_type
+	10, // [10:11] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_loan_proto_init() }
+func file_loan_proto_init() {
+	if File_loan_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_loan_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_loan_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoanConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_loan_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoanRateReset); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_loan_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoanPrepayment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_loan_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateScheduleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_loan_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoanScheduleRow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_loan_proto_rawDesc,
+			NumEnums:      5,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_loan_proto_goTypes,
+		DependencyIndexes: file_loan_proto_depIdxs,
+		EnumInfos:         file_loan_proto_enumTypes,
+		MessageInfos:      file_loan_proto_msgTypes,
+	}.Build()
+	File_loan_proto = out.File
+	file_loan_proto_rawDesc = nil
+	file_loan_proto_goTypes = nil
+	file_loan_proto_depIdxs = nil
+}
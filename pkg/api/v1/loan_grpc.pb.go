@@ -0,0 +1,105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LoanServiceClient is the client API for LoanService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LoanServiceClient interface {
+	// GenerateSchedule computes the amortization schedule of a loan. Invalid configurations fail
+	// with InvalidArgument and a BadRequest detail naming the offending fields.
+	GenerateSchedule(ctx context.Context, in *GenerateScheduleRequest, opts ...grpc.CallOption) (*GenerateScheduleResponse, error)
+}
+
+type loanServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLoanServiceClient(cc grpc.ClientConnInterface) LoanServiceClient {
+	return &loanServiceClient{cc}
+}
+
+func (c *loanServiceClient) GenerateSchedule(ctx context.Context, in *GenerateScheduleRequest, opts ...grpc.CallOption) (*GenerateScheduleResponse, error) {
+	out := new(GenerateScheduleResponse)
+	err := c.cc.Invoke(ctx, "/v1.LoanService/GenerateSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoanServiceServer is the server API for LoanService service.
+// All implementations must embed UnimplementedLoanServiceServer
+// for forward compatibility
+type LoanServiceServer interface {
+	// GenerateSchedule computes the amortization schedule of a loan. Invalid configurations fail
+	// with InvalidArgument and a BadRequest detail naming the offending fields.
+	GenerateSchedule(context.Context, *GenerateScheduleRequest) (*GenerateScheduleResponse, error)
+	mustEmbedUnimplementedLoanServiceServer()
+}
+
+// UnimplementedLoanServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLoanServiceServer struct {
+}
+
+func (UnimplementedLoanServiceServer) GenerateSchedule(context.Context, *GenerateScheduleRequest) (*GenerateScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateSchedule not implemented")
+}
+func (UnimplementedLoanServiceServer) mustEmbedUnimplementedLoanServiceServer() {}
+
+// UnsafeLoanServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LoanServiceServer will
+// result in compilation errors.
+type UnsafeLoanServiceServer interface {
+	mustEmbedUnimplementedLoanServiceServer()
+}
+
+func RegisterLoanServiceServer(s grpc.ServiceRegistrar, srv LoanServiceServer) {
+	s.RegisterService(&LoanService_ServiceDesc, srv)
+}
+
+func _LoanService_GenerateSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoanServiceServer).GenerateSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.LoanService/GenerateSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoanServiceServer).GenerateSchedule(ctx, req.(*GenerateScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LoanService_ServiceDesc is the grpc.ServiceDesc for LoanService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LoanService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.LoanService",
+	HandlerType: (*LoanServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateSchedule",
+			Handler:    _LoanService_GenerateSchedule_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "loan.proto",
+}
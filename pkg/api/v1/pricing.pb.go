@@ -0,0 +1,1544 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.19.2
+// source: pricing.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SolveFor int32
+
+const (
+	// IRR is the flat yield in percent at which the instrument is worth the price
+	SolveFor_SOLVE_FOR_IRR SolveFor = 0
+	// Spread is the spread in percent over the term structure at which the instrument is worth the price
+	SolveFor_SOLVE_FOR_SPREAD SolveFor = 1
+	// Implied volatility is the volatility at which an option is worth the price
+	SolveFor_SOLVE_FOR_IMPLIED_VOLATILITY SolveFor = 2
+)
+
+// Enum value maps for SolveFor.
+var (
+	SolveFor_name = map[int32]string{
+		0: "SOLVE_FOR_IRR",
+		1: "SOLVE_FOR_SPREAD",
+		2: "SOLVE_FOR_IMPLIED_VOLATILITY",
+	}
+	SolveFor_value = map[string]int32{
+		"SOLVE_FOR_IRR":                0,
+		"SOLVE_FOR_SPREAD":             1,
+		"SOLVE_FOR_IMPLIED_VOLATILITY": 2,
+	}
+)
+
+func (x SolveFor) Enum() *SolveFor {
+	p := new(SolveFor)
+	*p = x
+	return p
+}
+
+func (x SolveFor) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SolveFor) Descriptor() protoreflect.EnumDescriptor {
+	return file_pricing_proto_enumTypes[0].Descriptor()
+}
+
+func (SolveFor) Type() protoreflect.EnumType {
+	return &file_pricing_proto_enumTypes[0]
+}
+
+func (x SolveFor) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SolveFor.Descriptor instead.
+func (SolveFor) EnumDescriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{0}
+}
+
+type OptionType int32
+
+const (
+	OptionType_OPTION_CALL OptionType = 0
+	OptionType_OPTION_PUT  OptionType = 1
+)
+
+// Enum value maps for OptionType.
+var (
+	OptionType_name = map[int32]string{
+		0: "OPTION_CALL",
+		1: "OPTION_PUT",
+	}
+	OptionType_value = map[string]int32{
+		"OPTION_CALL": 0,
+		"OPTION_PUT":  1,
+	}
+)
+
+func (x OptionType) Enum() *OptionType {
+	p := new(OptionType)
+	*p = x
+	return p
+}
+
+func (x OptionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OptionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_pricing_proto_enumTypes[1].Descriptor()
+}
+
+func (OptionType) Type() protoreflect.EnumType {
+	return &file_pricing_proto_enumTypes[1]
+}
+
+func (x OptionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OptionType.Descriptor instead.
+func (OptionType) EnumDescriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{1}
+}
+
+type PriceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// term_structure is the JSON definition of a term structure, e.g. {"r": 2.5, "spread": 0}
+	// for a flat one, as understood by the securities/term package
+	TermStructure string      `protobuf:"bytes,1,opt,name=term_structure,json=termStructure,proto3" json:"term_structure,omitempty"`
+	Instrument    *Instrument `protobuf:"bytes,2,opt,name=instrument,proto3" json:"instrument,omitempty"`
+}
+
+func (x *PriceRequest) Reset() {
+	*x = PriceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceRequest) ProtoMessage() {}
+
+func (x *PriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceRequest.ProtoReflect.Descriptor instead.
+func (*PriceRequest) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PriceRequest) GetTermStructure() string {
+	if x != nil {
+		return x.TermStructure
+	}
+	return ""
+}
+
+func (x *PriceRequest) GetInstrument() *Instrument {
+	if x != nil {
+		return x.Instrument
+	}
+	return nil
+}
+
+type PriceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PresentValue float64 `protobuf:"fixed64,1,opt,name=present_value,json=presentValue,proto3" json:"present_value,omitempty"`
+	// accrued is the accrued interest of bonds
+	Accrued float64 `protobuf:"fixed64,2,opt,name=accrued,proto3" json:"accrued,omitempty"`
+	// term_risk is set for bonds
+	TermRisk *TermRisk `protobuf:"bytes,3,opt,name=term_risk,json=termRisk,proto3" json:"term_risk,omitempty"`
+	// greeks are set for options
+	Greeks *Greeks `protobuf:"bytes,4,opt,name=greeks,proto3" json:"greeks,omitempty"`
+}
+
+func (x *PriceResponse) Reset() {
+	*x = PriceResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceResponse) ProtoMessage() {}
+
+func (x *PriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceResponse.ProtoReflect.Descriptor instead.
+func (*PriceResponse) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PriceResponse) GetPresentValue() float64 {
+	if x != nil {
+		return x.PresentValue
+	}
+	return 0
+}
+
+func (x *PriceResponse) GetAccrued() float64 {
+	if x != nil {
+		return x.Accrued
+	}
+	return 0
+}
+
+func (x *PriceResponse) GetTermRisk() *TermRisk {
+	if x != nil {
+		return x.TermRisk
+	}
+	return nil
+}
+
+func (x *PriceResponse) GetGreeks() *Greeks {
+	if x != nil {
+		return x.Greeks
+	}
+	return nil
+}
+
+type TermRisk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Duration  float64 `protobuf:"fixed64,1,opt,name=duration,proto3" json:"duration,omitempty"`
+	Convexity float64 `protobuf:"fixed64,2,opt,name=convexity,proto3" json:"convexity,omitempty"`
+	// pvbp is the change in present value for a one basis point change in yield
+	Pvbp float64 `protobuf:"fixed64,3,opt,name=pvbp,proto3" json:"pvbp,omitempty"`
+}
+
+func (x *TermRisk) Reset() {
+	*x = TermRisk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TermRisk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermRisk) ProtoMessage() {}
+
+func (x *TermRisk) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TermRisk.ProtoReflect.Descriptor instead.
+func (*TermRisk) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TermRisk) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *TermRisk) GetConvexity() float64 {
+	if x != nil {
+		return x.Convexity
+	}
+	return 0
+}
+
+func (x *TermRisk) GetPvbp() float64 {
+	if x != nil {
+		return x.Pvbp
+	}
+	return 0
+}
+
+type Greeks struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Delta float64 `protobuf:"fixed64,1,opt,name=delta,proto3" json:"delta,omitempty"`
+	Gamma float64 `protobuf:"fixed64,2,opt,name=gamma,proto3" json:"gamma,omitempty"`
+	Rho   float64 `protobuf:"fixed64,3,opt,name=rho,proto3" json:"rho,omitempty"`
+	Vega  float64 `protobuf:"fixed64,4,opt,name=vega,proto3" json:"vega,omitempty"`
+}
+
+func (x *Greeks) Reset() {
+	*x = Greeks{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Greeks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Greeks) ProtoMessage() {}
+
+func (x *Greeks) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Greeks.ProtoReflect.Descriptor instead.
+func (*Greeks) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Greeks) GetDelta() float64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *Greeks) GetGamma() float64 {
+	if x != nil {
+		return x.Gamma
+	}
+	return 0
+}
+
+func (x *Greeks) GetRho() float64 {
+	if x != nil {
+		return x.Rho
+	}
+	return 0
+}
+
+func (x *Greeks) GetVega() float64 {
+	if x != nil {
+		return x.Vega
+	}
+	return 0
+}
+
+type SolveRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Instrument *Instrument `protobuf:"bytes,1,opt,name=instrument,proto3" json:"instrument,omitempty"`
+	Price      float64     `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	SolveFor   SolveFor    `protobuf:"varint,3,opt,name=solve_for,json=solveFor,proto3,enum=v1.SolveFor" json:"solve_for,omitempty"`
+	// term_structure is required to solve for a spread or an implied volatility
+	TermStructure string `protobuf:"bytes,4,opt,name=term_structure,json=termStructure,proto3" json:"term_structure,omitempty"`
+}
+
+func (x *SolveRequest) Reset() {
+	*x = SolveRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveRequest) ProtoMessage() {}
+
+func (x *SolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveRequest.ProtoReflect.Descriptor instead.
+func (*SolveRequest) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SolveRequest) GetInstrument() *Instrument {
+	if x != nil {
+		return x.Instrument
+	}
+	return nil
+}
+
+func (x *SolveRequest) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SolveRequest) GetSolveFor() SolveFor {
+	if x != nil {
+		return x.SolveFor
+	}
+	return SolveFor_SOLVE_FOR_IRR
+}
+
+func (x *SolveRequest) GetTermStructure() string {
+	if x != nil {
+		return x.TermStructure
+	}
+	return ""
+}
+
+type SolveResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *SolveResponse) Reset() {
+	*x = SolveResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveResponse) ProtoMessage() {}
+
+func (x *SolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveResponse.ProtoReflect.Descriptor instead.
+func (*SolveResponse) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SolveResponse) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type Instrument struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Kind:
+	//	*Instrument_StraightBond
+	//	*Instrument_FloatingBond
+	//	*Instrument_InterestRateSwap
+	//	*Instrument_ForwardRateAgreement
+	//	*Instrument_ForwardContract
+	//	*Instrument_Option
+	Kind isInstrument_Kind `protobuf_oneof:"kind"`
+}
+
+func (x *Instrument) Reset() {
+	*x = Instrument{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Instrument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Instrument) ProtoMessage() {}
+
+func (x *Instrument) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Instrument.ProtoReflect.Descriptor instead.
+func (*Instrument) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{6}
+}
+
+func (m *Instrument) GetKind() isInstrument_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return nil
+}
+
+func (x *Instrument) GetStraightBond() *StraightBond {
+	if x, ok := x.GetKind().(*Instrument_StraightBond); ok {
+		return x.StraightBond
+	}
+	return nil
+}
+
+func (x *Instrument) GetFloatingBond() *FloatingBond {
+	if x, ok := x.GetKind().(*Instrument_FloatingBond); ok {
+		return x.FloatingBond
+	}
+	return nil
+}
+
+func (x *Instrument) GetInterestRateSwap() *InterestRateSwap {
+	if x, ok := x.GetKind().(*Instrument_InterestRateSwap); ok {
+		return x.InterestRateSwap
+	}
+	return nil
+}
+
+func (x *Instrument) GetForwardRateAgreement() *ForwardRateAgreement {
+	if x, ok := x.GetKind().(*Instrument_ForwardRateAgreement); ok {
+		return x.ForwardRateAgreement
+	}
+	return nil
+}
+
+func (x *Instrument) GetForwardContract() *ForwardContract {
+	if x, ok := x.GetKind().(*Instrument_ForwardContract); ok {
+		return x.ForwardContract
+	}
+	return nil
+}
+
+func (x *Instrument) GetOption() *EquityOption {
+	if x, ok := x.GetKind().(*Instrument_Option); ok {
+		return x.Option
+	}
+	return nil
+}
+
+type isInstrument_Kind interface {
+	isInstrument_Kind()
+}
+
+type Instrument_StraightBond struct {
+	StraightBond *StraightBond `protobuf:"bytes,1,opt,name=straight_bond,json=straightBond,proto3,oneof"`
+}
+
+type Instrument_FloatingBond struct {
+	FloatingBond *FloatingBond `protobuf:"bytes,2,opt,name=floating_bond,json=floatingBond,proto3,oneof"`
+}
+
+type Instrument_InterestRateSwap struct {
+	InterestRateSwap *InterestRateSwap `protobuf:"bytes,3,opt,name=interest_rate_swap,json=interestRateSwap,proto3,oneof"`
+}
+
+type Instrument_ForwardRateAgreement struct {
+	ForwardRateAgreement *ForwardRateAgreement `protobuf:"bytes,4,opt,name=forward_rate_agreement,json=forwardRateAgreement,proto3,oneof"`
+}
+
+type Instrument_ForwardContract struct {
+	ForwardContract *ForwardContract `protobuf:"bytes,5,opt,name=forward_contract,json=forwardContract,proto3,oneof"`
+}
+
+type Instrument_Option struct {
+	Option *EquityOption `protobuf:"bytes,6,opt,name=option,proto3,oneof"`
+}
+
+func (*Instrument_StraightBond) isInstrument_Kind() {}
+
+func (*Instrument_FloatingBond) isInstrument_Kind() {}
+
+func (*Instrument_InterestRateSwap) isInstrument_Kind() {}
+
+func (*Instrument_ForwardRateAgreement) isInstrument_Kind() {}
+
+func (*Instrument_ForwardContract) isInstrument_Kind() {}
+
+func (*Instrument_Option) isInstrument_Kind() {}
+
+type MaturitySchedule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Settlement *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=settlement,proto3" json:"settlement,omitempty"`
+	Maturity   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=maturity,proto3" json:"maturity,omitempty"`
+	// frequency is the number of payments per year, one of 1, 2, 3, 4, 6 and 12. Zero means annual.
+	Frequency int32 `protobuf:"varint,3,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	// basis is the day count convention, e.g. 30E360 (the default), ACT360 or ACTACT
+	Basis string `protobuf:"bytes,4,opt,name=basis,proto3" json:"basis,omitempty"`
+}
+
+func (x *MaturitySchedule) Reset() {
+	*x = MaturitySchedule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MaturitySchedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaturitySchedule) ProtoMessage() {}
+
+func (x *MaturitySchedule) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaturitySchedule.ProtoReflect.Descriptor instead.
+func (*MaturitySchedule) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MaturitySchedule) GetSettlement() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Settlement
+	}
+	return nil
+}
+
+func (x *MaturitySchedule) GetMaturity() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Maturity
+	}
+	return nil
+}
+
+func (x *MaturitySchedule) GetFrequency() int32 {
+	if x != nil {
+		return x.Frequency
+	}
+	return 0
+}
+
+func (x *MaturitySchedule) GetBasis() string {
+	if x != nil {
+		return x.Basis
+	}
+	return ""
+}
+
+type StraightBond struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schedule *MaturitySchedule `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	// coupon is the annual coupon in percent of the redemption
+	Coupon     float64 `protobuf:"fixed64,2,opt,name=coupon,proto3" json:"coupon,omitempty"`
+	Redemption float64 `protobuf:"fixed64,3,opt,name=redemption,proto3" json:"redemption,omitempty"`
+}
+
+func (x *StraightBond) Reset() {
+	*x = StraightBond{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StraightBond) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StraightBond) ProtoMessage() {}
+
+func (x *StraightBond) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StraightBond.ProtoReflect.Descriptor instead.
+func (*StraightBond) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StraightBond) GetSchedule() *MaturitySchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *StraightBond) GetCoupon() float64 {
+	if x != nil {
+		return x.Coupon
+	}
+	return 0
+}
+
+func (x *StraightBond) GetRedemption() float64 {
+	if x != nil {
+		return x.Redemption
+	}
+	return 0
+}
+
+type FloatingBond struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schedule *MaturitySchedule `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	// rate is the annual rate of the current period
+	Rate       float64 `protobuf:"fixed64,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	Redemption float64 `protobuf:"fixed64,3,opt,name=redemption,proto3" json:"redemption,omitempty"`
+}
+
+func (x *FloatingBond) Reset() {
+	*x = FloatingBond{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FloatingBond) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FloatingBond) ProtoMessage() {}
+
+func (x *FloatingBond) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FloatingBond.ProtoReflect.Descriptor instead.
+func (*FloatingBond) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FloatingBond) GetSchedule() *MaturitySchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *FloatingBond) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *FloatingBond) GetRedemption() float64 {
+	if x != nil {
+		return x.Redemption
+	}
+	return 0
+}
+
+// InterestRateSwap receives the floating leg and pays the fixed leg
+type InterestRateSwap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Floating *FloatingBond `protobuf:"bytes,1,opt,name=floating,proto3" json:"floating,omitempty"`
+	Fixed    *StraightBond `protobuf:"bytes,2,opt,name=fixed,proto3" json:"fixed,omitempty"`
+}
+
+func (x *InterestRateSwap) Reset() {
+	*x = InterestRateSwap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InterestRateSwap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterestRateSwap) ProtoMessage() {}
+
+func (x *InterestRateSwap) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterestRateSwap.ProtoReflect.Descriptor instead.
+func (*InterestRateSwap) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *InterestRateSwap) GetFloating() *FloatingBond {
+	if x != nil {
+		return x.Floating
+	}
+	return nil
+}
+
+func (x *InterestRateSwap) GetFixed() *StraightBond {
+	if x != nil {
+		return x.Fixed
+	}
+	return nil
+}
+
+type ForwardRateAgreement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notional float64 `protobuf:"fixed64,1,opt,name=notional,proto3" json:"notional,omitempty"`
+	// factor is what a unit is worth at t2 if invested at t1, i.e. one plus the agreed rate over the period
+	Factor float64 `protobuf:"fixed64,2,opt,name=factor,proto3" json:"factor,omitempty"`
+	// t1 and t2 are the start and end of the period in years
+	T1 float64 `protobuf:"fixed64,3,opt,name=t1,proto3" json:"t1,omitempty"`
+	T2 float64 `protobuf:"fixed64,4,opt,name=t2,proto3" json:"t2,omitempty"`
+}
+
+func (x *ForwardRateAgreement) Reset() {
+	*x = ForwardRateAgreement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForwardRateAgreement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardRateAgreement) ProtoMessage() {}
+
+func (x *ForwardRateAgreement) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardRateAgreement.ProtoReflect.Descriptor instead.
+func (*ForwardRateAgreement) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ForwardRateAgreement) GetNotional() float64 {
+	if x != nil {
+		return x.Notional
+	}
+	return 0
+}
+
+func (x *ForwardRateAgreement) GetFactor() float64 {
+	if x != nil {
+		return x.Factor
+	}
+	return 0
+}
+
+func (x *ForwardRateAgreement) GetT1() float64 {
+	if x != nil {
+		return x.T1
+	}
+	return 0
+}
+
+func (x *ForwardRateAgreement) GetT2() float64 {
+	if x != nil {
+		return x.T2
+	}
+	return 0
+}
+
+type ForwardContract struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Strike       float64 `protobuf:"fixed64,1,opt,name=strike,proto3" json:"strike,omitempty"`
+	ForwardPrice float64 `protobuf:"fixed64,2,opt,name=forward_price,json=forwardPrice,proto3" json:"forward_price,omitempty"`
+	// maturity is in years
+	Maturity float64 `protobuf:"fixed64,3,opt,name=maturity,proto3" json:"maturity,omitempty"`
+}
+
+func (x *ForwardContract) Reset() {
+	*x = ForwardContract{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForwardContract) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardContract) ProtoMessage() {}
+
+func (x *ForwardContract) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardContract.ProtoReflect.Descriptor instead.
+func (*ForwardContract) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ForwardContract) GetStrike() float64 {
+	if x != nil {
+		return x.Strike
+	}
+	return 0
+}
+
+func (x *ForwardContract) GetForwardPrice() float64 {
+	if x != nil {
+		return x.ForwardPrice
+	}
+	return 0
+}
+
+func (x *ForwardContract) GetMaturity() float64 {
+	if x != nil {
+		return x.Maturity
+	}
+	return 0
+}
+
+// EquityOption is a European option priced with Black-Scholes
+type EquityOption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type   OptionType `protobuf:"varint,1,opt,name=type,proto3,enum=v1.OptionType" json:"type,omitempty"`
+	Spot   float64    `protobuf:"fixed64,2,opt,name=spot,proto3" json:"spot,omitempty"`
+	Strike float64    `protobuf:"fixed64,3,opt,name=strike,proto3" json:"strike,omitempty"`
+	// maturity is in years
+	Maturity float64 `protobuf:"fixed64,4,opt,name=maturity,proto3" json:"maturity,omitempty"`
+	// dividend_yield is in percent
+	DividendYield float64 `protobuf:"fixed64,5,opt,name=dividend_yield,json=dividendYield,proto3" json:"dividend_yield,omitempty"`
+	Volatility    float64 `protobuf:"fixed64,6,opt,name=volatility,proto3" json:"volatility,omitempty"`
+}
+
+func (x *EquityOption) Reset() {
+	*x = EquityOption{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pricing_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EquityOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EquityOption) ProtoMessage() {}
+
+func (x *EquityOption) ProtoReflect() protoreflect.Message {
+	mi := &file_pricing_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EquityOption.ProtoReflect.Descriptor instead.
+func (*EquityOption) Descriptor() ([]byte, []int) {
+	return file_pricing_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *EquityOption) GetType() OptionType {
+	if x != nil {
+		return x.Type
+	}
+	return OptionType_OPTION_CALL
+}
+
+func (x *EquityOption) GetSpot() float64 {
+	if x != nil {
+		return x.Spot
+	}
+	return 0
+}
+
+func (x *EquityOption) GetStrike() float64 {
+	if x != nil {
+		return x.Strike
+	}
+	return 0
+}
+
+func (x *EquityOption) GetMaturity() float64 {
+	if x != nil {
+		return x.Maturity
+	}
+	return 0
+}
+
+func (x *EquityOption) GetDividendYield() float64 {
+	if x != nil {
+		return x.DividendYield
+	}
+	return 0
+}
+
+func (x *EquityOption) GetVolatility() float64 {
+	if x != nil {
+		return x.Volatility
+	}
+	return 0
+}
+
+var File_pricing_proto protoreflect.FileDescriptor
+
+var file_pricing_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x02, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x65, 0x0a, 0x0c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x5f, 0x73, 0x74, 0x72,
+	0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x65,
+	0x72, 0x6d, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x0a, 0x69,
+	0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x0a, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x9d, 0x01, 0x0a, 0x0d,
+	0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a,
+	0x0d, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x63, 0x63, 0x72, 0x75, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x07, 0x61, 0x63, 0x63, 0x72, 0x75, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x09,
+	0x74, 0x65, 0x72, 0x6d, 0x5f, 0x72, 0x69, 0x73, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x52, 0x69, 0x73, 0x6b, 0x52, 0x08, 0x74,
+	0x65, 0x72, 0x6d, 0x52, 0x69, 0x73, 0x6b, 0x12, 0x22, 0x0a, 0x06, 0x67, 0x72, 0x65, 0x65, 0x6b,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72, 0x65,
+	0x65, 0x6b, 0x73, 0x52, 0x06, 0x67, 0x72, 0x65, 0x65, 0x6b, 0x73, 0x22, 0x58, 0x0a, 0x08, 0x54,
+	0x65, 0x72, 0x6d, 0x52, 0x69, 0x73, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x78, 0x69, 0x74, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x78, 0x69, 0x74,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x76, 0x62, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x04, 0x70, 0x76, 0x62, 0x70, 0x22, 0x5a, 0x0a, 0x06, 0x47, 0x72, 0x65, 0x65, 0x6b, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
+	0x64, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x72,
+	0x68, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x72, 0x68, 0x6f, 0x12, 0x12, 0x0a,
+	0x04, 0x76, 0x65, 0x67, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x76, 0x65, 0x67,
+	0x61, 0x22, 0xa6, 0x01, 0x0a, 0x0c, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x2e, 0x0a, 0x0a, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x73, 0x74,
+	0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x09, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x5f, 0x66, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x46, 0x6f, 0x72, 0x52, 0x08, 0x73, 0x6f, 0x6c, 0x76, 0x65,
+	0x46, 0x6f, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x5f, 0x73, 0x74, 0x72, 0x75,
+	0x63, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x65, 0x72,
+	0x6d, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x22, 0x25, 0x0a, 0x0d, 0x53, 0x6f,
+	0x6c, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x22, 0x8c, 0x03, 0x0a, 0x0a, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x37, 0x0a, 0x0d, 0x73, 0x74, 0x72, 0x61, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x62, 0x6f, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72,
+	0x61, 0x69, 0x67, 0x68, 0x74, 0x42, 0x6f, 0x6e, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x73, 0x74, 0x72,
+	0x61, 0x69, 0x67, 0x68, 0x74, 0x42, 0x6f, 0x6e, 0x64, 0x12, 0x37, 0x0a, 0x0d, 0x66, 0x6c, 0x6f,
+	0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x62, 0x6f, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x42, 0x6f,
+	0x6e, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x42, 0x6f,
+	0x6e, 0x64, 0x12, 0x44, 0x0a, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x5f, 0x72,
+	0x61, 0x74, 0x65, 0x5f, 0x73, 0x77, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x52, 0x61, 0x74, 0x65,
+	0x53, 0x77, 0x61, 0x70, 0x48, 0x00, 0x52, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74,
+	0x52, 0x61, 0x74, 0x65, 0x53, 0x77, 0x61, 0x70, 0x12, 0x50, 0x0a, 0x16, 0x66, 0x6f, 0x72, 0x77,
+	0x61, 0x72, 0x64, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f,
+	0x72, 0x77, 0x61, 0x72, 0x64, 0x52, 0x61, 0x74, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x48, 0x00, 0x52, 0x14, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x52, 0x61, 0x74,
+	0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x40, 0x0a, 0x10, 0x66, 0x6f,
+	0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72,
+	0x64, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48, 0x00, 0x52, 0x0f, 0x66, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x12, 0x2a, 0x0a, 0x06,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x71, 0x75, 0x69, 0x74, 0x79, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00,
+	0x52, 0x06, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x06, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x22, 0xba, 0x01, 0x0a, 0x10, 0x4d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x53, 0x63, 0x68,
+	0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x3a, 0x0a, 0x0a, 0x73, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x73, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x36, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x08, 0x6d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x66, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x61, 0x73, 0x69, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x62, 0x61, 0x73, 0x69, 0x73, 0x22, 0x78, 0x0a,
+	0x0c, 0x53, 0x74, 0x72, 0x61, 0x69, 0x67, 0x68, 0x74, 0x42, 0x6f, 0x6e, 0x64, 0x12, 0x30, 0x0a,
+	0x08, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x14, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x53, 0x63, 0x68,
+	0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x08, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x63, 0x6f, 0x75, 0x70, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x63, 0x6f, 0x75, 0x70, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x64, 0x65, 0x6d,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x72, 0x65, 0x64,
+	0x65, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x74, 0x0a, 0x0c, 0x46, 0x6c, 0x6f, 0x61, 0x74,
+	0x69, 0x6e, 0x67, 0x42, 0x6f, 0x6e, 0x64, 0x12, 0x30, 0x0a, 0x08, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52,
+	0x08, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x72, 0x61, 0x74, 0x65, 0x12, 0x1e, 0x0a,
+	0x0a, 0x72, 0x65, 0x64, 0x65, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0a, 0x72, 0x65, 0x64, 0x65, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x68, 0x0a,
+	0x10, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x52, 0x61, 0x74, 0x65, 0x53, 0x77, 0x61,
+	0x70, 0x12, 0x2c, 0x0a, 0x08, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x42, 0x6f, 0x6e, 0x64, 0x52, 0x08, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12,
+	0x26, 0x0a, 0x05, 0x66, 0x69, 0x78, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x61, 0x69, 0x67, 0x68, 0x74, 0x42, 0x6f, 0x6e, 0x64,
+	0x52, 0x05, 0x66, 0x69, 0x78, 0x65, 0x64, 0x22, 0x6a, 0x0a, 0x14, 0x46, 0x6f, 0x72, 0x77, 0x61,
+	0x72, 0x64, 0x52, 0x61, 0x74, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x66,
+	0x61, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x66, 0x61, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x31, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x02, 0x74, 0x31, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x32, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x02, 0x74, 0x32, 0x22, 0x6a, 0x0a, 0x0f, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x43, 0x6f,
+	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6b, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6b, 0x65, 0x12, 0x23,
+	0x0a, 0x0d, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x50, 0x72,
+	0x69, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x22,
+	0xc1, 0x01, 0x0a, 0x0c, 0x45, 0x71, 0x75, 0x69, 0x74, 0x79, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x22, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e,
+	0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x70, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x04, 0x73, 0x70, 0x6f, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69,
+	0x6b, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6b, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x08, 0x6d, 0x61, 0x74, 0x75, 0x72, 0x69, 0x74, 0x79, 0x12, 0x25, 0x0a, 0x0e,
+	0x64, 0x69, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x64, 0x5f, 0x79, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x64, 0x69, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x64, 0x59, 0x69,
+	0x65, 0x6c, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x76, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x76, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x2a, 0x55, 0x0a, 0x08, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x46, 0x6f, 0x72, 0x12,
+	0x11, 0x0a, 0x0d, 0x53, 0x4f, 0x4c, 0x56, 0x45, 0x5f, 0x46, 0x4f, 0x52, 0x5f, 0x49, 0x52, 0x52,
+	0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x53, 0x4f, 0x4c, 0x56, 0x45, 0x5f, 0x46, 0x4f, 0x52, 0x5f,
+	0x53, 0x50, 0x52, 0x45, 0x41, 0x44, 0x10, 0x01, 0x12, 0x20, 0x0a, 0x1c, 0x53, 0x4f, 0x4c, 0x56,
+	0x45, 0x5f, 0x46, 0x4f, 0x52, 0x5f, 0x49, 0x4d, 0x50, 0x4c, 0x49, 0x45, 0x44, 0x5f, 0x56, 0x4f,
+	0x4c, 0x41, 0x54, 0x49, 0x4c, 0x49, 0x54, 0x59, 0x10, 0x02, 0x2a, 0x2d, 0x0a, 0x0a, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x4f, 0x50, 0x54, 0x49,
+	0x4f, 0x4e, 0x5f, 0x43, 0x41, 0x4c, 0x4c, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x50, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x50, 0x55, 0x54, 0x10, 0x01, 0x32, 0x70, 0x0a, 0x0e, 0x50, 0x72, 0x69,
+	0x63, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2e, 0x0a, 0x05, 0x50,
+	0x72, 0x69, 0x63, 0x65, 0x12, 0x10, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x69, 0x63,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x53,
+	0x6f, 0x6c, 0x76, 0x65, 0x12, 0x10, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x6c, 0x76,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x27, 0x5a, 0x25, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x68, 0x6f, 0x6a, 0x70, 0x75,
+	0x72, 0x2f, 0x66, 0x69, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pricing_proto_rawDescOnce sync.Once
+	file_pricing_proto_rawDescData = file_pricing_proto_rawDesc
+)
+
+func file_pricing_proto_rawDescGZIP() []byte {
+	file_pricing_proto_rawDescOnce.Do(func() {
+		file_pricing_proto_rawDescData = protoimpl.X.CompressGZIP(file_pricing_proto_rawDescData)
+	})
+	return file_pricing_proto_rawDescData
+}
+
+var file_pricing_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pricing_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_pricing_proto_goTypes = []interface{}{
+	(SolveFor)(0),                 // 0: v1.SolveFor
+	(OptionType)(0),               // 1: v1.OptionType
+	(*PriceRequest)(nil),          // 2: v1.PriceRequest
+	(*PriceResponse)(nil),         // 3: v1.PriceResponse
+	(*TermRisk)(nil),              // 4: v1.TermRisk
+	(*Greeks)(nil),                // 5: v1.Greeks
+	(*SolveRequest)(nil),          // 6: v1.SolveRequest
+	(*SolveResponse)(nil),         // 7: v1.SolveResponse
+	(*Instrument)(nil),            // 8: v1.Instrument
+	(*MaturitySchedule)(nil),      // 9: v1.MaturitySchedule
+	(*StraightBond)(nil),          // 10: v1.StraightBond
+	(*FloatingBond)(nil),          // 11: v1.FloatingBond
+	(*InterestRateSwap)(nil),      // 12: v1.InterestRateSwap
+	(*ForwardRateAgreement)(nil),  // 13: v1.ForwardRateAgreement
+	(*ForwardContract)(nil),       // 14: v1.ForwardContract
+	(*EquityOption)(nil),          // 15: v1.EquityOption
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_pricing_proto_depIdxs = []int32{
+	8,  // 0: v1.PriceRequest.instrument:type_name -> v1.Instrument
+	4,  // 1: v1.PriceResponse.term_risk:type_name -> v1.TermRisk
+	5,  // 2: v1.PriceResponse.greeks:type_name -> v1.Greeks
+	8,  // 3: v1.SolveRequest.instrument:type_name -> v1.Instrument
+	0,  // 4: v1.SolveRequest.solve_for:type_name -> v1.SolveFor
+	10, // 5: v1.Instrument.straight_bond:type_name -> v1.StraightBond
+	11, // 6: v1.Instrument.floating_bond:type_name -> v1.FloatingBond
+	12, // 7: v1.Instrument.interest_rate_swap:type_name -> v1.InterestRateSwap
+	13, // 8: v1.Instrument.forward_rate_agreement:type_name -> v1.ForwardRateAgreement
+	14, // 9: v1.Instrument.forward_contract:type_name -> v1.ForwardContract
+	15, // 10: v1.Instrument.option:type_name -> v1.EquityOption
+	16, // 11: v1.MaturitySchedule.settlement:type_name -> google.protobuf.Timestamp
+	16, // 12: v1.MaturitySchedule.maturity:type_name -> google.protobuf.Timestamp
+	9,  // 13: v1.StraightBond.schedule:type_name -> v1.MaturitySchedule
+	9,  // 14: v1.FloatingBond.schedule:type_name -> v1.MaturitySchedule
+	11, // 15: v1.InterestRateSwap.floating:type_name -> v1.FloatingBond
+	10, // 16: v1.InterestRateSwap.fixed:type_name -> v1.StraightBond
+	1,  // 17: v1.EquityOption.type:type_name -> v1.OptionType
+	2,  // 18: v1.PricingService.Price:input_type -> v1.PriceRequest
+	6,  // 19: v1.PricingService.Solve:input_type -> v1.SolveRequest
+	3,  // 20: v1.PricingService.Price:output_type -> v1.PriceResponse
+	7,  // 21: v1.PricingService.Solve:output_type -> v1.SolveResponse
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_pricing_proto_init() }
+func file_pricing_proto_init() {
+	if File_pricing_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pricing_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PriceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PriceResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TermRisk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Greeks); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SolveRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SolveResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Instrument); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MaturitySchedule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StraightBond); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FloatingBond); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InterestRateSwap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ForwardRateAgreement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ForwardContract); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pricing_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EquityOption); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pricing_proto_msgTypes[6].OneofWrappers = []interface{}{
+		(*Instrument_StraightBond)(nil),
+		(*Instrument_FloatingBond)(nil),
+		(*Instrument_InterestRateSwap)(nil),
+		(*Instrument_ForwardRateAgreement)(nil),
+		(*Instrument_ForwardContract)(nil),
+		(*Instrument_Option)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pricing_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pricing_proto_goTypes,
+		DependencyIndexes: file_pricing_proto_depIdxs,
+		EnumInfos:         file_pricing_proto_enumTypes,
+		MessageInfos:      file_pricing_proto_msgTypes,
+	}.Build()
+	File_pricing_proto = out.File
+	file_pricing_proto_rawDesc = nil
+	file_pricing_proto_goTypes = nil
+	file_pricing_proto_depIdxs = nil
+}
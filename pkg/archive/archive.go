@@ -0,0 +1,255 @@
+package archive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTooLarge is returned when an archive exceeds the maximum size
+var ErrTooLarge = errors.New("archive is too large")
+
+// ErrIllegalPath is returned when an archive contains an entry which would end up outside the destination
+var ErrIllegalPath = errors.New("illegal path in archive")
+
+// Extract unpacks a gzipped tar stream into dst. Entries which would end up outside of dst,
+// e.g. "../foo", symlinks pointing out of dst or entries below a symlink, are rejected with
+// ErrIllegalPath. If the uncompressed content exceeds maxSize bytes, ErrTooLarge is returned.
+// A maxSize of zero means there's no limit.
+func Extract(in io.Reader, dst string, maxSize int64) error {
+	err := os.MkdirAll(dst, 0755)
+	if err != nil {
+		return err
+	}
+	// compare against the real path of dst, it may itself be located below a symlink
+	dst, err = filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	dst, err = filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("cannot read gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read tar stream: %w", err)
+		}
+
+		target, err := securePath(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			total += hdr.Size
+			if maxSize > 0 && total > maxSize {
+				return ErrTooLarge
+			}
+			err = writeFile(target, tr, hdr.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = writeSymlink(dst, target, hdr.Linkname)
+		default:
+			// we ignore everything else, e.g. devices or hard links
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// securePath resolves name within dst and ensures it does not escape dst. As the path is
+// only compared as text, none of its existing components may be a symlink.
+func securePath(dst, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("%w: %s", ErrIllegalPath, name)
+	}
+	target := filepath.Join(dst, name)
+	if !within(dst, target) {
+		return "", fmt.Errorf("%w: %s", ErrIllegalPath, name)
+	}
+	rel, err := filepath.Rel(dst, target)
+	if err != nil {
+		return "", err
+	}
+	p := dst
+	for _, elem := range strings.Split(rel, string(filepath.Separator)) {
+		p = filepath.Join(p, elem)
+		isLink, err := isSymlink(p)
+		if err != nil {
+			return "", err
+		}
+		if isLink {
+			return "", fmt.Errorf("%w: %s is below a symlink", ErrIllegalPath, name)
+		}
+	}
+	return target, nil
+}
+
+// isSymlink returns true if p is a symlink. Missing paths are no symlinks.
+func isSymlink(p string) (bool, error) {
+	info, err := os.Lstat(p)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Mode()&os.ModeSymlink != 0, nil
+}
+
+// within returns true if the clean path p is dst or located below dst
+func within(dst, p string) bool {
+	return p == dst || strings.HasPrefix(p, dst+string(filepath.Separator))
+}
+
+func writeFile(target string, in io.Reader, mode os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode|0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, in)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeSymlink creates the symlink target pointing to linkname. The parent of target is
+// a real directory, see securePath, so linkname is resolved from it one element at a time.
+// Links going through another symlink are rejected, they could resolve outside of dst.
+func writeSymlink(dst, target, linkname string) error {
+	resolved := filepath.Dir(target)
+	if filepath.IsAbs(linkname) {
+		resolved = filepath.VolumeName(linkname) + string(filepath.Separator)
+	}
+	elems := strings.Split(filepath.FromSlash(linkname), string(filepath.Separator))
+	for i, elem := range elems {
+		resolved = filepath.Join(resolved, elem)
+		if i == len(elems)-1 {
+			break
+		}
+		isLink, err := isSymlink(resolved)
+		if err != nil {
+			return err
+		}
+		if isLink {
+			return fmt.Errorf("%w: %s -> %s goes through a symlink", ErrIllegalPath, target, linkname)
+		}
+	}
+	if !within(dst, resolved) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalPath, target, linkname)
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(linkname, target)
+}
+
+// Create writes the content of src as gzipped tar stream to out. Directories for which
+// skip returns true are not included. skip may be nil.
+func Create(out io.Writer, src string, skip func(path string) bool) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if skip != nil && skip(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
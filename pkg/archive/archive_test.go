@@ -0,0 +1,237 @@
+package archive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type entry struct {
+	Name     string
+	Content  string
+	Linkname string
+	Type     byte
+}
+
+func buildArchive(t *testing.T, entries []entry) *bytes.Buffer {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.Name, Mode: 0644, Typeflag: e.Type, Linkname: e.Linkname}
+		if e.Type == tar.TypeReg {
+			hdr.Size = int64(len(e.Content))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.Content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		maxSize int64
+		wantErr error
+		want    map[string]string
+	}{
+		{
+			name: "regular files",
+			entries: []entry{
+				{Name: "finance/", Type: tar.TypeDir},
+				{Name: "finance/config.yaml", Content: "defaultAlgorithm: eod.yaml", Type: tar.TypeReg},
+				{Name: "data/quotes.csv", Content: "1,2,3", Type: tar.TypeReg},
+			},
+			want: map[string]string{
+				"finance/config.yaml": "defaultAlgorithm: eod.yaml",
+				"data/quotes.csv":     "1,2,3",
+			},
+		},
+		{
+			name:    "parent traversal",
+			entries: []entry{{Name: "../evil", Content: "foo", Type: tar.TypeReg}},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name:    "nested parent traversal",
+			entries: []entry{{Name: "foo/../../evil", Content: "foo", Type: tar.TypeReg}},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name:    "absolute path",
+			entries: []entry{{Name: "/etc/evil", Content: "foo", Type: tar.TypeReg}},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name:    "symlink out of destination",
+			entries: []entry{{Name: "link", Linkname: "../../etc", Type: tar.TypeSymlink}},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name:    "absolute symlink",
+			entries: []entry{{Name: "link", Linkname: "/etc/passwd", Type: tar.TypeSymlink}},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name: "symlink within destination",
+			entries: []entry{
+				{Name: "a/file", Content: "foo", Type: tar.TypeReg},
+				{Name: "b/link", Linkname: "../a/file", Type: tar.TypeSymlink},
+			},
+			want: map[string]string{"a/file": "foo", "b/link": "foo"},
+		},
+		{
+			name: "file below symlink",
+			entries: []entry{
+				{Name: "a/", Type: tar.TypeDir},
+				{Name: "link", Linkname: "a", Type: tar.TypeSymlink},
+				{Name: "link/file", Content: "foo", Type: tar.TypeReg},
+			},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name: "symlink through symlink",
+			entries: []entry{
+				{Name: "d", Linkname: ".", Type: tar.TypeSymlink},
+				{Name: "up", Linkname: "d/..", Type: tar.TypeSymlink},
+			},
+			wantErr: ErrIllegalPath,
+		},
+		{
+			name: "too large",
+			entries: []entry{
+				{Name: "a", Content: "0123456789", Type: tar.TypeReg},
+				{Name: "b", Content: "0123456789", Type: tar.TypeReg},
+			},
+			maxSize: 15,
+			wantErr: ErrTooLarge,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dst := t.TempDir()
+			err := Extract(buildArchive(t, test.entries), dst, test.maxSize)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("want error %v, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for fn, content := range test.want {
+				act, err := os.ReadFile(filepath.Join(dst, fn))
+				if err != nil {
+					t.Errorf("cannot read %s: %v", fn, err)
+					continue
+				}
+				if string(act) != content {
+					t.Errorf("unexpected content of %s: want %q, got %q", fn, content, string(act))
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAndExtract(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"finance/config.yaml":       "defaultAlgorithm: finance/eod.yaml",
+		"finance/eod.yaml":          "description: EOD",
+		"data/quotes.csv":           "1,2,3",
+		".git/HEAD":                 "ref: refs/heads/main",
+		"data/nested/deeper/a.json": "{}",
+	}
+	for fn, content := range files {
+		fn = filepath.Join(src, fn)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := Create(&buf, src, func(path string) bool { return path == ".git" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if err := Extract(&buf, dst, 0); err != nil {
+		t.Fatal(err)
+	}
+	for fn, content := range files {
+		act, err := os.ReadFile(filepath.Join(dst, fn))
+		if fn == ".git/HEAD" {
+			if err == nil {
+				t.Errorf("skipped file %s was extracted", fn)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("cannot read %s: %v", fn, err)
+			continue
+		}
+		if string(act) != content {
+			t.Errorf("unexpected content of %s: want %q, got %q", fn, content, string(act))
+		}
+	}
+}
+
+func TestExtractSymlinkChain(t *testing.T) {
+	// d resolves to the destination and d/e to its parent, e/pwned.txt must not end up next to the destination
+	parent := t.TempDir()
+	dst := filepath.Join(parent, "workspace")
+	entries := []entry{
+		{Name: "d", Linkname: ".", Type: tar.TypeSymlink},
+		{Name: "d/e", Linkname: "..", Type: tar.TypeSymlink},
+		{Name: "e/pwned.txt", Content: "foo", Type: tar.TypeReg},
+	}
+	err := Extract(buildArchive(t, entries), dst, 0)
+	if !errors.Is(err, ErrIllegalPath) {
+		t.Errorf("want error %v, got %v", ErrIllegalPath, err)
+	}
+	if _, err := os.Lstat(filepath.Join(parent, "pwned.txt")); !os.IsNotExist(err) {
+		t.Errorf("file was written outside of the destination: %v", err)
+	}
+}
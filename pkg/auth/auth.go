@@ -0,0 +1,216 @@
+package auth
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// healthService prefixes the methods of the standard gRPC health service. Load balancers and orchestrators
+// check health without credentials, so its calls are never authenticated.
+const healthService = "/grpc.health.v1.Health/"
+
+// Identity is an authenticated caller
+type Identity struct {
+	// Name identifies the caller, e.g. as owner of the Algorithms it starts
+	Name string
+
+	// Admin callers may act on the Algorithms of others
+	Admin bool
+}
+
+type identityKey struct{}
+
+// NewContext returns a context which carries the identity of the caller
+func NewContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity of the caller. If authentication is disabled ok is false.
+func FromContext(ctx context.Context) (id Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(Identity)
+	return
+}
+
+// Config configures how callers authenticate
+type Config struct {
+	// Tokens maps bearer tokens and API keys to the name of the identity they authenticate
+	Tokens map[string]string
+
+	// ClientCerts authenticates callers by the common name of their verified TLS client certificate
+	ClientCerts bool
+
+	// Admins names the identities which are admins
+	Admins []string
+}
+
+// Authenticator establishes the identity of callers. Callers present a token either as
+// "authorization: Bearer <token>" or as "x-api-key: <token>" metadata, or a client certificate.
+type Authenticator struct {
+	tokens      map[[sha256.Size]byte]string
+	clientCerts bool
+	admins      map[string]bool
+}
+
+// NewAuthenticator produces an authenticator. If the config neither has tokens nor accepts
+// client certificates, authentication is disabled.
+func NewAuthenticator(cfg Config) *Authenticator {
+	res := &Authenticator{
+		// we only keep hashes around so that looking tokens up doesn't leak them through timing
+		tokens:      make(map[[sha256.Size]byte]string, len(cfg.Tokens)),
+		clientCerts: cfg.ClientCerts,
+		admins:      make(map[string]bool, len(cfg.Admins)),
+	}
+	for token, name := range cfg.Tokens {
+		res.tokens[sha256.Sum256([]byte(token))] = name
+	}
+	for _, name := range cfg.Admins {
+		res.admins[name] = true
+	}
+	return res
+}
+
+// Enabled returns true if callers have to authenticate
+func (a *Authenticator) Enabled() bool {
+	return len(a.tokens) > 0 || a.clientCerts
+}
+
+// Authenticate establishes the identity of the caller of a request. All errors are gRPC status errors.
+func (a *Authenticator) Authenticate(ctx context.Context) (Identity, error) {
+	var token string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("authorization"); len(v) > 0 {
+		segs := strings.SplitN(v[0], " ", 2)
+		if len(segs) != 2 || !strings.EqualFold(segs[0], "bearer") {
+			return Identity{}, status.Error(codes.Unauthenticated, "unsupported authorization scheme, use a bearer token")
+		}
+		token = strings.TrimSpace(segs[1])
+	} else if v := md.Get("x-api-key"); len(v) > 0 {
+		token = v[0]
+	}
+	if token != "" {
+		name, ok := a.tokens[sha256.Sum256([]byte(token))]
+		if !ok {
+			return Identity{}, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return a.identity(name), nil
+	}
+
+	if a.clientCerts {
+		if p, ok := peer.FromContext(ctx); ok {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+				if cn := info.State.VerifiedChains[0][0].Subject.CommonName; cn != "" {
+					return a.identity(cn), nil
+				}
+			}
+		}
+	}
+	return Identity{}, status.Error(codes.Unauthenticated, "authentication required")
+}
+
+// required returns true if callers of a method have to authenticate
+func (a *Authenticator) required(method string) bool {
+	return a.Enabled() && !strings.HasPrefix(method, healthService)
+}
+
+func (a *Authenticator) identity(name string) Identity {
+	return Identity{Name: name, Admin: a.admins[name]}
+}
+
+// UnaryInterceptor authenticates unary calls
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !a.required(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		id, err := a.Authenticate(ctx)
+		if err != nil {
+			log.WithField("method", info.FullMethod).WithError(err).Debug("unauthenticated call")
+			return nil, err
+		}
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+// StreamInterceptor authenticates streaming calls
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !a.required(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		id, err := a.Authenticate(ss.Context())
+		if err != nil {
+			log.WithField("method", info.FullMethod).WithError(err).Debug("unauthenticated call")
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: NewContext(ss.Context(), id)})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// LoadTokens reads a token file. Each line of the file holds a token and the name of the
+// identity it authenticates, separated by a comma. Empty lines and lines starting with # are ignored.
+func LoadTokens(fn string) (map[string]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for nr := 1; scanner.Scan(); nr++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		segs := strings.SplitN(line, ",", 2)
+		if len(segs) != 2 || strings.TrimSpace(segs[0]) == "" || strings.TrimSpace(segs[1]) == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"<token>,<identity>\"", fn, nr)
+		}
+		res[strings.TrimSpace(segs[0])] = strings.TrimSpace(segs[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
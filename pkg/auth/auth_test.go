@@ -0,0 +1,202 @@
+package auth
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func withCert(ctx context.Context, cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{cert}},
+	}}})
+}
+
+func TestAuthenticate(t *testing.T) {
+	cfg := Config{
+		Tokens:      map[string]string{"s3cr3t": "alice", "k3y": "bob"},
+		ClientCerts: true,
+		Admins:      []string{"bob"},
+	}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		want     Identity
+		wantCode codes.Code
+	}{
+		{
+			name: "bearer token",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer s3cr3t")),
+			want: Identity{Name: "alice"},
+		},
+		{
+			name: "api key",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k3y")),
+			want: Identity{Name: "bob", Admin: true},
+		},
+		{
+			name:     "invalid token",
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer nope")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "basic auth",
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic YWxpY2U6cHc=")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "client certificate",
+			ctx:  withCert(context.Background(), "carol"),
+			want: Identity{Name: "carol"},
+		},
+		{
+			name:     "invalid token with client certificate",
+			ctx:      metadata.NewIncomingContext(withCert(context.Background(), "carol"), metadata.Pairs("x-api-key", "nope")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "anonymous",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := NewAuthenticator(cfg).Authenticate(test.ctx)
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected status code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+			if id != test.want {
+				t.Errorf("unexpected identity: want %+v, got %+v", test.want, id)
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	var (
+		ctx  = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer s3cr3t"))
+		info = &grpc.UnaryServerInfo{FullMethod: "/v1.FinanceService/StopAlgorithm"}
+	)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		id, ok := FromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return id.Name, nil
+	}
+
+	res, err := NewAuthenticator(Config{}).UnaryInterceptor()(ctx, nil, info, handler)
+	if err != nil || res != "anonymous" {
+		t.Errorf("disabled authentication: unexpected result %v (%v)", res, err)
+	}
+	res, err = NewAuthenticator(Config{Tokens: map[string]string{"s3cr3t": "alice"}}).UnaryInterceptor()(ctx, nil, info, handler)
+	if err != nil || res != "alice" {
+		t.Errorf("enabled authentication: unexpected result %v (%v)", res, err)
+	}
+	_, err = NewAuthenticator(Config{ClientCerts: true}).UnaryInterceptor()(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected an Unauthenticated error, got %v", err)
+	}
+}
+
+func TestHealthWithoutCredentials(t *testing.T) {
+	authenticator := NewAuthenticator(Config{Tokens: map[string]string{"s3cr3t": "alice"}})
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(authenticator.UnaryInterceptor()),
+		grpc.StreamInterceptor(authenticator.StreamInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	l := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil || resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("check: unexpected result %v (%v)", resp, err)
+	}
+	watch, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = watch.Recv()
+	if err != nil || resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("watch: unexpected result %v (%v)", resp, err)
+	}
+
+	// other methods still require credentials
+	info := &grpc.UnaryServerInfo{FullMethod: "/v1.FinanceService/ListAlgorithm"}
+	_, err = authenticator.UnaryInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected an Unauthenticated error, got %v", err)
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "tokens")
+	err := os.WriteFile(fn, []byte("# ops team\ns3cr3t, alice\n\nk3y,bob\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := LoadTokens(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens["s3cr3t"] != "alice" || tokens["k3y"] != "bob" {
+		t.Errorf("unexpected tokens: %v", tokens)
+	}
+
+	err = os.WriteFile(fn, []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTokens(fn); err == nil {
+		t.Error("expected an error for a line without identity")
+	}
+}
@@ -0,0 +1,214 @@
+package cron
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a parsed cron expression
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny record whether day of month and day of week were unrestricted,
+	// which decides how they combine
+	domAny, dowAny bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as Sunday as well and folded onto 0 after parsing
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression of five fields (minute, hour, day of month, month and day of week)
+// or one of the descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly.
+// Fields are lists of values, ranges (1-5) and steps (*/15, 1-30/2). Months and days of week may be
+// given by their three letter English names. If both day of month and day of week are restricted,
+// a day matches if either of them does.
+func Parse(expr string) (*Expression, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	} else if strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("unknown descriptor %s", expr)
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var (
+		res Expression
+		err error
+	)
+	if res.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if res.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if res.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if res.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if res.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if res.dow&(1<<7) != 0 {
+		res.dow |= 1
+	}
+	res.domAny = fields[2] == "*"
+	res.dowAny = fields[4] == "*"
+	return &res, nil
+}
+
+// parse turns a field into a bitset of the values it matches
+func (f field) parse(s string) (uint64, error) {
+	var res uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			var err error
+			rng = part[:idx]
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			idx := strings.Index(rng, "-")
+			var err error
+			if lo, err = f.value(rng[:idx]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[idx+1:]); err != nil {
+				return 0, err
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if step > 1 {
+				// a single value with a step runs until the end of the range, e.g. 5/15
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			res |= 1 << uint(v)
+		}
+	}
+	return res, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s: %q", f.name, s)
+	}
+	return v, nil
+}
+
+// maxSearch bounds the search for the next activation, e.g. of "0 0 30 2 *" which never happens
+const maxSearch = 5
+
+// Next returns the first activation strictly after t in the location of t. The zero time is
+// returned if there's no activation within the next five years.
+func (e *Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// activations are whole minutes
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Year() + maxSearch
+
+	for t.Year() <= limit {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		// hours and minutes advance in absolute time so that DST transitions can't trap us
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+			continue
+		}
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (e *Expression) dayMatches(t time.Time) bool {
+	dom := e.dom&(1<<uint(t.Day())) != 0
+	dow := e.dow&(1<<uint(t.Weekday())) != 0
+	if e.domAny || e.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,96 @@
+package cron
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2022, 3, 1, 10, 15, 30, 0, time.UTC), time.Date(2022, 3, 1, 10, 16, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2022, 3, 1, 10, 15, 0, 0, time.UTC), time.Date(2022, 3, 1, 10, 16, 0, 0, time.UTC)},
+		{"30 18 * * mon-fri", time.Date(2022, 3, 4, 18, 30, 0, 0, kolkata), time.Date(2022, 3, 7, 18, 30, 0, 0, kolkata)},
+		{"*/15 9-17 * * *", time.Date(2022, 3, 1, 17, 45, 0, 0, time.UTC), time.Date(2022, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2022, 3, 1, 10, 26, 0, 0, time.UTC), time.Date(2022, 3, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 5, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+		// day of month and day of week combine with "or" if both are restricted
+		{"0 12 13 * fri", time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 5, 6, 12, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC), time.Date(2022, 5, 8, 12, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2022, 12, 10, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2022, 12, 10, 7, 0, 0, 0, time.UTC), time.Date(2022, 12, 10, 8, 0, 0, 0, time.UTC)},
+		// 02:30 doesn't exist when clocks go forward, the run is skipped
+		{"30 2 * * *", time.Date(2022, 3, 27, 0, 0, 0, 0, berlin), time.Date(2022, 3, 28, 2, 30, 0, 0, berlin)},
+		// 02:30 happens twice when clocks go back, the run happens once
+		{"30 2 * * *", time.Date(2022, 10, 30, 2, 30, 0, 0, berlin).Add(time.Hour), time.Date(2022, 10, 31, 2, 30, 0, 0, berlin)},
+		{"0 6 * * *", time.Date(2022, 3, 27, 0, 0, 0, 0, berlin), time.Date(2022, 3, 27, 6, 0, 0, 0, berlin)},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			expr, err := Parse(test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			act := expr.Next(test.from)
+			if !act.Equal(test.want) {
+				t.Errorf("next activation after %v: want %v, got %v", test.from, test.want, act)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@fortnightly",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
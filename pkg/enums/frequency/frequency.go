@@ -5,16 +5,22 @@ type Type uint8
 const (
 	DAILY Type = iota + 1
 	WEEKLY
-  MONTHLY
+	MONTHLY
 	ANNUALLY
+	FORTNIGHTLY
+	QUARTERLY
+	HALF_YEARLY
 )
 
 // TODO: check if this assumption is OK
 var toValue = map[Type]int{
-	DAILY:    365,
-	WEEKLY:   52,
-	MONTHLY:  12,
-	ANNUALLY: 1,
+	DAILY:       365,
+	WEEKLY:      52,
+	MONTHLY:     12,
+	ANNUALLY:    1,
+	FORTNIGHTLY: 26,
+	QUARTERLY:   4,
+	HALF_YEARLY: 2,
 }
 
 func (t *Type) Value() int {
@@ -0,0 +1,8 @@
+package recasttype
+
+type Type uint8
+
+const (
+	KEEP_PAYMENT Type = iota + 1
+	KEEP_TENOR
+)
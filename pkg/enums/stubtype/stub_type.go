@@ -0,0 +1,8 @@
+package stubtype
+
+type Type uint8
+
+const (
+	SHORT Type = iota + 1
+	LONG
+)
@@ -0,0 +1,92 @@
+package executor
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var nonEnvChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// AnnotationEnvName returns the name of the environment variable an annotation is passed to steps in,
+// e.g. "FINANCE_ANNOTATION_VALUE_DATE" for "value-date"
+func AnnotationEnvName(key string) string {
+	return "FINANCE_ANNOTATION_" + nonEnvChars.ReplaceAllString(strings.ToUpper(key), "_")
+}
+
+// Environment returns the environment variables which tell the steps of a job about the Algorithm
+// they belong to. The workspace is the directory the steps find the workspace in.
+func (job Job) Environment(workspace string) []string {
+	env := []string{
+		"FINANCE_ALGORITHM_NAME=" + job.Name,
+		"FINANCE_WORKSPACE=" + workspace,
+	}
+	for _, a := range job.Annotations {
+		env = append(env, AnnotationEnvName(a.Key)+"="+a.Value)
+	}
+	return env
+}
+
+// markerRegexp matches log lines which are markers the log cutter acts on, other than slice content
+var markerRegexp = regexp.MustCompile(`^\[[^\]|]+\|(PHASE|DONE|FAIL|RESULT)\]`)
+
+// SliceWriter writes the output of a step as content of the step's log slice. Markers such as
+// results pass through as they are. It is safe to copy several streams at the same time.
+type SliceWriter struct {
+	Out   io.Writer
+	Slice string
+
+	mu sync.Mutex
+}
+
+// CopyLines copies the output line by line until the reader returns an error.
+// It returns nil once the reader is at its end.
+func (w *SliceWriter) CopyLines(in io.Reader) error {
+	rd := bufio.NewReader(in)
+	for {
+		line, err := rd.ReadString('\n')
+		if line != "" {
+			w.writeLine(strings.TrimSuffix(line, "\n"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (w *SliceWriter) writeLine(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if markerRegexp.MatchString(line) {
+		fmt.Fprintln(w.Out, line)
+		return
+	}
+	fmt.Fprintf(w.Out, "[%s] %s\n", w.Slice, line)
+}
@@ -0,0 +1,161 @@
+package executor
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+// ErrNotRunning is returned by Stop if the executor does not run an Algorithm of that name
+var ErrNotRunning = errors.New("algorithm is not running")
+
+// Executor runs Algorithms
+type Executor interface {
+	// Start starts running an Algorithm in the background. Once Start returns, the executor
+	// reports its progress through the job's OnUpdate function until the Algorithm is done.
+	Start(job Job) error
+
+	// Stop stops a running Algorithm. The executor reports the Algorithm done once it has stopped.
+	Stop(name, reason string) error
+}
+
+// Job is an Algorithm an executor is asked to run
+type Job struct {
+	// Name is the name of the Algorithm
+	Name string
+
+	// Spec describes what to run
+	Spec Spec
+
+	// Workspace is the directory containing the application of the Algorithm
+	Workspace string
+
+	// Annotations are the annotations the Algorithm was started with
+	Annotations []*v1.Annotation
+
+	// Log receives the log output of the Algorithm. It must not be written to once
+	// the Algorithm was reported done.
+	Log io.Writer
+
+	// OnUpdate is called whenever the Algorithm makes progress. Calls happen one after another,
+	// the last one reports the Algorithm done.
+	OnUpdate func(Update)
+}
+
+// Update describes the progress of an Algorithm
+type Update struct {
+	Phase        v1.AlgorithmPhase
+	Details      string
+	Success      bool
+	FailureCount int32
+	DidExecute   bool
+}
+
+// Spec is the part of an Algorithm YAML file which describes how to run the Algorithm
+type Spec struct {
+	// Steps run one after another until the first one fails
+	Steps []Step `json:"steps,omitempty"`
+
+	// Cleanup steps run once all other steps are done, even if those failed or were stopped
+	Cleanup []Step `json:"cleanup,omitempty"`
+
+	// Timeout limits the time all steps may take together. Zero means there's no limit.
+	Timeout Duration `json:"timeout,omitempty"`
+
+	// Image is the container image the steps run in, if the executor uses containers
+	Image string `json:"image,omitempty"`
+}
+
+// Step is a single command run as part of an Algorithm
+type Step struct {
+	// Name identifies the step in the log output
+	Name string `json:"name"`
+
+	// Command is the command to run, starting with the executable
+	Command []string `json:"command"`
+
+	// Env sets additional environment variables for the command
+	Env map[string]string `json:"env,omitempty"`
+
+	// Timeout limits the time the step may take. Zero means there's no limit.
+	Timeout Duration `json:"timeout,omitempty"`
+
+	// AllowFailure continues with the next step if this one fails. The failure
+	// still counts towards the failure count of the Algorithm.
+	AllowFailure bool `json:"allowFailure,omitempty"`
+}
+
+var stepNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Validate checks that the steps of the spec can be run
+func (s Spec) Validate() error {
+	names := make(map[string]struct{})
+	for _, steps := range [][]Step{s.Steps, s.Cleanup} {
+		for i, step := range steps {
+			if !stepNameRegexp.MatchString(step.Name) {
+				return fmt.Errorf("step %d has an invalid name %q: names consist of letters, digits, '_', '.' and '-'", i, step.Name)
+			}
+			if _, exists := names[step.Name]; exists {
+				return fmt.Errorf("step name %s is not unique", step.Name)
+			}
+			names[step.Name] = struct{}{}
+			if len(step.Command) == 0 {
+				return fmt.Errorf("step %s has no command", step.Name)
+			}
+			if step.Timeout < 0 {
+				return fmt.Errorf("step %s has a negative timeout", step.Name)
+			}
+		}
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+// Duration is a time.Duration which reads from and writes to JSON as string, e.g. "5m"
+type Duration time.Duration
+
+// MarshalJSON marshals the duration as string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string such as "1h30m"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
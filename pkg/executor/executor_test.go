@@ -0,0 +1,76 @@
+package executor
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	step := func(name string) Step { return Step{Name: name, Command: []string{"true"}} }
+	tests := []struct {
+		name  string
+		spec  Spec
+		valid bool
+	}{
+		{name: "empty", spec: Spec{}, valid: true},
+		{name: "steps", spec: Spec{Steps: []Step{step("fetch"), step("build.curve")}, Cleanup: []Step{step("tidy")}}, valid: true},
+		{name: "no name", spec: Spec{Steps: []Step{step("")}}},
+		{name: "marker name", spec: Spec{Steps: []Step{step("fetch|DONE")}}},
+		{name: "duplicate name", spec: Spec{Steps: []Step{step("fetch")}, Cleanup: []Step{step("fetch")}}},
+		{name: "no command", spec: Spec{Steps: []Step{{Name: "fetch"}}}},
+		{name: "negative timeout", spec: Spec{Timeout: Duration(-time.Second)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.spec.Validate()
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var step Step
+	err := json.Unmarshal([]byte(`{"name":"fetch","timeout":"1m30s"}`), &step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(step.Timeout) != 90*time.Second {
+		t.Errorf("unexpected timeout: %v", time.Duration(step.Timeout))
+	}
+	out, err := json.Marshal(step.Timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `"1m30s"` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+	if err := json.Unmarshal([]byte(`90`), &step.Timeout); err == nil {
+		t.Error("expected an error for a number")
+	}
+}
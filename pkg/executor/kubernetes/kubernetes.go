@@ -0,0 +1,563 @@
+package kubernetes
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	labelManagedBy      = "app.kubernetes.io/managed-by"
+	managedBy           = "bhojpur-finance"
+	annotationAlgorithm = "finance.bhojpur.net/algorithm"
+
+	// workspacePath is where the steps find the workspace they share
+	workspacePath = "/workspace"
+)
+
+// Config configures the Kubernetes executor
+type Config struct {
+	// Namespace is the namespace the pods of Algorithms are created in
+	Namespace string
+
+	// DefaultImage is the image of Algorithms which don't name one in their spec
+	DefaultImage string
+
+	// KeepFinished is the time finished pods are kept around before they're garbage collected.
+	// Zero deletes pods as soon as their Algorithm is done.
+	KeepFinished time.Duration
+}
+
+// Executor runs each Algorithm as a pod. The steps of an Algorithm run one after another as
+// containers of the pod, sharing an empty workspace volume.
+type Executor struct {
+	Config Config
+	Client kubernetes.Interface
+
+	mu      sync.Mutex
+	running map[string]*run
+}
+
+var _ executor.Executor = &Executor{}
+
+// NewExecutor produces a new Kubernetes executor
+func NewExecutor(client kubernetes.Interface, cfg Config) *Executor {
+	if cfg.Namespace == "" {
+		cfg.Namespace = metav1.NamespaceDefault
+	}
+	return &Executor{
+		Config:  cfg,
+		Client:  client,
+		running: make(map[string]*run),
+	}
+}
+
+// Start creates the pod of an Algorithm and follows it until it's done
+func (e *Executor) Start(job executor.Job) error {
+	if err := job.Spec.Validate(); err != nil {
+		return err
+	}
+	if len(job.Spec.Cleanup) > 0 {
+		return fmt.Errorf("cleanup steps are not supported by the Kubernetes executor")
+	}
+	for _, step := range job.Spec.Steps {
+		if step.AllowFailure {
+			return fmt.Errorf("step %s: allowFailure is not supported by the Kubernetes executor", step.Name)
+		}
+	}
+	image := job.Spec.Image
+	if image == "" {
+		image = e.Config.DefaultImage
+	}
+	if image == "" && len(job.Spec.Steps) > 0 {
+		return fmt.Errorf("algorithm has no image")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, exists := e.running[job.Name]; exists {
+		return fmt.Errorf("algorithm %s is running already", job.Name)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &run{
+		job:     job,
+		pod:     PodName(job.Name),
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+	e.running[job.Name] = r
+
+	go e.run(ctx, r, image)
+	return nil
+}
+
+// Stop deletes the pod of a running Algorithm
+func (e *Executor) Stop(name, reason string) error {
+	e.mu.Lock()
+	r, ok := e.running[name]
+	e.mu.Unlock()
+	if !ok {
+		return executor.ErrNotRunning
+	}
+	r.stop(reason)
+	return nil
+}
+
+// CollectGarbage deletes the finished pods which have been kept long enough, including those
+// left behind by earlier instances of the server
+func (e *Executor) CollectGarbage(ctx context.Context) error {
+	pods := e.Client.CoreV1().Pods(e.Config.Namespace)
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: labelManagedBy + "=" + managedBy})
+	if err != nil {
+		return err
+	}
+	for _, pod := range list.Items {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		e.mu.Lock()
+		_, running := e.running[pod.Annotations[annotationAlgorithm]]
+		e.mu.Unlock()
+		if running || time.Since(finishedAt(&pod)) < e.Config.KeepFinished {
+			continue
+		}
+		err := pods.Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.WithField("pod", pod.Name).Debug("deleted finished pod")
+	}
+	return nil
+}
+
+// finishedAt returns the time the last container of a pod finished
+func finishedAt(pod *corev1.Pod) time.Time {
+	res := pod.CreationTimestamp.Time
+	for _, cs := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if t := cs.State.Terminated; t != nil && t.FinishedAt.After(res) {
+			res = t.FinishedAt.Time
+		}
+	}
+	return res
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// PodName returns the name of the pod an Algorithm runs in
+func PodName(algorithm string) string {
+	name := "finance-" + invalidNameChars.ReplaceAllString(strings.ToLower(algorithm), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-.")
+}
+
+// containerName returns the name of the container of a step. Step names may contain
+// characters container names must not, hence we number the containers instead.
+func containerName(step int) string {
+	return fmt.Sprintf("step-%d", step)
+}
+
+func (e *Executor) pod(job executor.Job, image string) *corev1.Pod {
+	var containers []corev1.Container
+	for i, step := range job.Spec.Steps {
+		c := corev1.Container{
+			Name:         containerName(i),
+			Image:        image,
+			Command:      step.Command,
+			WorkingDir:   workspacePath,
+			VolumeMounts: []corev1.VolumeMount{{Name: "workspace", MountPath: workspacePath}},
+		}
+		for _, kv := range job.Environment(workspacePath) {
+			segs := strings.SplitN(kv, "=", 2)
+			c.Env = append(c.Env, corev1.EnvVar{Name: segs[0], Value: segs[1]})
+		}
+		for k, v := range step.Env {
+			c.Env = append(c.Env, corev1.EnvVar{Name: k, Value: v})
+		}
+		containers = append(containers, c)
+	}
+
+	var deadline *int64
+	if job.Spec.Timeout > 0 {
+		secs := int64(time.Duration(job.Spec.Timeout).Seconds())
+		deadline = &secs
+	}
+	noToken := false
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        PodName(job.Name),
+			Namespace:   e.Config.Namespace,
+			Labels:      map[string]string{labelManagedBy: managedBy},
+			Annotations: map[string]string{annotationAlgorithm: job.Name},
+		},
+		Spec: corev1.PodSpec{
+			// the steps run one after another as init containers, except for the last one
+			InitContainers:               containers[:len(containers)-1],
+			Containers:                   containers[len(containers)-1:],
+			RestartPolicy:                corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds:        deadline,
+			AutomountServiceAccountToken: &noToken,
+			Volumes: []corev1.Volume{{
+				Name:         "workspace",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+		},
+	}
+}
+
+// run is an Algorithm the executor runs. It holds the latest state of the Algorithm's pod.
+type run struct {
+	job    executor.Job
+	pod    string
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	reason  string
+	latest  *corev1.Pod
+	gone    bool
+	changed chan struct{}
+}
+
+func (r *run) stop(reason string) {
+	r.mu.Lock()
+	if r.reason == "" {
+		r.reason = reason
+	}
+	r.mu.Unlock()
+	r.cancel()
+}
+
+func (r *run) update(pod *corev1.Pod, gone bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latest = pod
+	r.gone = r.gone || gone
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// snapshot returns the latest state of the pod and a channel which is closed once that changes
+func (r *run) snapshot() (pod *corev1.Pod, gone bool, changed <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latest, r.gone, r.changed
+}
+
+// waitFor waits until the container of a step satisfies the condition. It returns false if the
+// pod finished before that happened.
+func (r *run) waitFor(container string, cond func(*corev1.ContainerState) bool) (*corev1.ContainerState, bool) {
+	for {
+		pod, gone, changed := r.snapshot()
+		if cs := containerStatus(pod, container); cs != nil && cond(&cs.State) {
+			return &cs.State, true
+		}
+		if gone || isFinished(pod) {
+			return nil, false
+		}
+		<-changed
+	}
+}
+
+func containerStatus(pod *corev1.Pod, name string) *corev1.ContainerStatus {
+	if pod == nil {
+		return nil
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if statuses[i].Name == name {
+				return &statuses[i]
+			}
+		}
+	}
+	return nil
+}
+
+func isFinished(pod *corev1.Pod) bool {
+	return pod != nil && (pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed)
+}
+
+func (e *Executor) run(ctx context.Context, r *run, image string) {
+	defer func() {
+		r.cancel()
+		e.mu.Lock()
+		delete(e.running, r.job.Name)
+		e.mu.Unlock()
+	}()
+
+	job := r.job
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+	if len(job.Spec.Steps) == 0 {
+		job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING})
+		job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_CLEANUP})
+		job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_DONE, Success: true})
+		return
+	}
+
+	pods := e.Client.CoreV1().Pods(e.Config.Namespace)
+	pod, err := pods.Create(context.Background(), e.pod(job, image), metav1.CreateOptions{})
+	if err != nil {
+		job.OnUpdate(executor.Update{
+			Phase:        v1.AlgorithmPhase_PHASE_DONE,
+			Details:      fmt.Sprintf("cannot create pod: %v", err),
+			FailureCount: 1,
+		})
+		return
+	}
+	r.update(pod, false)
+
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	go e.watch(watchCtx, r)
+	logsDone := make(chan struct{})
+	go func() {
+		defer close(logsDone)
+		e.streamLogs(watchCtx, r)
+	}()
+
+	e.follow(ctx, r)
+	<-logsDone
+	stopWatching()
+
+	pod, gone, _ := r.snapshot()
+	outcome := r.outcome(pod, gone)
+	outcome.Phase = v1.AlgorithmPhase_PHASE_CLEANUP
+	job.OnUpdate(outcome)
+	if !gone && e.Config.KeepFinished == 0 {
+		err := pods.Delete(context.Background(), r.pod, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			log.WithError(err).WithField("pod", r.pod).Warn("cannot delete finished pod")
+		}
+	}
+	outcome.Phase = v1.AlgorithmPhase_PHASE_DONE
+	job.OnUpdate(outcome)
+}
+
+// watch keeps the pod state of the run up to date until the context is canceled
+func (e *Executor) watch(ctx context.Context, r *run) {
+	pods := e.Client.CoreV1().Pods(e.Config.Namespace)
+	for ctx.Err() == nil {
+		pod, err := pods.Get(ctx, r.pod, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			latest, _, _ := r.snapshot()
+			r.update(latest, true)
+			return
+		}
+		if err == nil {
+			r.update(pod, false)
+			var w watch.Interface
+			w, err = pods.Watch(ctx, metav1.ListOptions{
+				FieldSelector:   fields.OneTermEqualSelector("metadata.name", r.pod).String(),
+				ResourceVersion: pod.ResourceVersion,
+			})
+			if err == nil {
+				for evt := range w.ResultChan() {
+					pod, ok := evt.Object.(*corev1.Pod)
+					if !ok || pod.Name != r.pod {
+						continue
+					}
+					r.update(pod, evt.Type == watch.Deleted)
+					if evt.Type == watch.Deleted {
+						w.Stop()
+						return
+					}
+				}
+				w.Stop()
+				continue
+			}
+		}
+		if ctx.Err() == nil {
+			log.WithError(err).WithField("pod", r.pod).Warn("cannot watch pod, retrying")
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// follow reports the phase of the Algorithm as its pod progresses and enforces step timeouts.
+// It returns once the pod is finished or gone.
+func (e *Executor) follow(ctx context.Context, r *run) {
+	var (
+		job      = r.job
+		reported = executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING}
+		stopped  = ctx.Done()
+	)
+	for {
+		pod, gone, changed := r.snapshot()
+		if gone || isFinished(pod) {
+			return
+		}
+
+		update, step, started := podProgress(job, pod)
+		if update != reported {
+			job.OnUpdate(update)
+			reported = update
+		}
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if step >= 0 && job.Spec.Steps[step].Timeout > 0 {
+			timer = time.NewTimer(time.Until(started.Add(time.Duration(job.Spec.Steps[step].Timeout))))
+			timeout = timer.C
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			s := job.Spec.Steps[step]
+			r.stop(fmt.Sprintf("step %s failed: timed out after %s", s.Name, time.Duration(s.Timeout)))
+		case <-stopped:
+			stopped = nil
+			err := e.Client.CoreV1().Pods(e.Config.Namespace).Delete(context.Background(), r.pod, metav1.DeleteOptions{})
+			if errors.IsNotFound(err) {
+				r.update(pod, true)
+			} else if err != nil {
+				log.WithError(err).WithField("pod", r.pod).Error("cannot delete pod of stopped algorithm")
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// podProgress determines the phase of an Algorithm from the state of its pod. It returns the
+// step which is running, if any, and the time it started.
+func podProgress(job executor.Job, pod *corev1.Pod) (update executor.Update, step int, started time.Time) {
+	update = executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING}
+	step = -1
+	for i := range job.Spec.Steps {
+		cs := containerStatus(pod, containerName(i))
+		if cs == nil {
+			continue
+		}
+		switch {
+		case cs.State.Running != nil:
+			return executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING, DidExecute: true}, i, cs.State.Running.StartedAt.Time
+		case cs.State.Terminated != nil:
+			update = executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING, DidExecute: true}
+		case cs.State.Waiting != nil && update.Phase == v1.AlgorithmPhase_PHASE_STARTING:
+			// e.g. while the image is pulled, or if pulling it failed
+			if w := cs.State.Waiting; w.Reason != "" && w.Reason != "PodInitializing" && w.Reason != "ContainerCreating" {
+				update.Details = strings.TrimSuffix(w.Reason+": "+w.Message, ": ")
+			}
+		}
+	}
+	return update, step, started
+}
+
+// outcome determines how an Algorithm went from the final state of its pod
+func (r *run) outcome(pod *corev1.Pod, gone bool) executor.Update {
+	var res executor.Update
+	for i, step := range r.job.Spec.Steps {
+		cs := containerStatus(pod, containerName(i))
+		if cs == nil {
+			continue
+		}
+		if cs.State.Running != nil || cs.State.Terminated != nil {
+			res.DidExecute = true
+		}
+		if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+			res.FailureCount++
+			if res.Details == "" {
+				res.Details = fmt.Sprintf("step %s failed: exit code %d", step.Name, t.ExitCode)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	reason := r.reason
+	r.mu.Unlock()
+	switch {
+	case reason != "":
+		res.Details = reason
+	case pod.Status.Phase == corev1.PodSucceeded && !gone:
+		res.Success = true
+		return res
+	case pod.Status.Reason == "DeadlineExceeded":
+		res.Details = fmt.Sprintf("timed out after %s", time.Duration(r.job.Spec.Timeout))
+	case res.Details == "" && gone:
+		res.Details = "pod was deleted"
+	case res.Details == "":
+		res.Details = strings.TrimSuffix("pod failed: "+pod.Status.Message, ": ")
+	}
+	if res.FailureCount == 0 {
+		res.FailureCount = 1
+	}
+	return res
+}
+
+// streamLogs copies the log of each step into the Algorithm's log, marking it up as a slice per step
+func (e *Executor) streamLogs(ctx context.Context, r *run) {
+	var (
+		out  = r.job.Log
+		pods = e.Client.CoreV1().Pods(e.Config.Namespace)
+	)
+	for i, step := range r.job.Spec.Steps {
+		container := containerName(i)
+		_, ok := r.waitFor(container, func(s *corev1.ContainerState) bool { return s.Running != nil || s.Terminated != nil })
+		if !ok {
+			return
+		}
+
+		fmt.Fprintf(out, "[%s|PHASE] %s\n", step.Name, strings.Join(step.Command, " "))
+		stream, err := pods.GetLogs(r.pod, &corev1.PodLogOptions{Container: container, Follow: true}).Stream(ctx)
+		if err == nil {
+			err = (&executor.SliceWriter{Out: out, Slice: step.Name}).CopyLines(stream)
+			stream.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(out, "[%s] cannot read log: %v\n", step.Name, err)
+		}
+
+		state, ok := r.waitFor(container, func(s *corev1.ContainerState) bool { return s.Terminated != nil })
+		switch {
+		case !ok:
+			fmt.Fprintf(out, "[%s|FAIL] interrupted\n", step.Name)
+			return
+		case state.Terminated.ExitCode != 0:
+			fmt.Fprintf(out, "[%s|FAIL] exit code %d\n", step.Name, state.Terminated.ExitCode)
+			return
+		default:
+			fmt.Fprintf(out, "[%s|DONE]\n", step.Name)
+		}
+	}
+}
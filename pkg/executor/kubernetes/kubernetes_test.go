@@ -0,0 +1,342 @@
+package kubernetes
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type testJob struct {
+	log     lockedBuffer
+	updates chan executor.Update
+}
+
+func startJob(t *testing.T, e *Executor, name string, spec executor.Spec) *testJob {
+	res := &testJob{updates: make(chan executor.Update, 10)}
+	err := e.Start(executor.Job{
+		Name:        name,
+		Spec:        spec,
+		Annotations: []*v1.Annotation{{Key: "value-date", Value: "2021-06-30"}},
+		Log:         &res.log,
+		OnUpdate:    func(u executor.Update) { res.updates <- u },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func (j *testJob) expect(t *testing.T, want executor.Update) {
+	t.Helper()
+	select {
+	case u := <-j.updates:
+		if u != want {
+			t.Fatalf("unexpected update:\nwant %+v\n got %+v", want, u)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout while waiting for update %+v", want)
+	}
+}
+
+func waitForPod(t *testing.T, client *fake.Clientset, name string) *corev1.Pod {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		pod, err := client.CoreV1().Pods("default").Get(context.Background(), name, metav1.GetOptions{})
+		if err == nil {
+			return pod
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("pod %s was not created", name)
+	return nil
+}
+
+func setStatus(t *testing.T, client *fake.Clientset, pod *corev1.Pod, status corev1.PodStatus) {
+	t.Helper()
+	pod = pod.DeepCopy()
+	pod.Status = status
+	_, err := client.CoreV1().Pods("default").UpdateStatus(context.Background(), pod, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func running() corev1.ContainerState {
+	return corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}}
+}
+
+func terminated(code int32) corev1.ContainerState {
+	return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: code, FinishedAt: metav1.Now()}}
+}
+
+var testSpec = executor.Spec{
+	Image: "bhojpur/eod-curve:1",
+	Steps: []executor.Step{
+		{Name: "fetch", Command: []string{"fetch-quotes"}},
+		{Name: "build", Command: []string{"build-curve", "--all"}},
+	},
+	Timeout: executor.Duration(time.Hour),
+}
+
+func TestRun(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	e := NewExecutor(client, Config{})
+	job := startJob(t, e, "eod-curve.1", testSpec)
+	job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+
+	pod := waitForPod(t, client, "finance-eod-curve.1")
+	if len(pod.Spec.InitContainers) != 1 || len(pod.Spec.Containers) != 1 {
+		t.Fatalf("unexpected containers: %v, %v", pod.Spec.InitContainers, pod.Spec.Containers)
+	}
+	if c := pod.Spec.Containers[0]; c.Image != "bhojpur/eod-curve:1" || strings.Join(c.Command, " ") != "build-curve --all" {
+		t.Errorf("unexpected container: %v", c)
+	}
+	var env []string
+	for _, ev := range pod.Spec.InitContainers[0].Env {
+		env = append(env, ev.Name+"="+ev.Value)
+	}
+	if want := "FINANCE_ALGORITHM_NAME=eod-curve.1 FINANCE_WORKSPACE=/workspace FINANCE_ANNOTATION_VALUE_DATE=2021-06-30"; strings.Join(env, " ") != want {
+		t.Errorf("unexpected environment: want %s, got %s", want, strings.Join(env, " "))
+	}
+	if d := pod.Spec.ActiveDeadlineSeconds; d == nil || *d != 3600 {
+		t.Errorf("unexpected active deadline: %v", d)
+	}
+
+	setStatus(t, client, pod, corev1.PodStatus{
+		Phase: corev1.PodPending,
+		InitContainerStatuses: []corev1.ContainerStatus{{Name: "step-0", State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"},
+		}}},
+	})
+	job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING, Details: "ErrImagePull: not found"})
+
+	setStatus(t, client, pod, corev1.PodStatus{
+		Phase:                 corev1.PodPending,
+		InitContainerStatuses: []corev1.ContainerStatus{{Name: "step-0", State: running()}},
+	})
+	job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING, DidExecute: true})
+
+	setStatus(t, client, pod, corev1.PodStatus{
+		Phase:                 corev1.PodSucceeded,
+		InitContainerStatuses: []corev1.ContainerStatus{{Name: "step-0", State: terminated(0)}},
+		ContainerStatuses:     []corev1.ContainerStatus{{Name: "step-1", State: terminated(0)}},
+	})
+	job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_CLEANUP, Success: true, DidExecute: true})
+	job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_DONE, Success: true, DidExecute: true})
+
+	want := "[fetch|PHASE] fetch-quotes\n[fetch] fake logs\n[fetch|DONE]\n" +
+		"[build|PHASE] build-curve --all\n[build] fake logs\n[build|DONE]\n"
+	if log := job.log.String(); log != want {
+		t.Errorf("unexpected log:\nwant %q\n got %q", want, log)
+	}
+	_, err := client.CoreV1().Pods("default").Get(context.Background(), pod.Name, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("finished pod was not deleted: %v", err)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	e := NewExecutor(client, Config{KeepFinished: time.Hour})
+	job := startJob(t, e, "eod-curve.1", testSpec)
+	job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+
+	pod := waitForPod(t, client, "finance-eod-curve.1")
+	setStatus(t, client, pod, corev1.PodStatus{
+		Phase:                 corev1.PodFailed,
+		InitContainerStatuses: []corev1.ContainerStatus{{Name: "step-0", State: terminated(2)}},
+	})
+	outcome := executor.Update{Details: "step fetch failed: exit code 2", FailureCount: 1, DidExecute: true}
+	outcome.Phase = v1.AlgorithmPhase_PHASE_CLEANUP
+	job.expect(t, outcome)
+	outcome.Phase = v1.AlgorithmPhase_PHASE_DONE
+	job.expect(t, outcome)
+
+	want := "[fetch|PHASE] fetch-quotes\n[fetch] fake logs\n[fetch|FAIL] exit code 2\n"
+	if log := job.log.String(); log != want {
+		t.Errorf("unexpected log:\nwant %q\n got %q", want, log)
+	}
+	_, err := client.CoreV1().Pods("default").Get(context.Background(), pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("finished pod was not kept: %v", err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	tests := []struct {
+		name    string
+		stop    func(e *Executor) error
+		timeout executor.Duration
+		details string
+	}{
+		{
+			name:    "stop",
+			stop:    func(e *Executor) error { return e.Stop("eod-curve.1", "stopped") },
+			details: "stopped",
+		},
+		{
+			name:    "step timeout",
+			stop:    func(e *Executor) error { return nil },
+			timeout: executor.Duration(50 * time.Millisecond),
+			details: "step fetch failed: timed out after 50ms",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			e := NewExecutor(client, Config{})
+			spec := executor.Spec{
+				Image: "bhojpur/eod-curve:1",
+				Steps: []executor.Step{{Name: "fetch", Command: []string{"fetch-quotes"}, Timeout: test.timeout}},
+			}
+			job := startJob(t, e, "eod-curve.1", spec)
+			job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+
+			pod := waitForPod(t, client, "finance-eod-curve.1")
+			setStatus(t, client, pod, corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "step-0", State: running()}},
+			})
+			job.expect(t, executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING, DidExecute: true})
+
+			if err := test.stop(e); err != nil {
+				t.Fatal(err)
+			}
+			outcome := executor.Update{Details: test.details, FailureCount: 1, DidExecute: true}
+			outcome.Phase = v1.AlgorithmPhase_PHASE_CLEANUP
+			job.expect(t, outcome)
+			outcome.Phase = v1.AlgorithmPhase_PHASE_DONE
+			job.expect(t, outcome)
+
+			_, err := client.CoreV1().Pods("default").Get(context.Background(), pod.Name, metav1.GetOptions{})
+			if !errors.IsNotFound(err) {
+				t.Errorf("pod of stopped algorithm was not deleted: %v", err)
+			}
+		})
+	}
+}
+
+func TestStartInvalid(t *testing.T) {
+	step := executor.Step{Name: "fetch", Command: []string{"fetch-quotes"}}
+	lenient := step
+	lenient.AllowFailure = true
+	tests := []struct {
+		name string
+		spec executor.Spec
+	}{
+		{name: "no image", spec: executor.Spec{Steps: []executor.Step{step}}},
+		{name: "allow failure", spec: executor.Spec{Image: "foo", Steps: []executor.Step{lenient}}},
+		{name: "cleanup", spec: executor.Spec{Image: "foo", Cleanup: []executor.Step{step}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := NewExecutor(fake.NewSimpleClientset(), Config{})
+			err := e.Start(executor.Job{Name: "eod-curve.1", Spec: test.spec})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	pod := func(name string, managed bool, phase corev1.PodPhase, finished time.Time) *corev1.Pod {
+		res := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: corev1.PodStatus{
+				Phase: phase,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "step-0", State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(finished)},
+				}}},
+			},
+		}
+		if managed {
+			res.Labels = map[string]string{labelManagedBy: managedBy}
+		}
+		return res
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	client := fake.NewSimpleClientset(
+		pod("finance-old.1", true, corev1.PodSucceeded, old),
+		pod("finance-failed.1", true, corev1.PodFailed, old),
+		pod("finance-recent.1", true, corev1.PodSucceeded, time.Now()),
+		pod("finance-running.1", true, corev1.PodRunning, old),
+		pod("someone-else", false, corev1.PodSucceeded, old),
+	)
+	e := NewExecutor(client, Config{KeepFinished: time.Hour})
+	if err := e.CollectGarbage(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range list.Items {
+		names = append(names, p.Name)
+	}
+	if want := "finance-recent.1 finance-running.1 someone-else"; strings.Join(names, " ") != want {
+		t.Errorf("unexpected remaining pods: want %s, got %s", want, strings.Join(names, " "))
+	}
+}
+
+func TestPodName(t *testing.T) {
+	tests := map[string]string{
+		"eod-curve.1":                  "finance-eod-curve.1",
+		"EOD_Curve-nightly.12":         "finance-eod-curve-nightly.12",
+		strings.Repeat("a", 70) + ".1": "finance-" + strings.Repeat("a", 55),
+	}
+	for name, want := range tests {
+		if act := PodName(name); act != want {
+			t.Errorf("PodName(%q): want %q, got %q", name, want, act)
+		}
+	}
+}
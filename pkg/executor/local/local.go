@@ -0,0 +1,309 @@
+package local
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+)
+
+// errInterrupted is reported for steps which were stopped or ran out of time
+var errInterrupted = errors.New("interrupted")
+
+// Config configures the local executor
+type Config struct {
+	// PassEnv names the environment variables of the server which are passed on to the steps.
+	// All other variables are withheld so that steps don't see the server's secrets.
+	PassEnv []string
+
+	// KillTimeout is the time a step has to exit after it was asked to terminate before it's killed
+	KillTimeout time.Duration
+
+	// CleanupTimeout limits the time of cleanup steps which have no timeout of their own
+	CleanupTimeout time.Duration
+}
+
+// Executor runs the steps of Algorithms as subprocesses in their workspace. Each step runs
+// in its own process group, which is killed once the step is done.
+type Executor struct {
+	Config Config
+
+	mu      sync.Mutex
+	running map[string]*run
+}
+
+var _ executor.Executor = &Executor{}
+
+// NewExecutor produces a new local executor
+func NewExecutor(cfg Config) *Executor {
+	if cfg.PassEnv == nil {
+		cfg.PassEnv = []string{"PATH", "LANG", "TZ"}
+	}
+	if cfg.KillTimeout == 0 {
+		cfg.KillTimeout = 10 * time.Second
+	}
+	if cfg.CleanupTimeout == 0 {
+		cfg.CleanupTimeout = time.Minute
+	}
+	return &Executor{
+		Config:  cfg,
+		running: make(map[string]*run),
+	}
+}
+
+// run is an Algorithm the executor runs
+type run struct {
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	reason string
+}
+
+func (r *run) stop(reason string) {
+	r.mu.Lock()
+	if r.reason == "" {
+		r.reason = reason
+	}
+	r.mu.Unlock()
+	r.cancel()
+}
+
+func (r *run) stopReason() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reason
+}
+
+// Start starts running the steps of an Algorithm
+func (e *Executor) Start(job executor.Job) error {
+	if err := job.Spec.Validate(); err != nil {
+		return err
+	}
+	if stat, err := os.Stat(job.Workspace); err != nil || !stat.IsDir() {
+		return fmt.Errorf("workspace %s is not a directory", job.Workspace)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, exists := e.running[job.Name]; exists {
+		return fmt.Errorf("algorithm %s is running already", job.Name)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &run{cancel: cancel}
+	e.running[job.Name] = r
+
+	go e.run(ctx, r, job)
+	return nil
+}
+
+// Stop stops a running Algorithm. Its cleanup steps still run.
+func (e *Executor) Stop(name, reason string) error {
+	e.mu.Lock()
+	r, ok := e.running[name]
+	e.mu.Unlock()
+	if !ok {
+		return executor.ErrNotRunning
+	}
+	r.stop(reason)
+	return nil
+}
+
+func (e *Executor) run(ctx context.Context, r *run, job executor.Job) {
+	defer func() {
+		r.cancel()
+		e.mu.Lock()
+		delete(e.running, job.Name)
+		e.mu.Unlock()
+	}()
+
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+
+	if job.Spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.Spec.Timeout))
+		defer cancel()
+	}
+	var (
+		env        = e.environment(job)
+		success    = true
+		failures   int32
+		details    string
+		didExecute = len(job.Spec.Steps) > 0
+	)
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING, DidExecute: didExecute})
+	for _, step := range job.Spec.Steps {
+		if ctx.Err() != nil {
+			break
+		}
+		err := e.runStep(ctx, job, step, env)
+		if err == nil {
+			continue
+		}
+		failures++
+		if ctx.Err() == nil && step.AllowFailure {
+			continue
+		}
+		success = false
+		details = fmt.Sprintf("step %s failed: %v", step.Name, err)
+		break
+	}
+	if ctx.Err() != nil {
+		success = false
+		if reason := r.stopReason(); reason != "" {
+			details = reason
+		} else {
+			details = fmt.Sprintf("timed out after %s", time.Duration(job.Spec.Timeout))
+		}
+	}
+
+	job.OnUpdate(executor.Update{
+		Phase:        v1.AlgorithmPhase_PHASE_CLEANUP,
+		Details:      details,
+		FailureCount: failures,
+		DidExecute:   didExecute,
+	})
+	for _, step := range job.Spec.Cleanup {
+		// cleanup has to happen even if the Algorithm was stopped
+		if step.Timeout == 0 {
+			step.Timeout = executor.Duration(e.Config.CleanupTimeout)
+		}
+		err := e.runStep(context.Background(), job, step, env)
+		if err == nil {
+			continue
+		}
+		failures++
+		if step.AllowFailure {
+			continue
+		}
+		if success {
+			success = false
+			details = fmt.Sprintf("cleanup step %s failed: %v", step.Name, err)
+		}
+	}
+
+	job.OnUpdate(executor.Update{
+		Phase:        v1.AlgorithmPhase_PHASE_DONE,
+		Details:      details,
+		Success:      success,
+		FailureCount: failures,
+		DidExecute:   didExecute,
+	})
+}
+
+// environment produces the environment variables all steps of a job run with
+func (e *Executor) environment(job executor.Job) []string {
+	env := []string{"HOME=" + job.Workspace}
+	for _, name := range e.Config.PassEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return append(env, job.Environment(job.Workspace)...)
+}
+
+// runStep runs a single step and marks its output up as a log slice named after the step
+func (e *Executor) runStep(ctx context.Context, job executor.Job, step executor.Step, env []string) error {
+	stepCtx := ctx
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(ctx, time.Duration(step.Timeout))
+		defer cancel()
+	}
+
+	fmt.Fprintf(job.Log, "[%s|PHASE] %s\n", step.Name, strings.Join(step.Command, " "))
+	err := e.execute(stepCtx, job, step, env)
+	if errors.Is(err, errInterrupted) && ctx.Err() == nil {
+		err = fmt.Errorf("timed out after %s", time.Duration(step.Timeout))
+	}
+	if err != nil {
+		fmt.Fprintf(job.Log, "[%s|FAIL] %v\n", step.Name, err)
+		return err
+	}
+	fmt.Fprintf(job.Log, "[%s|DONE]\n", step.Name)
+	return nil
+}
+
+func (e *Executor) execute(ctx context.Context, job executor.Job, step executor.Step, env []string) error {
+	cmd := exec.Command(step.Command[0], step.Command[1:]...)
+	cmd.Dir = job.Workspace
+	cmd.Env = append([]string{}, env...)
+	for k, v := range step.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	isolate(cmd)
+
+	// We hand the process the write end of pipes rather than a writer so that Wait returns
+	// once the step exits, even if processes it left behind still hold on to its output.
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer outR.Close()
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outW.Close()
+		return err
+	}
+	defer errR.Close()
+	cmd.Stdout = outW
+	cmd.Stderr = errW
+	err = cmd.Start()
+	outW.Close()
+	errW.Close()
+	if err != nil {
+		return err
+	}
+
+	out := &executor.SliceWriter{Out: job.Log, Slice: step.Name}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); _ = out.CopyLines(outR) }()
+	go func() { defer wg.Done(); _ = out.CopyLines(errR) }()
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err = <-exited:
+	case <-ctx.Done():
+		signalGroup(cmd, terminateSignal)
+		select {
+		case <-exited:
+		case <-time.After(e.Config.KillTimeout):
+			signalGroup(cmd, killSignal)
+			<-exited
+		}
+		err = errInterrupted
+	}
+
+	// processes the step left behind in the background must not outlive it
+	signalGroup(cmd, killSignal)
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,247 @@
+package local
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type testJob struct {
+	log     lockedBuffer
+	updates chan executor.Update
+}
+
+func startJob(t *testing.T, e *Executor, name string, spec executor.Spec) *testJob {
+	res := &testJob{updates: make(chan executor.Update, 10)}
+	err := e.Start(executor.Job{
+		Name:        name,
+		Spec:        spec,
+		Workspace:   t.TempDir(),
+		Annotations: []*v1.Annotation{{Key: "value-date", Value: "2021-06-30"}},
+		Log:         &res.log,
+		OnUpdate:    func(u executor.Update) { res.updates <- u },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// wait returns all updates up to and including the one which reports the job done
+func (j *testJob) wait(t *testing.T) []executor.Update {
+	var res []executor.Update
+	for {
+		select {
+		case u := <-j.updates:
+			res = append(res, u)
+			if u.Phase == v1.AlgorithmPhase_PHASE_DONE {
+				return res
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout while waiting for the job to finish: %v", res)
+		}
+	}
+}
+
+func sh(name, script string) executor.Step {
+	return executor.Step{Name: name, Command: []string{"sh", "-c", script}}
+}
+
+func TestRun(t *testing.T) {
+	allowed := sh("lint", "exit 3")
+	allowed.AllowFailure = true
+	slow := sh("slow", "sleep 10")
+	slow.Timeout = executor.Duration(100 * time.Millisecond)
+
+	tests := []struct {
+		name    string
+		spec    executor.Spec
+		success bool
+		details string
+		failed  int32
+		log     string
+	}{
+		{
+			name: "success",
+			spec: executor.Spec{Steps: []executor.Step{
+				sh("fetch", "echo loading; echo '[curve|RESULT] done'"),
+				sh("env", "echo $FINANCE_ANNOTATION_VALUE_DATE $FINANCE_ALGORITHM_NAME; echo oops >&2"),
+			}},
+			success: true,
+			log: "[fetch|PHASE] sh -c echo loading; echo '[curve|RESULT] done'\n" +
+				"[fetch] loading\n" +
+				"[curve|RESULT] done\n" +
+				"[fetch|DONE]\n" +
+				"[env|PHASE] sh -c echo $FINANCE_ANNOTATION_VALUE_DATE $FINANCE_ALGORITHM_NAME; echo oops >&2\n" +
+				"[env] 2021-06-30 success\n" +
+				"[env] oops\n" +
+				"[env|DONE]\n",
+		},
+		{
+			name: "failure",
+			spec: executor.Spec{
+				Steps:   []executor.Step{sh("fetch", "exit 1"), sh("never", "echo never")},
+				Cleanup: []executor.Step{sh("tidy", "echo tidy")},
+			},
+			details: "step fetch failed: exit status 1",
+			failed:  1,
+			log: "[fetch|PHASE] sh -c exit 1\n" +
+				"[fetch|FAIL] exit status 1\n" +
+				"[tidy|PHASE] sh -c echo tidy\n" +
+				"[tidy] tidy\n" +
+				"[tidy|DONE]\n",
+		},
+		{
+			name:    "allowed failure",
+			spec:    executor.Spec{Steps: []executor.Step{allowed, sh("build", "true")}},
+			success: true,
+			failed:  1,
+			log: "[lint|PHASE] sh -c exit 3\n" +
+				"[lint|FAIL] exit status 3\n" +
+				"[build|PHASE] sh -c true\n" +
+				"[build|DONE]\n",
+		},
+		{
+			name:    "step timeout",
+			spec:    executor.Spec{Steps: []executor.Step{slow}},
+			details: "step slow failed: timed out after 100ms",
+			failed:  1,
+			log: "[slow|PHASE] sh -c sleep 10\n" +
+				"[slow|FAIL] timed out after 100ms\n",
+		},
+		{
+			name:    "algorithm timeout",
+			spec:    executor.Spec{Steps: []executor.Step{sh("slow", "sleep 10")}, Timeout: executor.Duration(100 * time.Millisecond)},
+			details: "timed out after 100ms",
+			failed:  1,
+			log: "[slow|PHASE] sh -c sleep 10\n" +
+				"[slow|FAIL] interrupted\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := NewExecutor(Config{})
+			job := startJob(t, e, test.name, test.spec)
+			updates := job.wait(t)
+
+			var phases []string
+			for _, u := range updates {
+				phases = append(phases, u.Phase.String())
+			}
+			if want := "PHASE_STARTING PHASE_RUNNING PHASE_CLEANUP PHASE_DONE"; strings.Join(phases, " ") != want {
+				t.Errorf("unexpected phases: want %s, got %s", want, strings.Join(phases, " "))
+			}
+			done := updates[len(updates)-1]
+			if done.Success != test.success {
+				t.Errorf("unexpected success: want %v, got %v", test.success, done.Success)
+			}
+			if done.Details != test.details {
+				t.Errorf("unexpected details: want %q, got %q", test.details, done.Details)
+			}
+			if done.FailureCount != test.failed {
+				t.Errorf("unexpected failure count: want %d, got %d", test.failed, done.FailureCount)
+			}
+			if !done.DidExecute {
+				t.Error("algorithm did not execute")
+			}
+			if log := job.log.String(); log != test.log {
+				t.Errorf("unexpected log:\nwant %q\n got %q", test.log, log)
+			}
+		})
+	}
+}
+
+func TestStop(t *testing.T) {
+	e := NewExecutor(Config{})
+	// the sleep in the background would keep running if only the shell was stopped
+	marker := filepath.Join(t.TempDir(), "marker")
+	job := startJob(t, e, "stop", executor.Spec{
+		Steps:   []executor.Step{sh("wait", "(sleep 1; touch "+marker+") & sleep 10")},
+		Cleanup: []executor.Step{sh("tidy", "true")},
+	})
+	if u := <-job.updates; u.Phase != v1.AlgorithmPhase_PHASE_STARTING {
+		t.Fatalf("unexpected first update: %v", u)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if err := e.Stop("stop", "stopped by test"); err != nil {
+		t.Fatal(err)
+	}
+	updates := job.wait(t)
+	done := updates[len(updates)-1]
+	if done.Success || done.Details != "stopped by test" {
+		t.Errorf("unexpected outcome: %+v", done)
+	}
+	if !strings.Contains(job.log.String(), "[tidy|DONE]") {
+		t.Errorf("cleanup did not run: %q", job.log.String())
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("background process survived the stopped step")
+	}
+	if err := e.Stop("stop", "again"); !errors.Is(err, executor.ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestStartInvalid(t *testing.T) {
+	e := NewExecutor(Config{})
+	err := e.Start(executor.Job{
+		Name:      "invalid",
+		Spec:      executor.Spec{Steps: []executor.Step{{Name: "no-command"}}},
+		Workspace: t.TempDir(),
+	})
+	if err == nil {
+		t.Error("expected an error for a step without command")
+	}
+	err = e.Start(executor.Job{Name: "no-workspace", Workspace: filepath.Join(t.TempDir(), "missing")})
+	if err == nil {
+		t.Error("expected an error for a missing workspace")
+	}
+}
@@ -0,0 +1,83 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"sync"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// listenerBufferSize is the number of updates buffered for each listener
+const listenerBufferSize = 100
+
+// emitter distributes Algorithm status updates to all listeners
+type emitter struct {
+	mu sync.RWMutex
+	// listeners maps the update channel of each listener to the channel which tells it that it missed updates
+	listeners map[chan *v1.AlgorithmStatus]chan struct{}
+}
+
+func newEmitter() *emitter {
+	return &emitter{listeners: make(map[chan *v1.AlgorithmStatus]chan struct{})}
+}
+
+// On registers a new listener. Callers must call the returned function once they're
+// no longer interested in updates. lagged receives a value when the listener could not
+// keep up and missed updates. Such listeners have to re-read the status from the store.
+func (e *emitter) On() (updates <-chan *v1.AlgorithmStatus, lagged <-chan struct{}, done func()) {
+	c := make(chan *v1.AlgorithmStatus, listenerBufferSize)
+	l := make(chan struct{}, 1)
+
+	e.mu.Lock()
+	e.listeners[c] = l
+	e.mu.Unlock()
+
+	var once sync.Once
+	return c, l, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.listeners, c)
+			e.mu.Unlock()
+			close(c)
+		})
+	}
+}
+
+// Emit sends a status update to all listeners. Listeners which cannot keep up miss updates
+// and are marked as lagging. Emit must only be called once the update is stored.
+func (e *emitter) Emit(status *v1.AlgorithmStatus) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for c, lagged := range e.listeners {
+		select {
+		case c <- status:
+		default:
+			log.WithField("name", status.Name).Warn("listener is too slow - dropping algorithm update")
+			select {
+			case lagged <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/logcutter"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// unslicedChunkSize is the maximum payload size of a single unsliced log event
+const unslicedChunkSize = 4096
+
+// Subscribe listens to new Algorithm updates
+func (srv *Service) Subscribe(req *v1.SubscribeRequest, resp v1.FinanceService_SubscribeServer) error {
+	if err := query.Validate(req.Filter, nil, 0, 0); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	updates, _, done := srv.events.On()
+	defer done()
+
+	ctx := resp.Context()
+	for {
+		select {
+		case u := <-updates:
+			if !query.MatchesFilter(u, req.Filter) {
+				continue
+			}
+			if err := resp.Send(&v1.SubscribeResponse{Result: u}); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Listen listens to Algorithm updates and log output of a running Algorithm
+func (srv *Service) Listen(req *v1.ListenRequest, ls v1.FinanceService_ListenServer) error {
+	// we start listening for updates before we retrieve the algorithm so that we don't miss any
+	var (
+		updates <-chan *v1.AlgorithmStatus
+		lagged  <-chan struct{}
+	)
+	if req.Updates {
+		var done func()
+		updates, lagged, done = srv.events.On()
+		defer done()
+	}
+
+	ctx, cancel := context.WithCancel(ls.Context())
+	defer cancel()
+
+	algo, err := srv.Config.Algorithms.Get(ctx, req.Name)
+	if errors.Is(err, store.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "algorithm %s not found", req.Name)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	var mu sync.Mutex
+	send := func(resp *v1.ListenResponse) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return ls.Send(resp)
+	}
+
+	var tasks []func(context.Context) error
+	if req.Updates {
+		tasks = append(tasks, func(ctx context.Context) error {
+			return listenUpdates(ctx, algo, updates, lagged, srv.Config.Algorithms.Get, send)
+		})
+	}
+	switch req.Logs {
+	case v1.ListenRequestLogs_LOGS_DISABLED:
+	case v1.ListenRequestLogs_LOGS_UNSLICED, v1.ListenRequestLogs_LOGS_RAW, v1.ListenRequestLogs_LOGS_HTML:
+		in, err := srv.logReader(ctx, algo.Name)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if req.Logs == v1.ListenRequestLogs_LOGS_UNSLICED {
+			tasks = append(tasks, func(ctx context.Context) error {
+				return listenUnslicedLogs(in, send)
+			})
+			break
+		}
+		html := req.Logs == v1.ListenRequestLogs_LOGS_HTML
+		tasks = append(tasks, func(ctx context.Context) error {
+			return listenSlicedLogs(in, html, send)
+		})
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown logs mode %v", req.Logs)
+	}
+
+	errc := make(chan error, len(tasks))
+	for _, task := range tasks {
+		go func(task func(context.Context) error) {
+			errc <- task(ctx)
+		}(task)
+	}
+	for range tasks {
+		if err := <-errc; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logReader returns a reader for the log of an Algorithm. The log of a running Algorithm is read
+// from memory as it is written, the log of a finished one comes from the log store.
+func (srv *Service) logReader(ctx context.Context, name string) (io.Reader, error) {
+	live := srv.logs.Reader(ctx, name)
+
+	// the status is checked after we got hold of the live log: updateAlgorithm stores the log
+	// before it marks the Algorithm done, hence we cannot miss the log in between
+	algo, err := srv.Config.Algorithms.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if algo.Phase != v1.AlgorithmPhase_PHASE_DONE {
+		return live, nil
+	}
+	// the live log might have just been created by us
+	srv.logs.Remove(name)
+
+	content, err := srv.Config.Logs.Get(ctx, name)
+	if errors.Is(err, store.ErrNotFound) {
+		// algorithms which finished without ever producing output have no log
+		return bytes.NewReader(nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
+// listenUpdates sends the current status of the algorithm and all subsequent updates until it is done.
+// Once the listener lags behind, the updates it missed might include the last one, hence the status
+// is read again using get.
+func listenUpdates(ctx context.Context, algo *v1.AlgorithmStatus, updates <-chan *v1.AlgorithmStatus, lagged <-chan struct{}, get func(context.Context, string) (*v1.AlgorithmStatus, error), send func(*v1.ListenResponse) error) error {
+	err := send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: algo}})
+	if err != nil {
+		return err
+	}
+
+	for algo.Phase != v1.AlgorithmPhase_PHASE_DONE {
+		select {
+		case u := <-updates:
+			if u.Name != algo.Name {
+				continue
+			}
+			algo = u
+			err := send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: algo}})
+			if err != nil {
+				return err
+			}
+		case <-lagged:
+			// updates are emitted once they're stored, the buffered ones are older than the stored status
+			drain(updates)
+			u, err := get(ctx, algo.Name)
+			if err != nil {
+				return status.Errorf(codes.Internal, "cannot get algorithm %s: %v", algo.Name, err)
+			}
+			algo = u
+			err = send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: algo}})
+			if err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// drain discards all buffered updates
+func drain(updates <-chan *v1.AlgorithmStatus) {
+	for {
+		select {
+		case <-updates:
+		default:
+			return
+		}
+	}
+}
+
+// listenUnslicedLogs forwards the log output as is
+func listenUnslicedLogs(in io.Reader, send func(*v1.ListenResponse) error) error {
+	buf := make([]byte, unslicedChunkSize)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			serr := send(&v1.ListenResponse{Content: &v1.ListenResponse_Slice{Slice: &v1.LogSliceEvent{
+				Type:    v1.LogSliceType_SLICE_CONTENT,
+				Payload: string(buf[:n]),
+			}}})
+			if serr != nil {
+				return serr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// listenSlicedLogs cuts the log output into slices and forwards them, optionally converting
+// terminal escape sequences to HTML.
+func listenSlicedLogs(in io.Reader, html bool, send func(*v1.ListenResponse) error) error {
+	evts, errc := logcutter.DefaultCutter.Slice(in)
+	for evt := range evts {
+		if html && evt.Type != v1.LogSliceType_SLICE_RESULT {
+			evt.Payload = logcutter.ANSIToHTML(evt.Payload)
+		}
+		err := send(&v1.ListenResponse{Content: &v1.ListenResponse_Slice{Slice: evt}})
+		if err != nil {
+			// drain the cutter so that it can finish
+			go func() {
+				for range evts {
+				}
+			}()
+			return err
+		}
+	}
+	return <-errc
+}
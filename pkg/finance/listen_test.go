@@ -0,0 +1,310 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/store/memory"
+	"google.golang.org/grpc"
+)
+
+type fakeListenServer struct {
+	grpc.ServerStream
+
+	ctx context.Context
+	mu  sync.Mutex
+	res []*v1.ListenResponse
+}
+
+func (f *fakeListenServer) Context() context.Context { return f.ctx }
+
+func (f *fakeListenServer) Send(resp *v1.ListenResponse) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.res = append(f.res, resp)
+	return nil
+}
+
+// stalledListenServer holds every Send until release is closed, like a slow client
+type stalledListenServer struct {
+	fakeListenServer
+
+	stalled chan struct{}
+	release chan struct{}
+}
+
+func (f *stalledListenServer) Send(resp *v1.ListenResponse) error {
+	select {
+	case f.stalled <- struct{}{}:
+	default:
+	}
+	<-f.release
+	return f.fakeListenServer.Send(resp)
+}
+
+type fakeSubscribeServer struct {
+	grpc.ServerStream
+
+	ctx context.Context
+	res chan *v1.SubscribeResponse
+}
+
+func (f *fakeSubscribeServer) Context() context.Context { return f.ctx }
+
+func (f *fakeSubscribeServer) Send(resp *v1.SubscribeResponse) error {
+	f.res <- resp
+	return nil
+}
+
+func startTestAlgorithm(t *testing.T, srv *Service, owner string) *v1.AlgorithmStatus {
+	resp, err := srv.StartAlgorithm(context.Background(), &v1.StartAlgorithmRequest{
+		Metadata:      &v1.AlgorithmMetadata{Owner: owner, AlgorithmSpecName: "eod-curve"},
+		AlgorithmYaml: []byte("description: builds the EOD curve"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Status
+}
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name string
+		logs v1.ListenRequestLogs
+		want []string
+	}{
+		{
+			name: "disabled",
+			logs: v1.ListenRequestLogs_LOGS_DISABLED,
+			want: []string{"update:PHASE_PREPARING", "update:PHASE_RUNNING", "update:PHASE_DONE"},
+		},
+		{
+			name: "unsliced",
+			logs: v1.ListenRequestLogs_LOGS_UNSLICED,
+			want: []string{"slice:SLICE_CONTENT||[fetch] \x1b[1mloading\x1b[0m\n[fetch|DONE]\n"},
+		},
+		{
+			name: "raw",
+			logs: v1.ListenRequestLogs_LOGS_RAW,
+			want: []string{
+				"slice:SLICE_START|fetch|",
+				"slice:SLICE_CONTENT|fetch|\x1b[1mloading\x1b[0m",
+				"slice:SLICE_DONE|fetch|",
+			},
+		},
+		{
+			name: "html",
+			logs: v1.ListenRequestLogs_LOGS_HTML,
+			want: []string{
+				"slice:SLICE_START|fetch|",
+				`slice:SLICE_CONTENT|fetch|<span class="term-fg1">loading</span>`,
+				"slice:SLICE_DONE|fetch|",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := NewService(Config{})
+			algo := startTestAlgorithm(t, srv, "foo")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ls := &fakeListenServer{ctx: ctx}
+			errc := make(chan error, 1)
+			go func() {
+				errc <- srv.Listen(&v1.ListenRequest{
+					Name:    algo.Name,
+					Updates: test.logs == v1.ListenRequestLogs_LOGS_DISABLED,
+					Logs:    test.logs,
+				}, ls)
+			}()
+
+			// give Listen a chance to start listening before we produce updates
+			time.Sleep(50 * time.Millisecond)
+			_, _ = srv.logs.Write(algo.Name, []byte("[fetch] \x1b[1mloading\x1b[0m\n[fetch|DONE]\n"))
+			algo.Phase = v1.AlgorithmPhase_PHASE_RUNNING
+			if _, err := srv.updateAlgorithm(context.Background(), algo); err != nil {
+				t.Fatal(err)
+			}
+			algo.Phase = v1.AlgorithmPhase_PHASE_DONE
+			if _, err := srv.updateAlgorithm(context.Background(), algo); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := <-errc; err != nil {
+				t.Fatal(err)
+			}
+
+			var act []string
+			for _, r := range ls.res {
+				switch c := r.Content.(type) {
+				case *v1.ListenResponse_Update:
+					act = append(act, fmt.Sprintf("update:%s", c.Update.Phase))
+				case *v1.ListenResponse_Slice:
+					act = append(act, fmt.Sprintf("slice:%s|%s|%s", c.Slice.Type, c.Slice.Name, c.Slice.Payload))
+				}
+			}
+			if len(act) != len(test.want) {
+				t.Fatalf("unexpected responses: want %q, got %q", test.want, act)
+			}
+			for i := range act {
+				if act[i] != test.want[i] {
+					t.Errorf("response %d: want %q, got %q", i, test.want[i], act[i])
+				}
+			}
+		})
+	}
+}
+
+func TestListenDoneAlgorithm(t *testing.T) {
+	srv := NewService(Config{})
+	algo := startTestAlgorithm(t, srv, "foo")
+	_, err := srv.StopAlgorithm(context.Background(), &v1.StopAlgorithmRequest{Name: algo.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ls := &fakeListenServer{ctx: ctx}
+	err = srv.Listen(&v1.ListenRequest{Name: algo.Name, Updates: true, Logs: v1.ListenRequestLogs_LOGS_RAW}, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls.res) != 1 {
+		t.Errorf("expected a single update, got %d responses", len(ls.res))
+	}
+}
+
+func TestListenSlowClient(t *testing.T) {
+	srv := NewService(Config{})
+	algo := startTestAlgorithm(t, srv, "foo")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ls := &stalledListenServer{
+		fakeListenServer: fakeListenServer{ctx: ctx},
+		stalled:          make(chan struct{}, 1),
+		release:          make(chan struct{}),
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Listen(&v1.ListenRequest{Name: algo.Name, Updates: true}, ls)
+	}()
+
+	// while the client is stuck on the initial status the buffer fills up and the final update is dropped
+	<-ls.stalled
+	algo.Phase = v1.AlgorithmPhase_PHASE_RUNNING
+	for i := 0; i < listenerBufferSize; i++ {
+		if _, err := srv.updateAlgorithm(context.Background(), algo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	algo.Phase = v1.AlgorithmPhase_PHASE_DONE
+	if _, err := srv.updateAlgorithm(context.Background(), algo); err != nil {
+		t.Fatal(err)
+	}
+	close(ls.release)
+
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	last := ls.res[len(ls.res)-1].GetUpdate()
+	if last.GetPhase() != v1.AlgorithmPhase_PHASE_DONE {
+		t.Errorf("want the last update to be done, got %v", last)
+	}
+}
+
+func TestListenReplaysStoredLog(t *testing.T) {
+	cfg := Config{Algorithms: memory.NewAlgorithms(), Logs: memory.NewLogs()}
+	srv := NewService(cfg)
+	algo := startTestAlgorithm(t, srv, "foo")
+	_, _ = srv.logs.Write(algo.Name, []byte("[fetch] loading\n[fetch|DONE]\n"))
+	_, err := srv.StopAlgorithm(context.Background(), &v1.StopAlgorithmRequest{Name: algo.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a restarted service only has the stores to go by
+	srv = NewService(cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ls := &fakeListenServer{ctx: ctx}
+	err = srv.Listen(&v1.ListenRequest{Name: algo.Name, Logs: v1.ListenRequestLogs_LOGS_RAW}, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var act []string
+	for _, r := range ls.res {
+		slice := r.GetSlice()
+		act = append(act, fmt.Sprintf("%s %v %s", slice.Name, slice.Type, slice.Payload))
+	}
+	want := []string{
+		"fetch SLICE_START ",
+		"fetch SLICE_CONTENT loading",
+		"fetch SLICE_DONE ",
+	}
+	if fmt.Sprint(act) != fmt.Sprint(want) {
+		t.Errorf("unexpected log replay:\nwant %q\n got %q", want, act)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	srv := NewService(Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ss := &fakeSubscribeServer{ctx: ctx, res: make(chan *v1.SubscribeResponse, 10)}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Subscribe(&v1.SubscribeRequest{Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+			{Field: "metadata.owner", Value: "bar"},
+		}}}}, ss)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	startTestAlgorithm(t, srv, "foo")
+	want := startTestAlgorithm(t, srv, "bar")
+
+	select {
+	case resp := <-ss.res:
+		if resp.Result.Name != want.Name {
+			t.Errorf("unexpected update: want %s, got %s", want.Name, resp.Result.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout while waiting for update")
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(ss.res) != 0 {
+		t.Errorf("received %d unexpected updates", len(ss.res))
+	}
+}
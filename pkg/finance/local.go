@@ -0,0 +1,279 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/archive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxYAMLSize is the maximum size in bytes of an uploaded config or Algorithm YAML file
+const maxYAMLSize = 1024 * 1024
+
+// uploadState is the state of a StartLocalAlgorithm upload
+type uploadState int
+
+const (
+	expectMetadata uploadState = iota
+	expectConfig
+	readingConfig
+	readingAlgorithm
+	readingApplication
+	uploadDone
+)
+
+// uploadContent is the kind of content carried by a StartLocalAlgorithmRequest
+type uploadContent string
+
+const (
+	contentMetadata        uploadContent = "metadata"
+	contentConfig          uploadContent = "config_yaml"
+	contentAlgorithm       uploadContent = "algorithm_yaml"
+	contentApplication     uploadContent = "application_tar"
+	contentApplicationDone uploadContent = "application_tar_done"
+)
+
+// uploadTransitions lists the valid state transitions of a StartLocalAlgorithm upload
+var uploadTransitions = map[uploadState]map[uploadContent]uploadState{
+	expectMetadata: {
+		contentMetadata: expectConfig,
+	},
+	expectConfig: {
+		contentConfig: readingConfig,
+	},
+	readingConfig: {
+		contentConfig:    readingConfig,
+		contentAlgorithm: readingAlgorithm,
+	},
+	readingAlgorithm: {
+		contentAlgorithm:       readingAlgorithm,
+		contentApplication:     readingApplication,
+		contentApplicationDone: uploadDone,
+	},
+	readingApplication: {
+		contentApplication:     readingApplication,
+		contentApplicationDone: uploadDone,
+	},
+}
+
+// StartLocalAlgorithm starts an Algorithm from an application uploaded by the client.
+// The upload must arrive in the order documented in the protocol definition.
+func (srv *Service) StartLocalAlgorithm(inc v1.FinanceService_StartLocalAlgorithmServer) error {
+	if err := srv.checkWritable(); err != nil {
+		return err
+	}
+	err := os.MkdirAll(srv.Config.WorkspaceDir, 0755)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot create workspace directory: %v", err)
+	}
+	tmpdir, err := os.MkdirTemp(srv.Config.WorkspaceDir, "upload-")
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot create workspace: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	var (
+		state         = expectMetadata
+		md            *v1.AlgorithmMetadata
+		configYAML    []byte
+		algorithmYAML []byte
+		uploaded      int64
+		app           *extraction
+	)
+	defer func() {
+		if app != nil {
+			app.Abort()
+		}
+	}()
+	for state != uploadDone {
+		msg, err := inc.Recv()
+		if err == io.EOF {
+			return status.Errorf(codes.InvalidArgument, "upload ended before %s", contentApplicationDone)
+		}
+		if err != nil {
+			return err
+		}
+
+		var content uploadContent
+		switch c := msg.Content.(type) {
+		case *v1.StartLocalAlgorithmRequest_Metadata:
+			content = contentMetadata
+			md = c.Metadata
+		case *v1.StartLocalAlgorithmRequest_ConfigYaml:
+			content = contentConfig
+			configYAML = append(configYAML, c.ConfigYaml...)
+			if len(configYAML) > maxYAMLSize {
+				return status.Errorf(codes.ResourceExhausted, "%s exceeds %d bytes", content, maxYAMLSize)
+			}
+		case *v1.StartLocalAlgorithmRequest_AlgorithmYaml:
+			content = contentAlgorithm
+			algorithmYAML = append(algorithmYAML, c.AlgorithmYaml...)
+			if len(algorithmYAML) > maxYAMLSize {
+				return status.Errorf(codes.ResourceExhausted, "%s exceeds %d bytes", content, maxYAMLSize)
+			}
+		case *v1.StartLocalAlgorithmRequest_ApplicationTar:
+			content = contentApplication
+		case *v1.StartLocalAlgorithmRequest_ApplicationTarDone:
+			content = contentApplicationDone
+		default:
+			return status.Error(codes.InvalidArgument, "request has no content")
+		}
+
+		next, ok := uploadTransitions[state][content]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "unexpected %s in upload", content)
+		}
+		state = next
+
+		if content == contentApplication {
+			chunk := msg.GetApplicationTar()
+			uploaded += int64(len(chunk))
+			if srv.Config.MaxUploadSize > 0 && uploaded > srv.Config.MaxUploadSize {
+				return status.Errorf(codes.ResourceExhausted, "application exceeds %d bytes", srv.Config.MaxUploadSize)
+			}
+			if app == nil {
+				app = startExtraction(tmpdir, srv.Config.MaxUploadSize)
+			}
+			if err := app.Write(chunk); err != nil {
+				return extractionError(err)
+			}
+		}
+	}
+	if _, err := inc.Recv(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return status.Errorf(codes.InvalidArgument, "unexpected content after %s", contentApplicationDone)
+	}
+	if app != nil {
+		err := app.Finish()
+		app = nil
+		if err != nil {
+			return extractionError(err)
+		}
+	}
+
+	cfg, err := ParseRepoConfig(configYAML)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	algo, err := srv.startAlgorithm(inc.Context(), algorithmStart{
+		Metadata:      md,
+		AlgorithmPath: cfg.DefaultAlgorithm,
+		AlgorithmYAML: algorithmYAML,
+		PrepareWorkspace: func(dst string) error {
+			return os.Rename(tmpdir, dst)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return inc.SendAndClose(&v1.StartAlgorithmResponse{Status: algo})
+}
+
+// workspacePath returns the path of the workspace of an Algorithm
+func (srv *Service) workspacePath(name string) string {
+	return filepath.Join(srv.Config.WorkspaceDir, name)
+}
+
+// copyWorkspace copies the content of the src workspace to dst
+func copyWorkspace(src, dst string) error {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(archive.Create(w, src, nil))
+	}()
+	err := archive.Extract(r, dst, 0)
+	r.CloseWithError(err)
+	return err
+}
+
+// isDir returns true if path exists and is a directory
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
+}
+
+// extraction unpacks an application tar stream while it's being uploaded
+type extraction struct {
+	w    *io.PipeWriter
+	done chan struct{}
+	err  error
+}
+
+func startExtraction(dst string, maxSize int64) *extraction {
+	r, w := io.Pipe()
+	res := &extraction{w: w, done: make(chan struct{})}
+	go func() {
+		err := archive.Extract(r, dst, maxSize)
+		if err == nil {
+			// consume any trailing padding so that the uploader does not block
+			_, err = io.Copy(io.Discard, r)
+		}
+		r.CloseWithError(err)
+		res.err = err
+		close(res.done)
+	}()
+	return res
+}
+
+// Write forwards a chunk of the tar stream to the extraction
+func (e *extraction) Write(p []byte) error {
+	_, err := e.w.Write(p)
+	if err != nil {
+		// the extraction has failed - its error is more meaningful than the pipe's
+		<-e.done
+		return e.err
+	}
+	return nil
+}
+
+// Finish marks the end of the tar stream and waits for the extraction to complete
+func (e *extraction) Finish() error {
+	e.w.Close()
+	<-e.done
+	return e.err
+}
+
+// Abort stops the extraction and waits for it to finish
+func (e *extraction) Abort() {
+	e.w.CloseWithError(fmt.Errorf("upload aborted"))
+	<-e.done
+}
+
+func extractionError(err error) error {
+	switch {
+	case errors.Is(err, archive.ErrTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, archive.ErrIllegalPath):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.InvalidArgument, "cannot extract application: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStartLocalServer struct {
+	grpc.ServerStream
+
+	reqs []*v1.StartLocalAlgorithmRequest
+	resp *v1.StartAlgorithmResponse
+}
+
+func (f *fakeStartLocalServer) Context() context.Context { return context.Background() }
+
+func (f *fakeStartLocalServer) Recv() (*v1.StartLocalAlgorithmRequest, error) {
+	if len(f.reqs) == 0 {
+		return nil, io.EOF
+	}
+	res := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	return res, nil
+}
+
+func (f *fakeStartLocalServer) SendAndClose(resp *v1.StartAlgorithmResponse) error {
+	f.resp = resp
+	return nil
+}
+
+func buildApplication(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var (
+	reqMetadata = &v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_Metadata{
+		Metadata: &v1.AlgorithmMetadata{Owner: "foo", Trigger: v1.AlgorithmTrigger_TRIGGER_MANUAL},
+	}}
+	reqConfig    = &v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_ConfigYaml{ConfigYaml: []byte("defaultAlgorithm: finance/eod-curve.yaml")}}
+	reqAlgorithm = &v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_AlgorithmYaml{AlgorithmYaml: []byte("description: builds the EOD curve")}}
+	reqDone      = &v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_ApplicationTarDone{ApplicationTarDone: true}}
+)
+
+func reqTar(p []byte) *v1.StartLocalAlgorithmRequest {
+	return &v1.StartLocalAlgorithmRequest{Content: &v1.StartLocalAlgorithmRequest_ApplicationTar{ApplicationTar: p}}
+}
+
+func TestStartLocalAlgorithm(t *testing.T) {
+	app := buildApplication(t, map[string]string{"data/quotes.csv": "1,2,3"})
+	evil := buildApplication(t, map[string]string{"../evil": "foo"})
+
+	tests := []struct {
+		name          string
+		reqs          []*v1.StartLocalAlgorithmRequest
+		maxUploadSize int64
+		wantCode      codes.Code
+		wantFiles     map[string]string
+	}{
+		{
+			name:      "happy path",
+			reqs:      []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqTar(app[:10]), reqTar(app[10:]), reqDone},
+			wantFiles: map[string]string{"data/quotes.csv": "1,2,3"},
+		},
+		{
+			name: "no application",
+			reqs: []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqDone},
+		},
+		{
+			name:     "missing metadata",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqConfig, reqAlgorithm, reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "algorithm before config",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqAlgorithm, reqConfig, reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "config after algorithm",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqConfig, reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "application before algorithm",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqTar(app), reqAlgorithm, reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing done marker",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqTar(app)},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "content after done marker",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqDone, reqTar(app)},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "empty request",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, {}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:          "application too large",
+			reqs:          []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqTar(app), reqDone},
+			maxUploadSize: 10,
+			wantCode:      codes.ResourceExhausted,
+		},
+		{
+			name:     "path traversal",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqTar(evil), reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "corrupt application",
+			reqs:     []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, reqAlgorithm, reqTar([]byte("not a tar")), reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid algorithm YAML",
+			reqs: []*v1.StartLocalAlgorithmRequest{reqMetadata, reqConfig, {Content: &v1.StartLocalAlgorithmRequest_AlgorithmYaml{
+				AlgorithmYaml: []byte("description: [foo"),
+			}}, reqDone},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workspaces := t.TempDir()
+			srv := NewService(Config{WorkspaceDir: workspaces, MaxUploadSize: test.maxUploadSize})
+			inc := &fakeStartLocalServer{reqs: test.reqs}
+
+			err := srv.StartLocalAlgorithm(inc)
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected status code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+
+			entries, rerr := os.ReadDir(workspaces)
+			if rerr != nil {
+				t.Fatal(rerr)
+			}
+			if err != nil {
+				if len(entries) != 0 {
+					t.Errorf("failed upload left %d entries in the workspace directory", len(entries))
+				}
+				return
+			}
+
+			if inc.resp == nil {
+				t.Fatal("no response was sent")
+			}
+			name := inc.resp.Status.Name
+			if name != "eod-curve.1" {
+				t.Errorf("unexpected algorithm name: want eod-curve.1, got %s", name)
+			}
+			if len(entries) != 1 || entries[0].Name() != name {
+				t.Errorf("workspace directory does not contain just the algorithm's workspace: %v", entries)
+			}
+			for fn, content := range test.wantFiles {
+				act, err := os.ReadFile(filepath.Join(workspaces, name, fn))
+				if err != nil {
+					t.Errorf("cannot read %s: %v", fn, err)
+					continue
+				}
+				if string(act) != content {
+					t.Errorf("unexpected content of %s: want %q, got %q", fn, content, string(act))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// logStore keeps the log output of Algorithms in memory
+type logStore struct {
+	mu   sync.Mutex
+	logs map[string]*logBuffer
+}
+
+func newLogStore() *logStore {
+	return &logStore{logs: make(map[string]*logBuffer)}
+}
+
+func (s *logStore) get(name string) *logBuffer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, ok := s.logs[name]
+	if !ok {
+		buf = &logBuffer{notify: make(chan struct{})}
+		s.logs[name] = buf
+	}
+	return buf
+}
+
+// Write appends log output of an Algorithm
+func (s *logStore) Write(name string, p []byte) (int, error) {
+	return s.get(name).Write(p)
+}
+
+// Writer returns a writer which appends to the log of an Algorithm
+func (s *logStore) Writer(name string) io.Writer {
+	return s.get(name)
+}
+
+// Close marks the log of an Algorithm as complete and returns its content. Readers will
+// receive io.EOF once they have read all content.
+func (s *logStore) Close(name string) []byte {
+	return s.get(name).Close()
+}
+
+// Remove forgets the log of an Algorithm. Readers which exist already can still read
+// the log until its end.
+func (s *logStore) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.logs, name)
+}
+
+// Reader returns a reader which produces the complete log of an Algorithm and blocks
+// for more output until the log is closed or the context is canceled.
+func (s *logStore) Reader(ctx context.Context, name string) io.Reader {
+	return &logReader{ctx: ctx, buf: s.get(name)}
+}
+
+// logBuffer is an append-only buffer which notifies readers of new content
+type logBuffer struct {
+	mu     sync.Mutex
+	data   []byte
+	closed bool
+	notify chan struct{}
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	b.data = append(b.data, p...)
+	close(b.notify)
+	b.notify = make(chan struct{})
+	return len(p), nil
+}
+
+func (b *logBuffer) Close() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		close(b.notify)
+	}
+	return b.data
+}
+
+type logReader struct {
+	ctx context.Context
+	buf *logBuffer
+	off int
+}
+
+func (r *logReader) Read(p []byte) (int, error) {
+	for {
+		r.buf.mu.Lock()
+		if r.off < len(r.buf.data) {
+			n := copy(p, r.buf.data[r.off:])
+			r.off += n
+			r.buf.mu.Unlock()
+			return n, nil
+		}
+		if r.buf.closed {
+			r.buf.mu.Unlock()
+			return 0, io.EOF
+		}
+		notify := r.buf.notify
+		r.buf.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+}
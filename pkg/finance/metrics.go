@@ -0,0 +1,107 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectTimeout bounds the store queries of a single metrics scrape
+const collectTimeout = 10 * time.Second
+
+var algorithmsDesc = prometheus.NewDesc("finance_algorithms", "Number of algorithms by phase.", []string{"phase"}, nil)
+
+// serviceMetrics count what the service does. They're exported by registering the service with a Prometheus registry.
+type serviceMetrics struct {
+	started  *prometheus.CounterVec
+	stopped  prometheus.Counter
+	duration *prometheus.HistogramVec
+}
+
+func newServiceMetrics() *serviceMetrics {
+	return &serviceMetrics{
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "finance",
+			Name:      "algorithms_started_total",
+			Help:      "Number of algorithms started by trigger.",
+		}, []string{"trigger"}),
+		stopped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "finance",
+			Name:      "algorithms_stopped_total",
+			Help:      "Number of algorithms stopped on request.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "finance",
+			Name:      "executor_duration_seconds",
+			Help:      "Time algorithms spent in the executor, from being handed over until done.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"success"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (srv *Service) Describe(ch chan<- *prometheus.Desc) {
+	ch <- algorithmsDesc
+	srv.metrics.started.Describe(ch)
+	srv.metrics.stopped.Describe(ch)
+	srv.metrics.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector. The number of algorithms by phase is read from the store.
+func (srv *Service) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	phases := make([]int, 0, len(v1.AlgorithmPhase_name))
+	for p := range v1.AlgorithmPhase_name {
+		if p != int32(v1.AlgorithmPhase_PHASE_UNKNOWN) {
+			phases = append(phases, int(p))
+		}
+	}
+	sort.Ints(phases)
+	for _, p := range phases {
+		phase := query.PhaseString(v1.AlgorithmPhase(p))
+		_, total, err := srv.Config.Algorithms.Find(ctx, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+			{Field: "phase", Value: phase},
+		}}}, nil, 0, 1)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(algorithmsDesc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(algorithmsDesc, prometheus.GaugeValue, float64(total), phase)
+	}
+
+	srv.metrics.started.Collect(ch)
+	srv.metrics.stopped.Collect(ch)
+	srv.metrics.duration.Collect(ch)
+}
+
+// observeExecution records how long an Algorithm spent in the executor
+func (m *serviceMetrics) observeExecution(start time.Time, success bool) {
+	m.duration.WithLabelValues(strconv.FormatBool(success)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,68 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	exec := &fakeExecutor{}
+	srv := NewService(Config{WorkspaceDir: t.TempDir(), Executor: exec})
+	stuck := startTestAlgorithm(t, srv, "foo")
+	exec.job(t, stuck.Name).OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+	stopped := startTestAlgorithm(t, srv, "foo")
+	startTestAlgorithm(t, srv, "foo")
+	_, err := srv.StopAlgorithm(context.Background(), &v1.StopAlgorithmRequest{Name: stopped.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP finance_algorithms Number of algorithms by phase.
+# TYPE finance_algorithms gauge
+finance_algorithms{phase="cleanup"} 0
+finance_algorithms{phase="done"} 1
+finance_algorithms{phase="preparing"} 1
+finance_algorithms{phase="running"} 0
+finance_algorithms{phase="starting"} 1
+finance_algorithms{phase="waiting"} 0
+# HELP finance_algorithms_started_total Number of algorithms started by trigger.
+# TYPE finance_algorithms_started_total counter
+finance_algorithms_started_total{trigger="unknown"} 3
+# HELP finance_algorithms_stopped_total Number of algorithms stopped on request.
+# TYPE finance_algorithms_stopped_total counter
+finance_algorithms_stopped_total 1
+`
+	err = testutil.CollectAndCompare(srv, strings.NewReader(want), "finance_algorithms", "finance_algorithms_started_total", "finance_algorithms_stopped_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(srv, "finance_executor_duration_seconds"); n != 1 {
+		t.Errorf("expected the duration of the stopped algorithm only, got %d series", n)
+	}
+}
@@ -0,0 +1,198 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// truncatedLogNote is the first line of a truncated log
+const truncatedLogNote = "... %d bytes of log output removed ...\n"
+
+// RetentionPolicy bounds how many finished Algorithms and how much of their log output
+// are kept. The zero value keeps everything.
+type RetentionPolicy struct {
+	// KeepPerSpec is the number of finished Algorithms kept per spec name. Zero keeps all of them.
+	KeepPerSpec int
+
+	// MaxAge is how long finished Algorithms are kept for. Zero keeps them forever.
+	MaxAge time.Duration
+
+	// MaxLogSize is the size in bytes the log of a finished Algorithm is cut down to, keeping
+	// its end. Zero keeps complete logs.
+	MaxLogSize int64
+}
+
+// PruneAlgorithms applies the retention policy right away
+func (srv *Service) PruneAlgorithms(ctx context.Context, req *v1.PruneAlgorithmsRequest) (*v1.PruneAlgorithmsResponse, error) {
+	if id, ok := auth.FromContext(ctx); ok && !id.Admin {
+		return nil, status.Error(codes.PermissionDenied, "only admins can prune algorithms")
+	}
+	if !req.DryRun {
+		if err := srv.checkWritable(); err != nil {
+			return nil, err
+		}
+	}
+	res, err := srv.Prune(ctx, req.DryRun)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return res, nil
+}
+
+// Prune deletes the finished Algorithms the retention policy no longer keeps, together with
+// their spec, log and workspace, and truncates the logs of all others which are too long.
+// With dryRun Prune only reports what it would do.
+func (srv *Service) Prune(ctx context.Context, dryRun bool) (*v1.PruneAlgorithmsResponse, error) {
+	policy := srv.Config.Retention
+	res := &v1.PruneAlgorithmsResponse{}
+	if policy == (RetentionPolicy{}) {
+		return res, nil
+	}
+
+	done, _, err := srv.Config.Algorithms.Find(ctx, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+		{Field: "phase", Value: query.PhaseString(v1.AlgorithmPhase_PHASE_DONE)},
+	}}}, nil, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find finished algorithms: %w", err)
+	}
+	// newest first, so that the runs kept per spec are the most recent ones
+	sort.SliceStable(done, func(i, j int) bool { return finishedAt(done[i]).After(finishedAt(done[j])) })
+
+	var (
+		cutoff = time.Now().Add(-policy.MaxAge)
+		kept   = make(map[string]int)
+	)
+	for _, algo := range done {
+		spec := algo.GetMetadata().GetAlgorithmSpecName()
+		kept[spec]++
+		expired := policy.MaxAge > 0 && finishedAt(algo).Before(cutoff)
+		if expired || (policy.KeepPerSpec > 0 && kept[spec] > policy.KeepPerSpec) {
+			kept[spec]--
+			res.Deleted = append(res.Deleted, algo.Name)
+			if dryRun {
+				continue
+			}
+			if err := srv.deleteAlgorithm(ctx, algo.Name); err != nil {
+				return res, err
+			}
+			continue
+		}
+
+		if policy.MaxLogSize <= 0 {
+			continue
+		}
+		size, err := srv.Config.Logs.Size(ctx, algo.Name)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return res, fmt.Errorf("cannot get log size of %s: %w", algo.Name, err)
+		}
+		if size <= policy.MaxLogSize {
+			continue
+		}
+		res.Truncated = append(res.Truncated, algo.Name)
+		if dryRun {
+			continue
+		}
+		content, err := srv.Config.Logs.Get(ctx, algo.Name)
+		if err != nil {
+			return res, fmt.Errorf("cannot get log of %s: %w", algo.Name, err)
+		}
+		err = srv.Config.Logs.Store(ctx, algo.Name, truncateLog(content, policy.MaxLogSize))
+		if err != nil {
+			return res, fmt.Errorf("cannot truncate log of %s: %w", algo.Name, err)
+		}
+	}
+
+	if len(res.Deleted) > 0 || len(res.Truncated) > 0 {
+		log.WithField("deleted", len(res.Deleted)).WithField("truncated", len(res.Truncated)).WithField("dryRun", dryRun).Info("pruned algorithms")
+	}
+	return res, nil
+}
+
+// deleteAlgorithm removes a finished Algorithm. The status goes last so that an Algorithm
+// which cannot be deleted completely is pruned again.
+func (srv *Service) deleteAlgorithm(ctx context.Context, name string) error {
+	err := srv.Config.Logs.Delete(ctx, name)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("cannot delete log of %s: %w", name, err)
+	}
+	err = os.RemoveAll(srv.workspacePath(name))
+	if err != nil {
+		return fmt.Errorf("cannot delete workspace of %s: %w", name, err)
+	}
+	err = srv.Config.Algorithms.Delete(ctx, name)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("cannot delete algorithm %s: %w", name, err)
+	}
+	return nil
+}
+
+// finishedAt returns when an Algorithm finished, or was created if it never recorded its end
+func finishedAt(algo *v1.AlgorithmStatus) time.Time {
+	md := algo.GetMetadata()
+	if md.GetFinished() != nil {
+		return md.Finished.AsTime()
+	}
+	return md.GetCreated().AsTime()
+}
+
+// truncateLog keeps the end of a log which is longer than max bytes. The log is cut at a line
+// boundary and starts with a line which says how much was removed, if there's room for it.
+func truncateLog(content []byte, max int64) []byte {
+	if max <= 0 || int64(len(content)) <= max {
+		return content
+	}
+	// the note can only get shorter than this as it counts fewer bytes than the whole log
+	note := fmt.Sprintf(truncatedLogNote, len(content))
+	room := max
+	if int64(len(note)) < max {
+		room -= int64(len(note))
+	}
+	cut := int64(len(content)) - room
+	tail := content[cut:]
+	if content[cut-1] != '\n' {
+		if idx := bytes.IndexByte(tail, '\n'); idx >= 0 {
+			tail = tail[idx+1:]
+		}
+	}
+	if room == max {
+		return append([]byte(nil), tail...)
+	}
+	note = fmt.Sprintf(truncatedLogNote, len(content)-len(tail))
+	return append([]byte(note), tail...)
+}
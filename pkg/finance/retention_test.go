@@ -0,0 +1,218 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"github.com/bhojpur/finance/pkg/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newPruneService returns a service which knows finished Algorithms of two specs, each with a log
+// and a workspace, and a running one which must never be pruned
+func newPruneService(t *testing.T, policy RetentionPolicy) *Service {
+	srv := NewService(Config{WorkspaceDir: t.TempDir(), Retention: policy})
+	ctx := context.Background()
+	now := time.Now()
+	algos := []struct {
+		name  string
+		phase v1.AlgorithmPhase
+		age   time.Duration
+		log   string
+	}{
+		{"eod-curve.1", v1.AlgorithmPhase_PHASE_DONE, 72 * time.Hour, "[build] one\n[build] two\n[build] three\n"},
+		{"eod-curve.2", v1.AlgorithmPhase_PHASE_DONE, 48 * time.Hour, "short\n"},
+		{"eod-curve.3", v1.AlgorithmPhase_PHASE_DONE, time.Hour, "short\n"},
+		{"revaluation.1", v1.AlgorithmPhase_PHASE_DONE, 96 * time.Hour, "[build] one\n[build] two\n[build] three\n"},
+		{"revaluation.2", v1.AlgorithmPhase_PHASE_RUNNING, 120 * time.Hour, ""},
+	}
+	for _, a := range algos {
+		md := &v1.AlgorithmMetadata{AlgorithmSpecName: namePrefix(a.name), Created: timestamppb.New(now.Add(-a.age - time.Minute))}
+		if a.phase == v1.AlgorithmPhase_PHASE_DONE {
+			md.Finished = timestamppb.New(now.Add(-a.age))
+		}
+		if err := srv.Config.Algorithms.Store(ctx, &v1.AlgorithmStatus{Name: a.name, Phase: a.phase, Metadata: md}); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.Config.Algorithms.StoreSpec(ctx, a.name, []byte("description: test")); err != nil {
+			t.Fatal(err)
+		}
+		if a.log != "" {
+			if err := srv.Config.Logs.Store(ctx, a.name, []byte(a.log)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.MkdirAll(srv.workspacePath(a.name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        RetentionPolicy
+		wantDeleted   []string
+		wantTruncated []string
+	}{
+		{name: "no policy"},
+		{
+			name:        "keep per spec",
+			policy:      RetentionPolicy{KeepPerSpec: 2},
+			wantDeleted: []string{"eod-curve.1"},
+		},
+		{
+			name:        "max age",
+			policy:      RetentionPolicy{MaxAge: 50 * time.Hour},
+			wantDeleted: []string{"eod-curve.1", "revaluation.1"},
+		},
+		{
+			name:        "keep per spec and max age",
+			policy:      RetentionPolicy{KeepPerSpec: 1, MaxAge: 90 * time.Hour},
+			wantDeleted: []string{"eod-curve.2", "eod-curve.1", "revaluation.1"},
+		},
+		{
+			name:          "max log size",
+			policy:        RetentionPolicy{MaxLogSize: 10},
+			wantTruncated: []string{"eod-curve.1", "revaluation.1"},
+		},
+		{
+			name:          "deleted logs are not truncated",
+			policy:        RetentionPolicy{KeepPerSpec: 2, MaxLogSize: 10},
+			wantDeleted:   []string{"eod-curve.1"},
+			wantTruncated: []string{"revaluation.1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, dryRun := range []bool{true, false} {
+				srv := newPruneService(t, test.policy)
+				ctx := context.Background()
+
+				res, err := srv.PruneAlgorithms(ctx, &v1.PruneAlgorithmsRequest{DryRun: dryRun})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Join(res.Deleted, ",") != strings.Join(test.wantDeleted, ",") {
+					t.Errorf("dry run %v: unexpected deleted algorithms: want %v, got %v", dryRun, test.wantDeleted, res.Deleted)
+				}
+				if strings.Join(res.Truncated, ",") != strings.Join(test.wantTruncated, ",") {
+					t.Errorf("dry run %v: unexpected truncated logs: want %v, got %v", dryRun, test.wantTruncated, res.Truncated)
+				}
+
+				deleted := make(map[string]bool)
+				for _, name := range test.wantDeleted {
+					deleted[name] = !dryRun
+				}
+				for _, name := range []string{"eod-curve.1", "eod-curve.2", "eod-curve.3", "revaluation.1", "revaluation.2"} {
+					_, err := srv.Config.Algorithms.Get(ctx, name)
+					if gone := errors.Is(err, store.ErrNotFound); gone != deleted[name] {
+						t.Errorf("dry run %v: algorithm %s: expected deleted=%v, got %v", dryRun, name, deleted[name], err)
+					}
+					_, err = srv.Config.Algorithms.GetSpec(ctx, name)
+					if gone := errors.Is(err, store.ErrNotFound); gone != deleted[name] {
+						t.Errorf("dry run %v: spec of %s: expected deleted=%v, got %v", dryRun, name, deleted[name], err)
+					}
+					if gone := !isDir(srv.workspacePath(name)); gone != deleted[name] {
+						t.Errorf("dry run %v: workspace of %s: expected deleted=%v", dryRun, name, deleted[name])
+					}
+				}
+				for _, name := range test.wantTruncated {
+					size, err := srv.Config.Logs.Size(ctx, name)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if truncated := size <= test.policy.MaxLogSize; truncated == dryRun {
+						t.Errorf("dry run %v: log of %s has %d bytes", dryRun, name, size)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPruneAlgorithmsPermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		readOnly bool
+		dryRun   bool
+		wantCode codes.Code
+	}{
+		{name: "unauthenticated", ctx: context.Background(), wantCode: codes.OK},
+		{name: "admin", ctx: auth.NewContext(context.Background(), auth.Identity{Name: "carol", Admin: true}), wantCode: codes.OK},
+		{name: "not an admin", ctx: auth.NewContext(context.Background(), auth.Identity{Name: "alice"}), dryRun: true, wantCode: codes.PermissionDenied},
+		{name: "read-only", ctx: context.Background(), readOnly: true, wantCode: codes.PermissionDenied},
+		{name: "read-only dry run", ctx: context.Background(), readOnly: true, dryRun: true, wantCode: codes.OK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newPruneService(t, RetentionPolicy{KeepPerSpec: 1})
+			srv.Config.ReadOnly = test.readOnly
+
+			_, err := srv.PruneAlgorithms(test.ctx, &v1.PruneAlgorithmsRequest{DryRun: test.dryRun})
+			if code := status.Code(err); code != test.wantCode {
+				t.Errorf("unexpected code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+		})
+	}
+}
+
+func TestTruncateLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		max     int64
+		want    string
+	}{
+		{name: "no limit", content: "one\ntwo\n", want: "one\ntwo\n"},
+		{name: "short enough", content: "one\ntwo\n", max: 8, want: "one\ntwo\n"},
+		{name: "without room for the note", content: "one\ntwo\nthree\n", max: 8, want: "three\n"},
+		{
+			name:    "with note",
+			content: strings.Repeat("[build] some output\n", 10),
+			max:     100,
+			want:    "... 140 bytes of log output removed ...\n" + strings.Repeat("[build] some output\n", 3),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			act := string(truncateLog([]byte(test.content), test.max))
+			if act != test.want {
+				t.Errorf("unexpected log: want %q, got %q", test.want, act)
+			}
+			if test.max > 0 && int64(len(act)) > test.max {
+				t.Errorf("truncated log has %d bytes, more than %d", len(act), test.max)
+			}
+		})
+	}
+}
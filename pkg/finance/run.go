@@ -0,0 +1,187 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+	"github.com/bhojpur/finance/pkg/logcutter"
+	log "github.com/sirupsen/logrus"
+)
+
+// runAlgorithm hands an Algorithm which is done preparing over to the executor
+func (srv *Service) runAlgorithm(ctx context.Context, algo *v1.AlgorithmStatus) {
+	if srv.Config.Executor == nil {
+		return
+	}
+
+	fail := func(details string, err error) {
+		log.WithError(err).WithField("name", algo.Name).Error(details)
+		algo.Phase = v1.AlgorithmPhase_PHASE_DONE
+		algo.Details = details
+		if _, err := srv.updateAlgorithm(ctx, algo); err != nil {
+			log.WithError(err).WithField("name", algo.Name).Error("cannot update algorithm")
+		}
+	}
+	content, err := srv.Config.Algorithms.GetSpec(ctx, algo.Name)
+	if err != nil {
+		fail("cannot read algorithm spec", err)
+		return
+	}
+	spec, err := ParseAlgorithmSpec(content)
+	if err != nil {
+		fail("invalid algorithm spec", err)
+		return
+	}
+	workspace := srv.workspacePath(algo.Name)
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		fail("cannot create workspace", err)
+		return
+	}
+
+	var (
+		name    = algo.Name
+		results = newResultCollector()
+		start   = time.Now()
+	)
+	err = srv.Config.Executor.Start(executor.Job{
+		Name:        name,
+		Spec:        spec.Spec,
+		Workspace:   workspace,
+		Annotations: algo.GetMetadata().GetAnnotations(),
+		Log:         io.MultiWriter(srv.logs.Writer(name), results),
+		OnUpdate: func(u executor.Update) {
+			srv.applyUpdate(name, u, results)
+			if u.Phase == v1.AlgorithmPhase_PHASE_DONE {
+				srv.metrics.observeExecution(start, u.Success)
+			}
+		},
+	})
+	if err != nil {
+		results.Close()
+		fail("cannot start algorithm", err)
+	}
+}
+
+// applyUpdate records the progress an executor reports for an Algorithm
+func (srv *Service) applyUpdate(name string, u executor.Update, results *resultCollector) {
+	done := u.Phase == v1.AlgorithmPhase_PHASE_DONE
+	var res []*v1.AlgorithmResult
+	if done {
+		res = results.Close()
+	}
+
+	ctx := context.Background()
+	algo, err := srv.Config.Algorithms.Get(ctx, name)
+	if err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot update algorithm")
+		return
+	}
+	if algo.Phase == v1.AlgorithmPhase_PHASE_DONE {
+		return
+	}
+
+	algo.Phase = u.Phase
+	algo.Details = u.Details
+	if algo.Conditions == nil {
+		algo.Conditions = &v1.AlgorithmConditions{}
+	}
+	algo.Conditions.FailureCount = u.FailureCount
+	algo.Conditions.DidExecute = algo.Conditions.DidExecute || u.DidExecute
+	if done {
+		algo.Conditions.Success = u.Success
+		algo.Results = res
+	}
+	if _, err := srv.updateAlgorithm(ctx, algo); err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot update algorithm")
+		return
+	}
+	if done {
+		log.WithField("name", name).WithField("success", u.Success).WithField("details", u.Details).Info("algorithm done")
+	}
+}
+
+// resultCollector picks the results an Algorithm publishes out of its log
+type resultCollector struct {
+	w       *io.PipeWriter
+	done    chan struct{}
+	results []*v1.AlgorithmResult
+}
+
+func newResultCollector() *resultCollector {
+	r, w := io.Pipe()
+	c := &resultCollector{w: w, done: make(chan struct{})}
+	go func() {
+		defer close(c.done)
+
+		evts, errc := logcutter.DefaultCutter.Slice(r)
+		for evt := range evts {
+			if evt.Type == v1.LogSliceType_SLICE_RESULT {
+				c.results = append(c.results, parseResult(evt.Name, evt.Payload))
+			}
+		}
+		if err := <-errc; err != nil {
+			log.WithError(err).Warn("cannot collect algorithm results")
+			// the log writer must not block, even if we can't make sense of it
+			_, _ = io.Copy(io.Discard, r)
+		}
+	}()
+	return c
+}
+
+func (c *resultCollector) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
+
+// Close waits until all log output has been processed and returns the results found
+func (c *resultCollector) Close() []*v1.AlgorithmResult {
+	c.w.Close()
+	<-c.done
+	return c.results
+}
+
+// parseResult interprets the payload of a result marker. The payload is either plain text,
+// or a JSON object such as {"payload": "...", "description": "...", "channels": ["slack"]}.
+func parseResult(tpe, payload string) *v1.AlgorithmResult {
+	res := &v1.AlgorithmResult{Type: tpe, Payload: payload}
+	if !strings.HasPrefix(strings.TrimSpace(payload), "{") {
+		return res
+	}
+	var obj struct {
+		Payload     string   `json:"payload"`
+		Description string   `json:"description"`
+		Channels    []string `json:"channels"`
+	}
+	if err := json.Unmarshal([]byte(payload), &obj); err != nil || obj.Payload == "" {
+		return res
+	}
+	res.Payload = obj.Payload
+	res.Description = obj.Description
+	res.Channels = obj.Channels
+	return res
+}
@@ -0,0 +1,158 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+)
+
+// fakeExecutor records the jobs it is asked to run and leaves running them to the test
+type fakeExecutor struct {
+	mu   sync.Mutex
+	jobs map[string]executor.Job
+}
+
+func (e *fakeExecutor) Start(job executor.Job) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.jobs == nil {
+		e.jobs = make(map[string]executor.Job)
+	}
+	e.jobs[job.Name] = job
+	return nil
+}
+
+func (e *fakeExecutor) Stop(name, reason string) error {
+	e.mu.Lock()
+	job, ok := e.jobs[name]
+	delete(e.jobs, name)
+	e.mu.Unlock()
+	if !ok {
+		return executor.ErrNotRunning
+	}
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_DONE, Details: reason})
+	return nil
+}
+
+func (e *fakeExecutor) job(t *testing.T, name string) executor.Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job, ok := e.jobs[name]
+	if !ok {
+		t.Fatalf("algorithm %s was not started", name)
+	}
+	return job
+}
+
+func TestRunAlgorithm(t *testing.T) {
+	exec := &fakeExecutor{}
+	srv := NewService(Config{WorkspaceDir: t.TempDir(), Executor: exec})
+	ctx := context.Background()
+	algo := startTestAlgorithm(t, srv, "foo")
+
+	job := exec.job(t, algo.Name)
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_STARTING})
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING, DidExecute: true})
+	fmt.Fprintln(job.Log, "[fetch] loading")
+	fmt.Fprintln(job.Log, "[curve|RESULT] eod-curve-2021-06-30.csv")
+	fmt.Fprintln(job.Log, `[report|RESULT] {"payload": "https://reports/1", "description": "EOD report", "channels": ["slack", "email"]}`)
+	fmt.Fprintln(job.Log, "[fetch|FAIL] no quotes")
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_CLEANUP, FailureCount: 1, DidExecute: true})
+	job.OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_DONE, Success: true, FailureCount: 1, DidExecute: true})
+
+	res, err := srv.Config.Algorithms.Get(ctx, algo.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Phase != v1.AlgorithmPhase_PHASE_DONE {
+		t.Errorf("unexpected phase: %v", res.Phase)
+	}
+	if c := res.Conditions; !c.Success || c.FailureCount != 1 || !c.DidExecute || !c.CanReplay {
+		t.Errorf("unexpected conditions: %v", c)
+	}
+	if res.Metadata.Finished == nil {
+		t.Error("finished time was not set")
+	}
+	want := []string{
+		"curve|eod-curve-2021-06-30.csv||[]",
+		"report|https://reports/1|EOD report|[slack email]",
+	}
+	var act []string
+	for _, r := range res.Results {
+		act = append(act, fmt.Sprintf("%s|%s|%s|%v", r.Type, r.Payload, r.Description, r.Channels))
+	}
+	if fmt.Sprint(act) != fmt.Sprint(want) {
+		t.Errorf("unexpected results:\nwant %q\n got %q", want, act)
+	}
+
+	logs, err := srv.Config.Logs.Get(ctx, algo.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) == 0 {
+		t.Error("log was not stored")
+	}
+}
+
+func TestStopRunningAlgorithm(t *testing.T) {
+	exec := &fakeExecutor{}
+	srv := NewService(Config{WorkspaceDir: t.TempDir(), Executor: exec})
+	ctx := context.Background()
+	algo := startTestAlgorithm(t, srv, "foo")
+	exec.job(t, algo.Name).OnUpdate(executor.Update{Phase: v1.AlgorithmPhase_PHASE_RUNNING})
+
+	_, err := srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: algo.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := srv.Config.Algorithms.Get(ctx, algo.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Phase != v1.AlgorithmPhase_PHASE_DONE || res.Details != "stopped" || res.Conditions.Success {
+		t.Errorf("unexpected status of stopped algorithm: %v", res)
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{payload: "plain text", want: "plain text||[]"},
+		{payload: `{"payload": "p", "description": "d", "channels": ["c"]}`, want: "p|d|[c]"},
+		{payload: `{"description": "no payload"}`, want: `{"description": "no payload"}||[]`},
+		{payload: `{not json`, want: "{not json||[]"},
+	}
+	for _, test := range tests {
+		r := parseResult("t", test.payload)
+		act := fmt.Sprintf("%s|%s|%v", r.Payload, r.Description, r.Channels)
+		if act != test.want {
+			t.Errorf("parseResult(%q): want %q, got %q", test.payload, test.want, act)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"github.com/bhojpur/finance/pkg/cron"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ScheduleAnnotation is the annotation which links an Algorithm to the schedule that started it
+const ScheduleAnnotation = "schedule"
+
+var scheduleNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// CreateSchedule adds a schedule which starts an Algorithm on a cron calendar
+func (srv *Service) CreateSchedule(ctx context.Context, req *v1.CreateScheduleRequest) (*v1.CreateScheduleResponse, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+	if req.Schedule == nil {
+		return nil, status.Error(codes.InvalidArgument, "schedule is required")
+	}
+	sched := proto.Clone(req.Schedule).(*v1.Schedule)
+	if !scheduleNamePattern.MatchString(sched.Name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid schedule name %q: must consist of lower case letters, digits and dashes", sched.Name)
+	}
+	if err := srv.validateSchedule(sched); err != nil {
+		return nil, err
+	}
+
+	srv.scheduling.Lock()
+	defer srv.scheduling.Unlock()
+
+	_, err := srv.Config.Schedules.Get(ctx, sched.Name)
+	if err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "schedule %s exists already", sched.Name)
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if id, ok := auth.FromContext(ctx); ok {
+		sched.Owner = id.Name
+	}
+	sched.Status = &v1.ScheduleStatus{}
+
+	err = srv.planSchedule(ctx, sched)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.WithField("name", sched.Name).WithField("cron", sched.Cron).WithField("owner", sched.Owner).Info("schedule created")
+	return &v1.CreateScheduleResponse{Schedule: sched}, nil
+}
+
+// ListSchedules returns all schedules ordered by name
+func (srv *Service) ListSchedules(ctx context.Context, req *v1.ListSchedulesRequest) (*v1.ListSchedulesResponse, error) {
+	res, err := srv.Config.Schedules.List(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &v1.ListSchedulesResponse{Schedules: res}, nil
+}
+
+// GetSchedule retrieves a single schedule
+func (srv *Service) GetSchedule(ctx context.Context, req *v1.GetScheduleRequest) (*v1.GetScheduleResponse, error) {
+	sched, err := srv.getSchedule(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.GetScheduleResponse{Schedule: sched}, nil
+}
+
+// UpdateSchedule replaces the definition of a schedule. Owner and status of the schedule are kept.
+func (srv *Service) UpdateSchedule(ctx context.Context, req *v1.UpdateScheduleRequest) (*v1.UpdateScheduleResponse, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+	if req.Schedule == nil {
+		return nil, status.Error(codes.InvalidArgument, "schedule is required")
+	}
+	sched := proto.Clone(req.Schedule).(*v1.Schedule)
+	if err := srv.validateSchedule(sched); err != nil {
+		return nil, err
+	}
+
+	srv.scheduling.Lock()
+	defer srv.scheduling.Unlock()
+
+	prev, err := srv.getSchedule(ctx, sched.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !mayModify(ctx, prev.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "only the owner or an admin can change schedule %s", sched.Name)
+	}
+	sched.Owner = prev.Owner
+	sched.Status = prev.Status
+	if sched.Status == nil {
+		sched.Status = &v1.ScheduleStatus{}
+	}
+
+	err = srv.planSchedule(ctx, sched)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.WithField("name", sched.Name).WithField("cron", sched.Cron).WithField("suspended", sched.Suspended).Info("schedule updated")
+	return &v1.UpdateScheduleResponse{Schedule: sched}, nil
+}
+
+// DeleteSchedule removes a schedule. Algorithms it started are not affected.
+func (srv *Service) DeleteSchedule(ctx context.Context, req *v1.DeleteScheduleRequest) (*v1.DeleteScheduleResponse, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	srv.scheduling.Lock()
+	defer srv.scheduling.Unlock()
+
+	sched, err := srv.getSchedule(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !mayModify(ctx, sched.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "only the owner or an admin can delete schedule %s", req.Name)
+	}
+	err = srv.Config.Schedules.Delete(ctx, req.Name)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	srv.cron.Cancel(req.Name)
+	log.WithField("name", req.Name).Info("schedule deleted")
+	return &v1.DeleteScheduleResponse{}, nil
+}
+
+// getSchedule retrieves a schedule. All errors returned are gRPC status errors.
+func (srv *Service) getSchedule(ctx context.Context, name string) (*v1.Schedule, error) {
+	sched, err := srv.Config.Schedules.Get(ctx, name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "schedule %s not found", name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return sched, nil
+}
+
+// validateSchedule checks the definition of a schedule, including that its Algorithm spec can be
+// started with its annotations. All errors returned are gRPC status errors.
+func (srv *Service) validateSchedule(sched *v1.Schedule) error {
+	if _, err := cron.Parse(sched.Cron); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid cron expression %q: %v", sched.Cron, err)
+	}
+	if _, err := time.LoadLocation(sched.TimeZone); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid time zone %q", sched.TimeZone)
+	}
+	if _, ok := v1.ConcurrencyPolicy_name[int32(sched.ConcurrencyPolicy)]; !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid concurrency policy %d", sched.ConcurrencyPolicy)
+	}
+	for _, a := range sched.Annotations {
+		if a.Key == ScheduleAnnotation {
+			return status.Errorf(codes.InvalidArgument, "annotation %s is reserved", ScheduleAnnotation)
+		}
+	}
+
+	_, _, spec, err := srv.findSpec(sched.AlgorithmSpecName)
+	if err != nil {
+		return err
+	}
+	if err := spec.ValidateAnnotations(sched.Annotations); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// findSpec looks up an Algorithm spec by name in the specs directory. All errors returned are gRPC status errors.
+func (srv *Service) findSpec(name string) (path string, content []byte, spec *AlgorithmSpec, err error) {
+	if name == "" {
+		return "", nil, nil, status.Error(codes.InvalidArgument, "algorithm_spec_name is required")
+	}
+	if srv.Config.SpecsDir == "" {
+		return "", nil, nil, status.Error(codes.FailedPrecondition, "no specs directory configured")
+	}
+	err = walkAlgorithmSpecs(srv.Config.SpecsDir, func(rel string, c []byte, s *AlgorithmSpec) error {
+		if spec == nil && SpecNameFromPath(rel) == name {
+			path, content, spec = rel, c, s
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).WithField("dir", srv.Config.SpecsDir).Error("cannot list algorithm specs")
+		return "", nil, nil, status.Error(codes.Internal, "cannot list algorithm specs")
+	}
+	if spec == nil {
+		return "", nil, nil, status.Errorf(codes.NotFound, "algorithm spec %s not found", name)
+	}
+	return path, content, spec, nil
+}
+
+// planSchedule computes the next run of a schedule, stores it and sets the timer for it.
+// Callers must hold srv.scheduling.
+func (srv *Service) planSchedule(ctx context.Context, sched *v1.Schedule) error {
+	var next time.Time
+	if !sched.Suspended {
+		expr, err := cron.Parse(sched.Cron)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(sched.TimeZone)
+		if err != nil {
+			return err
+		}
+		next = expr.Next(time.Now().In(loc))
+	}
+
+	if sched.Status == nil {
+		sched.Status = &v1.ScheduleStatus{}
+	}
+	sched.Status.NextRun = nil
+	if !next.IsZero() {
+		sched.Status.NextRun = timestamppb.New(next)
+	}
+	err := srv.Config.Schedules.Store(ctx, sched)
+	if err != nil {
+		return fmt.Errorf("cannot store schedule %s: %w", sched.Name, err)
+	}
+
+	if next.IsZero() {
+		srv.cron.Cancel(sched.Name)
+		return nil
+	}
+	srv.cron.Schedule(sched.Name, next, srv.runSchedule)
+	log.WithField("name", sched.Name).WithField("nextRun", next).Debug("schedule planned")
+	return nil
+}
+
+// runSchedule starts the Algorithm of a schedule whose time has come and plans its next run
+func (srv *Service) runSchedule(name string) {
+	ctx := context.Background()
+	srv.scheduling.Lock()
+	defer srv.scheduling.Unlock()
+
+	sched, err := srv.Config.Schedules.Get(ctx, name)
+	if errors.Is(err, store.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot run schedule")
+		return
+	}
+	if sched.Suspended {
+		return
+	}
+
+	algo, details := srv.fireSchedule(ctx, sched)
+	if sched.Status == nil {
+		sched.Status = &v1.ScheduleStatus{}
+	}
+	sched.Status.LastRun = timestamppb.Now()
+	sched.Status.LastDetails = details
+	if algo != "" {
+		sched.Status.LastAlgorithm = algo
+	}
+	err = srv.planSchedule(ctx, sched)
+	if err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot plan next run of schedule")
+	}
+}
+
+// fireSchedule applies the concurrency policy of a schedule and starts its Algorithm. It returns the
+// name of the started Algorithm, or why none was started.
+func (srv *Service) fireSchedule(ctx context.Context, sched *v1.Schedule) (algo string, details string) {
+	running, _, err := srv.Config.Algorithms.Find(ctx, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+		{Field: "metadata.annotations." + ScheduleAnnotation, Value: sched.Name},
+		{Field: "phase", Value: query.PhaseString(v1.AlgorithmPhase_PHASE_DONE), Negate: true},
+	}}}, nil, 0, 0)
+	if err != nil {
+		log.WithError(err).WithField("name", sched.Name).Error("cannot find previous runs of schedule")
+		return "", "cannot find previous runs"
+	}
+
+	switch sched.ConcurrencyPolicy {
+	case v1.ConcurrencyPolicy_CONCURRENCY_FORBID:
+		if len(running) > 0 {
+			log.WithField("name", sched.Name).WithField("running", running[0].Name).Info("skipping run of schedule")
+			return "", fmt.Sprintf("skipped because %s is still running", running[0].Name)
+		}
+	case v1.ConcurrencyPolicy_CONCURRENCY_REPLACE:
+		for _, prev := range running {
+			_, err := srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: prev.Name})
+			if err != nil {
+				log.WithError(err).WithField("name", sched.Name).WithField("algorithm", prev.Name).Warn("cannot replace previous run of schedule")
+			}
+		}
+	}
+
+	path, content, _, err := srv.findSpec(sched.AlgorithmSpecName)
+	if err != nil {
+		log.WithError(err).WithField("name", sched.Name).Warn("cannot run schedule")
+		return "", status.Convert(err).Message()
+	}
+	if err := srv.checkWritable(); err != nil {
+		return "", status.Convert(err).Message()
+	}
+	started, err := srv.startAlgorithm(ctx, algorithmStart{
+		Metadata: &v1.AlgorithmMetadata{
+			Owner:             sched.Owner,
+			Trigger:           v1.AlgorithmTrigger_TRIGGER_SCHEDULED,
+			Annotations:       sched.Annotations,
+			AlgorithmSpecName: sched.AlgorithmSpecName,
+		},
+		AlgorithmPath: path,
+		AlgorithmYAML: content,
+		Schedule:      sched.Name,
+	})
+	if err != nil {
+		log.WithError(err).WithField("name", sched.Name).Warn("cannot run schedule")
+		return "", status.Convert(err).Message()
+	}
+	return started.Name, ""
+}
+
+// mayModify returns true if the caller may stop or change what owner owns
+func mayModify(ctx context.Context, owner string) bool {
+	id, ok := auth.FromContext(ctx)
+	return !ok || id.Admin || id.Name == owner
+}
@@ -0,0 +1,336 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newScheduleService(t *testing.T) *Service {
+	dir := t.TempDir()
+	files := map[string]string{
+		"revaluation.yaml":      "description: revalues the books\narguments:\n- name: desk\n  required: true\n",
+		"curves/eod-curve.yaml": "description: builds the EOD curve",
+	}
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewService(Config{WorkspaceDir: t.TempDir(), SpecsDir: dir})
+}
+
+func TestCreateSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule *v1.Schedule
+		wantCode codes.Code
+	}{
+		{
+			name:     "valid",
+			schedule: &v1.Schedule{Name: "nightly-revaluation", Cron: "0 22 * * mon-fri", TimeZone: "Asia/Kolkata", AlgorithmSpecName: "revaluation", Annotations: []*v1.Annotation{{Key: "desk", Value: "rates"}}},
+		},
+		{
+			name:     "spec in subdirectory",
+			schedule: &v1.Schedule{Name: "eod-curve", Cron: "@daily", AlgorithmSpecName: "eod-curve"},
+		},
+		{
+			name:     "missing schedule",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid name",
+			schedule: &v1.Schedule{Name: "Nightly Revaluation", Cron: "@daily", AlgorithmSpecName: "eod-curve"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid cron expression",
+			schedule: &v1.Schedule{Name: "eod-curve", Cron: "0 25 * * *", AlgorithmSpecName: "eod-curve"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid time zone",
+			schedule: &v1.Schedule{Name: "eod-curve", Cron: "@daily", TimeZone: "Mars/Olympus_Mons", AlgorithmSpecName: "eod-curve"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown spec",
+			schedule: &v1.Schedule{Name: "eod-curve", Cron: "@daily", AlgorithmSpecName: "does-not-exist"},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "required annotation missing",
+			schedule: &v1.Schedule{Name: "nightly-revaluation", Cron: "@daily", AlgorithmSpecName: "revaluation"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "reserved annotation",
+			schedule: &v1.Schedule{Name: "eod-curve", Cron: "@daily", AlgorithmSpecName: "eod-curve", Annotations: []*v1.Annotation{{Key: ScheduleAnnotation, Value: "foo"}}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newScheduleService(t)
+			resp, err := srv.CreateSchedule(context.Background(), &v1.CreateScheduleRequest{Schedule: test.schedule})
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+			if err != nil {
+				return
+			}
+			if !srv.cron.Cancel(test.schedule.Name) {
+				t.Errorf("schedule was not planned")
+			}
+			next := resp.Schedule.GetStatus().GetNextRun()
+			if next == nil || !next.AsTime().After(time.Now()) {
+				t.Errorf("unexpected next run: %v", next)
+			}
+
+			_, err = srv.CreateSchedule(context.Background(), &v1.CreateScheduleRequest{Schedule: test.schedule})
+			if code := status.Code(err); code != codes.AlreadyExists {
+				t.Errorf("creating a schedule twice: want %v, got %v", codes.AlreadyExists, code)
+			}
+		})
+	}
+}
+
+func TestRunSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      v1.ConcurrencyPolicy
+		wantNames   []string
+		wantDone    []string
+		wantDetails string
+	}{
+		{
+			name:      "allow",
+			policy:    v1.ConcurrencyPolicy_CONCURRENCY_ALLOW,
+			wantNames: []string{"revaluation.1", "revaluation.2"},
+		},
+		{
+			name:        "forbid",
+			policy:      v1.ConcurrencyPolicy_CONCURRENCY_FORBID,
+			wantNames:   []string{"revaluation.1"},
+			wantDetails: "skipped because revaluation.1 is still running",
+		},
+		{
+			name:      "replace",
+			policy:    v1.ConcurrencyPolicy_CONCURRENCY_REPLACE,
+			wantNames: []string{"revaluation.1", "revaluation.2"},
+			wantDone:  []string{"revaluation.1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			// without an executor Algorithms remain in preparation, i.e. they keep running
+			srv := newScheduleService(t)
+			_, err := srv.CreateSchedule(ctx, &v1.CreateScheduleRequest{Schedule: &v1.Schedule{
+				Name:              "nightly",
+				Cron:              "0 22 * * *",
+				AlgorithmSpecName: "revaluation",
+				Annotations:       []*v1.Annotation{{Key: "desk", Value: "rates"}},
+				ConcurrencyPolicy: test.policy,
+				Owner:             "foo",
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv.cron.Cancel("nightly")
+
+			srv.runSchedule("nightly")
+			srv.runSchedule("nightly")
+
+			for _, name := range test.wantNames {
+				algo, err := srv.Config.Algorithms.Get(ctx, name)
+				if err != nil {
+					t.Fatalf("%s was not started: %v", name, err)
+				}
+				md := algo.Metadata
+				if md.Trigger != v1.AlgorithmTrigger_TRIGGER_SCHEDULED || md.Owner != "foo" || md.AlgorithmSpecName != "revaluation" {
+					t.Errorf("unexpected metadata of %s: %v", name, md)
+				}
+				if len(md.Annotations) != 2 || md.Annotations[1].Key != ScheduleAnnotation || md.Annotations[1].Value != "nightly" {
+					t.Errorf("%s is not linked to its schedule: %v", name, md.Annotations)
+				}
+			}
+			all, _, err := srv.Config.Algorithms.Find(ctx, nil, nil, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != len(test.wantNames) {
+				t.Errorf("unexpected number of algorithms: want %d, got %d", len(test.wantNames), len(all))
+			}
+			for _, name := range test.wantDone {
+				algo, err := srv.Config.Algorithms.Get(ctx, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if algo.Phase != v1.AlgorithmPhase_PHASE_DONE {
+					t.Errorf("%s was not replaced: %v", name, algo.Phase)
+				}
+			}
+
+			resp, err := srv.GetSchedule(ctx, &v1.GetScheduleRequest{Name: "nightly"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			st := resp.Schedule.Status
+			if st.LastRun == nil || st.NextRun == nil || st.LastDetails != test.wantDetails {
+				t.Errorf("unexpected status: %v", st)
+			}
+			if want := test.wantNames[len(test.wantNames)-1]; st.LastAlgorithm != want {
+				t.Errorf("unexpected last algorithm: want %s, got %s", want, st.LastAlgorithm)
+			}
+			srv.cron.Cancel("nightly")
+		})
+	}
+}
+
+func TestReplayScheduledRun(t *testing.T) {
+	ctx := context.Background()
+	srv := newScheduleService(t)
+	_, err := srv.CreateSchedule(ctx, &v1.CreateScheduleRequest{Schedule: &v1.Schedule{
+		Name:              "nightly",
+		Cron:              "0 22 * * *",
+		AlgorithmSpecName: "revaluation",
+		Annotations:       []*v1.Annotation{{Key: "desk", Value: "rates"}},
+		ConcurrencyPolicy: v1.ConcurrencyPolicy_CONCURRENCY_FORBID,
+		Owner:             "foo",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.cron.Cancel("nightly")
+	srv.runSchedule("nightly")
+
+	// a replay isn't a run of the schedule, which therefore still starts its next run
+	replay, err := srv.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{PreviousAlgorithm: "revaluation.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replay.Status.Metadata.Annotations) != 1 {
+		t.Errorf("replay is linked to the schedule: %v", replay.Status.Metadata.Annotations)
+	}
+	_, err = srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: "revaluation.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.runSchedule("nightly")
+	if _, err := srv.Config.Algorithms.Get(ctx, "revaluation.3"); err != nil {
+		t.Errorf("next run was not started: %v", err)
+	}
+	srv.cron.Cancel("nightly")
+}
+
+func TestUpdateAndDeleteSchedule(t *testing.T) {
+	srv := newScheduleService(t)
+	owner := auth.NewContext(context.Background(), auth.Identity{Name: "alice"})
+	other := auth.NewContext(context.Background(), auth.Identity{Name: "bob"})
+	admin := auth.NewContext(context.Background(), auth.Identity{Name: "carol", Admin: true})
+
+	sched := &v1.Schedule{Name: "eod-curve", Cron: "@daily", AlgorithmSpecName: "eod-curve"}
+	resp, err := srv.CreateSchedule(owner, &v1.CreateScheduleRequest{Schedule: sched})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Schedule.Owner != "alice" {
+		t.Errorf("owner was not set from identity: %s", resp.Schedule.Owner)
+	}
+
+	sched.Suspended = true
+	_, err = srv.UpdateSchedule(other, &v1.UpdateScheduleRequest{Schedule: sched})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("updating the schedule of others: want %v, got %v", codes.PermissionDenied, code)
+	}
+	updated, err := srv.UpdateSchedule(owner, &v1.UpdateScheduleRequest{Schedule: sched})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Schedule.Owner != "alice" || updated.Schedule.Status.GetNextRun() != nil {
+		t.Errorf("unexpected suspended schedule: %v", updated.Schedule)
+	}
+	if srv.cron.Cancel(sched.Name) {
+		t.Errorf("suspended schedule is still planned")
+	}
+
+	_, err = srv.UpdateSchedule(owner, &v1.UpdateScheduleRequest{Schedule: &v1.Schedule{Name: "does-not-exist", Cron: "@daily", AlgorithmSpecName: "eod-curve"}})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("updating an unknown schedule: want %v, got %v", codes.NotFound, code)
+	}
+
+	_, err = srv.DeleteSchedule(other, &v1.DeleteScheduleRequest{Name: sched.Name})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("deleting the schedule of others: want %v, got %v", codes.PermissionDenied, code)
+	}
+	_, err = srv.DeleteSchedule(admin, &v1.DeleteScheduleRequest{Name: sched.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := srv.ListSchedules(owner, &v1.ListSchedulesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Schedules) != 0 {
+		t.Errorf("schedule was not deleted: %v", list.Schedules)
+	}
+	_, err = srv.GetSchedule(owner, &v1.GetScheduleRequest{Name: sched.Name})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("getting a deleted schedule: want %v, got %v", codes.NotFound, code)
+	}
+}
+
+func TestStartPlansSchedules(t *testing.T) {
+	srv := newScheduleService(t)
+	err := srv.Config.Schedules.Store(context.Background(), &v1.Schedule{Name: "eod-curve", Cron: "*/5 * * * *", AlgorithmSpecName: "eod-curve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = srv.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !srv.cron.Cancel("eod-curve") {
+		t.Errorf("schedule was not planned on start")
+	}
+	sched, err := srv.Config.Schedules.Get(context.Background(), "eod-curve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := sched.Status.GetNextRun().AsTime(); next.Minute()%5 != 0 || !strings.HasSuffix(next.Format(time.RFC3339), ":00Z") {
+		t.Errorf("unexpected next run: %v", next)
+	}
+}
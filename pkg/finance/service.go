@@ -0,0 +1,539 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"github.com/bhojpur/finance/pkg/executor"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	"github.com/bhojpur/finance/pkg/store/memory"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultSpecName is used to name Algorithms whose spec name cannot be determined otherwise
+const defaultSpecName = "algorithm"
+
+// Config configures the Bhojpur Finance service
+type Config struct {
+	// WorkspaceDir is the directory in which uploaded applications are unpacked.
+	// Defaults to a directory in the system's temp directory.
+	WorkspaceDir string
+
+	// MaxUploadSize is the maximum size in bytes of an uploaded application, compressed or not.
+	// Zero means there's no limit.
+	MaxUploadSize int64
+
+	// Algorithms stores the status and spec of all Algorithms. Defaults to an in-memory store.
+	Algorithms store.Algorithms
+
+	// Logs stores the log output of finished Algorithms. Defaults to an in-memory store.
+	Logs store.Logs
+
+	// SpecsDir is the directory in which the Algorithm YAML files offered by the UI are found
+	SpecsDir string
+
+	// ReadOnly rejects all requests which would start or stop Algorithms
+	ReadOnly bool
+
+	// Executor runs the Algorithms. Without an executor Algorithms remain in preparation until stopped.
+	Executor executor.Executor
+
+	// Schedules stores the schedules which start Algorithms on a cron calendar. Defaults to an in-memory store.
+	Schedules store.Schedules
+
+	// GitOpsToken lets replays of Algorithms started by Git events keep their trigger.
+	// Replays without it are manual ones.
+	GitOpsToken string
+
+	// Retention bounds how many finished Algorithms and how much of their logs are kept.
+	// It's enforced by Prune, except for the log size which also applies when a log is stored.
+	Retention RetentionPolicy
+}
+
+// Service implements the Bhojpur Finance gRPC services
+type Service struct {
+	Config Config
+
+	logs    *logStore
+	events  *emitter
+	waiting *waitScheduler
+	cron    *waitScheduler
+
+	// updates serializes status updates so that an Algorithm can't leave the DONE phase
+	updates sync.Mutex
+
+	// scheduling serializes changes to schedules and their runs
+	scheduling sync.Mutex
+
+	metrics *serviceMetrics
+
+	v1.UnimplementedFinanceServiceServer
+}
+
+// NewService produces a new Bhojpur Finance service
+func NewService(cfg Config) *Service {
+	if cfg.WorkspaceDir == "" {
+		cfg.WorkspaceDir = filepath.Join(os.TempDir(), "finance-workspaces")
+	}
+	if cfg.Algorithms == nil {
+		cfg.Algorithms = memory.NewAlgorithms()
+	}
+	if cfg.Logs == nil {
+		cfg.Logs = memory.NewLogs()
+	}
+	if cfg.Schedules == nil {
+		cfg.Schedules = memory.NewSchedules()
+	}
+	return &Service{
+		Config:  cfg,
+		logs:    newLogStore(),
+		events:  newEmitter(),
+		waiting: newWaitScheduler(),
+		cron:    newWaitScheduler(),
+		metrics: newServiceMetrics(),
+	}
+}
+
+// Start resumes the background work of the service, e.g. it schedules all Algorithms
+// which are still waiting for their start time and plans the next run of all schedules.
+func (srv *Service) Start() error {
+	waiting, _, err := srv.Config.Algorithms.Find(context.Background(), []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+		{Field: "phase", Value: query.PhaseString(v1.AlgorithmPhase_PHASE_WAITING)},
+	}}}, nil, 0, 0)
+	if err != nil {
+		return fmt.Errorf("cannot find waiting algorithms: %w", err)
+	}
+	for _, algo := range waiting {
+		srv.scheduleAlgorithm(algo)
+	}
+
+	schedules, err := srv.Config.Schedules.List(context.Background())
+	if err != nil {
+		return fmt.Errorf("cannot list schedules: %w", err)
+	}
+	srv.scheduling.Lock()
+	defer srv.scheduling.Unlock()
+	for _, sched := range schedules {
+		// runs missed while we were down are skipped
+		err := srv.planSchedule(context.Background(), sched)
+		if err != nil {
+			log.WithError(err).WithField("name", sched.Name).Error("cannot plan schedule")
+		}
+	}
+	return nil
+}
+
+// StartAlgorithm starts a new Algorithm based on its specification.
+func (srv *Service) StartAlgorithm(ctx context.Context, req *v1.StartAlgorithmRequest) (*v1.StartAlgorithmResponse, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(req.AlgorithmYaml) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "algorithm_yaml is required")
+	}
+
+	algo, err := srv.startAlgorithm(ctx, algorithmStart{
+		Metadata:      req.Metadata,
+		AlgorithmPath: req.AlgorithmPath,
+		AlgorithmYAML: req.AlgorithmYaml,
+		NameSuffix:    req.NameSuffix,
+		WaitUntil:     req.WaitUntil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.StartAlgorithmResponse{Status: algo}, nil
+}
+
+// StartFromPreviousAlgorithm starts a new Algorithm with the metadata and spec of a previous one
+func (srv *Service) StartFromPreviousAlgorithm(ctx context.Context, req *v1.StartFromPreviousAlgorithmRequest) (*v1.StartAlgorithmResponse, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+	prev, err := srv.Config.Algorithms.Get(ctx, req.PreviousAlgorithm)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "algorithm %s not found", req.PreviousAlgorithm)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !prev.GetConditions().GetCanReplay() {
+		return nil, status.Errorf(codes.FailedPrecondition, "algorithm %s cannot be replayed", prev.Name)
+	}
+	spec, err := srv.Config.Algorithms.GetSpec(ctx, prev.Name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.FailedPrecondition, "algorithm %s has no spec to replay", prev.Name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	md, err := srv.replayMetadata(prev.Metadata, req.GitopsToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var prepare func(dst string) error
+	if src := srv.workspacePath(prev.Name); isDir(src) {
+		prepare = func(dst string) error { return copyWorkspace(src, dst) }
+	}
+	algo, err := srv.startAlgorithm(ctx, algorithmStart{
+		Metadata:         md,
+		AlgorithmYAML:    spec,
+		NamePrefix:       namePrefix(prev.Name),
+		WaitUntil:        req.WaitUntil,
+		PrepareWorkspace: prepare,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.StartAlgorithmResponse{Status: algo}, nil
+}
+
+// replayMetadata returns the metadata of a replayed Algorithm. Only replays which present the GitOps
+// token keep the trigger of an Algorithm started by a Git event, all others are manual replays.
+// Replays of scheduled runs aren't runs of their schedule, so they lose the link to it.
+func (srv *Service) replayMetadata(prev *v1.AlgorithmMetadata, gitopsToken string) (*v1.AlgorithmMetadata, error) {
+	md := proto.Clone(prev).(*v1.AlgorithmMetadata)
+	annotations := md.Annotations[:0]
+	for _, a := range md.Annotations {
+		if a.Key != ScheduleAnnotation {
+			annotations = append(annotations, a)
+		}
+	}
+	md.Annotations = annotations
+
+	if gitopsToken != "" {
+		if srv.Config.GitOpsToken == "" || subtle.ConstantTimeCompare([]byte(gitopsToken), []byte(srv.Config.GitOpsToken)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "invalid gitops token")
+		}
+		return md, nil
+	}
+
+	switch md.GetTrigger() {
+	case v1.AlgorithmTrigger_TRIGGER_PUSH, v1.AlgorithmTrigger_TRIGGER_DELETED:
+		md.Trigger = v1.AlgorithmTrigger_TRIGGER_MANUAL
+	}
+	return md, nil
+}
+
+// checkWritable returns a PermissionDenied error if the service is read-only
+func (srv *Service) checkWritable() error {
+	if srv.Config.ReadOnly {
+		return status.Error(codes.PermissionDenied, "Bhojpur Finance is read-only")
+	}
+	return nil
+}
+
+// algorithmStart describes an Algorithm which is about to be started
+type algorithmStart struct {
+	Metadata      *v1.AlgorithmMetadata
+	AlgorithmPath string
+	AlgorithmYAML []byte
+	NameSuffix    string
+	WaitUntil     *timestamppb.Timestamp
+
+	// NamePrefix overrides the name prefix otherwise derived from spec name and suffix
+	NamePrefix string
+
+	// PrepareWorkspace populates the workspace of the Algorithm before it's scheduled
+	PrepareWorkspace func(dst string) error
+
+	// Schedule links the Algorithm to the schedule which starts it. The link is reserved to the scheduler,
+	// as schedules find their running Algorithms by it.
+	Schedule string
+}
+
+// startAlgorithm validates and registers a new Algorithm. All errors returned are gRPC status errors.
+func (srv *Service) startAlgorithm(ctx context.Context, req algorithmStart) (*v1.AlgorithmStatus, error) {
+	if req.Metadata == nil {
+		return nil, status.Error(codes.InvalidArgument, "metadata is required")
+	}
+	spec, err := ParseAlgorithmSpec(req.AlgorithmYAML)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := spec.ValidateAnnotations(req.Metadata.Annotations); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	for _, a := range req.Metadata.Annotations {
+		if a.Key == ScheduleAnnotation {
+			return nil, status.Errorf(codes.InvalidArgument, "annotation %s is reserved", ScheduleAnnotation)
+		}
+	}
+
+	md := proto.Clone(req.Metadata).(*v1.AlgorithmMetadata)
+	if req.Schedule != "" {
+		md.Annotations = append(md.Annotations, &v1.Annotation{Key: ScheduleAnnotation, Value: req.Schedule})
+	}
+	if id, ok := auth.FromContext(ctx); ok {
+		md.Owner = id.Name
+	}
+	if md.AlgorithmSpecName == "" && req.AlgorithmPath != "" {
+		md.AlgorithmSpecName = SpecNameFromPath(req.AlgorithmPath)
+	}
+	if md.AlgorithmSpecName == "" {
+		md.AlgorithmSpecName = defaultSpecName
+	}
+	md.Created = timestamppb.Now()
+	md.Finished = nil
+
+	prefix := req.NamePrefix
+	if prefix == "" {
+		prefix = md.AlgorithmSpecName
+		if req.NameSuffix != "" {
+			prefix += "-" + strings.ToLower(req.NameSuffix)
+		}
+	}
+
+	phase := v1.AlgorithmPhase_PHASE_PREPARING
+	if req.WaitUntil != nil && req.WaitUntil.AsTime().After(time.Now()) {
+		phase = v1.AlgorithmPhase_PHASE_WAITING
+	}
+
+	algo, err := srv.addAlgorithm(ctx, prefix, &v1.AlgorithmStatus{
+		Metadata: md,
+		Phase:    phase,
+		Conditions: &v1.AlgorithmConditions{
+			CanReplay: true,
+			WaitUntil: req.WaitUntil,
+		},
+	}, req.AlgorithmYAML)
+	if err != nil {
+		log.WithError(err).WithField("prefix", prefix).Error("cannot store algorithm")
+		return nil, status.Errorf(codes.Internal, "cannot store algorithm: %v", err)
+	}
+	if req.PrepareWorkspace != nil {
+		err := req.PrepareWorkspace(srv.workspacePath(algo.Name))
+		if err != nil {
+			log.WithError(err).WithField("name", algo.Name).Error("cannot prepare workspace")
+			algo.Phase = v1.AlgorithmPhase_PHASE_DONE
+			algo.Details = "cannot prepare workspace"
+			if _, uerr := srv.updateAlgorithm(ctx, algo); uerr != nil {
+				log.WithError(uerr).WithField("name", algo.Name).Error("cannot update algorithm")
+			}
+			return nil, status.Errorf(codes.Internal, "cannot prepare workspace: %v", err)
+		}
+	}
+	srv.events.Emit(algo)
+	srv.metrics.started.WithLabelValues(query.TriggerString(md.Trigger)).Inc()
+	log.WithField("name", algo.Name).WithField("owner", md.Owner).WithField("phase", algo.Phase).Info("algorithm started")
+
+	switch algo.Phase {
+	case v1.AlgorithmPhase_PHASE_WAITING:
+		srv.scheduleAlgorithm(algo)
+	case v1.AlgorithmPhase_PHASE_PREPARING:
+		srv.runAlgorithm(ctx, algo)
+	}
+
+	return algo, nil
+}
+
+// addAlgorithm stores a new Algorithm together with its spec. The name of the Algorithm is derived
+// from the prefix and a number which is unique per prefix, e.g. "eod-curve.3".
+func (srv *Service) addAlgorithm(ctx context.Context, prefix string, algo *v1.AlgorithmStatus, spec []byte) (*v1.AlgorithmStatus, error) {
+	nr, err := srv.Config.Algorithms.NextNumber(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	algo.Name = fmt.Sprintf("%s.%d", prefix, nr)
+
+	// the spec goes first so that every stored Algorithm can be replayed
+	err = srv.Config.Algorithms.StoreSpec(ctx, algo.Name, spec)
+	if err != nil {
+		return nil, err
+	}
+	err = srv.Config.Algorithms.Store(ctx, algo)
+	if err != nil {
+		return nil, err
+	}
+	return algo, nil
+}
+
+// scheduleAlgorithm releases a waiting Algorithm once its wait_until time has come
+func (srv *Service) scheduleAlgorithm(algo *v1.AlgorithmStatus) {
+	at := time.Now()
+	if wu := algo.GetConditions().GetWaitUntil(); wu != nil {
+		at = wu.AsTime()
+	}
+	srv.waiting.Schedule(algo.Name, at, srv.releaseAlgorithm)
+	log.WithField("name", algo.Name).WithField("waitUntil", at).Debug("algorithm is waiting")
+}
+
+// releaseAlgorithm moves a waiting Algorithm on to preparation
+func (srv *Service) releaseAlgorithm(name string) {
+	ctx := context.Background()
+	algo, err := srv.Config.Algorithms.Get(ctx, name)
+	if err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot release waiting algorithm")
+		return
+	}
+	if algo.Phase != v1.AlgorithmPhase_PHASE_WAITING {
+		return
+	}
+
+	algo.Phase = v1.AlgorithmPhase_PHASE_PREPARING
+	algo, err = srv.updateAlgorithm(ctx, algo)
+	if err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot release waiting algorithm")
+		return
+	}
+	log.WithField("name", name).Info("algorithm is done waiting")
+	srv.runAlgorithm(ctx, algo)
+}
+
+// namePrefix returns the prefix of an Algorithm name, e.g. "eod-curve" for "eod-curve.3"
+func namePrefix(name string) string {
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		return name[:idx]
+	}
+	return name
+}
+
+// ListAlgorithm searches for Algorithms known to this instance
+func (srv *Service) ListAlgorithm(ctx context.Context, req *v1.ListAlgorithmRequest) (*v1.ListAlgorithmResponse, error) {
+	err := query.Validate(req.Filter, req.Order, req.Start, req.Limit)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res, total, err := srv.Config.Algorithms.Find(ctx, req.Filter, req.Order, int(req.Start), int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &v1.ListAlgorithmResponse{
+		Total:  int32(total),
+		Result: res,
+	}, nil
+}
+
+// GetAlgorithm retrieves details of a single Algorithm
+func (srv *Service) GetAlgorithm(ctx context.Context, req *v1.GetAlgorithmRequest) (*v1.GetAlgorithmResponse, error) {
+	algo, err := srv.Config.Algorithms.Get(ctx, req.Name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "algorithm %s not found", req.Name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &v1.GetAlgorithmResponse{Result: algo}, nil
+}
+
+// StopAlgorithm stops a currently running Algorithm
+func (srv *Service) StopAlgorithm(ctx context.Context, req *v1.StopAlgorithmRequest) (*v1.StopAlgorithmResponse, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+	algo, err := srv.Config.Algorithms.Get(ctx, req.Name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "algorithm %s not found", req.Name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !mayModify(ctx, algo.GetMetadata().GetOwner()) {
+		return nil, status.Errorf(codes.PermissionDenied, "only the owner or an admin can stop algorithm %s", req.Name)
+	}
+	if algo.Phase == v1.AlgorithmPhase_PHASE_DONE {
+		return nil, status.Errorf(codes.FailedPrecondition, "algorithm %s is already done", req.Name)
+	}
+	srv.waiting.Cancel(algo.Name)
+
+	if srv.Config.Executor != nil {
+		// a running Algorithm is reported done by the executor once it has stopped
+		err := srv.Config.Executor.Stop(algo.Name, "stopped")
+		if err == nil {
+			srv.metrics.stopped.Inc()
+			log.WithField("name", algo.Name).Info("stopping algorithm")
+			return &v1.StopAlgorithmResponse{}, nil
+		}
+		if !errors.Is(err, executor.ErrNotRunning) {
+			return nil, status.Errorf(codes.Internal, "cannot stop algorithm: %v", err)
+		}
+	}
+
+	algo.Phase = v1.AlgorithmPhase_PHASE_DONE
+	algo.Details = "stopped"
+	if algo.Conditions == nil {
+		algo.Conditions = &v1.AlgorithmConditions{}
+	}
+	algo.Conditions.Success = false
+	if _, err := srv.updateAlgorithm(ctx, algo); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	srv.metrics.stopped.Inc()
+	log.WithField("name", algo.Name).Info("algorithm stopped")
+
+	return &v1.StopAlgorithmResponse{}, nil
+}
+
+// updateAlgorithm stores a new Algorithm status and notifies all listeners. Once an
+// Algorithm is done its phase can no longer change and its log is complete, hence the
+// log is moved from memory to the log store.
+func (srv *Service) updateAlgorithm(ctx context.Context, algo *v1.AlgorithmStatus) (*v1.AlgorithmStatus, error) {
+	srv.updates.Lock()
+	defer srv.updates.Unlock()
+
+	old, err := srv.Config.Algorithms.Get(ctx, algo.Name)
+	if err != nil {
+		return nil, err
+	}
+	if old.Phase == v1.AlgorithmPhase_PHASE_DONE && algo.Phase != v1.AlgorithmPhase_PHASE_DONE {
+		return nil, fmt.Errorf("cannot move algorithm %s from %s to %s", algo.Name, old.Phase, algo.Phase)
+	}
+
+	res := proto.Clone(algo).(*v1.AlgorithmStatus)
+	done := res.Phase == v1.AlgorithmPhase_PHASE_DONE
+	if done && res.Metadata != nil && res.Metadata.Finished == nil {
+		res.Metadata.Finished = timestamppb.Now()
+	}
+	if done {
+		// the log is stored before the status so that whoever sees the Algorithm done finds its log
+		err = srv.Config.Logs.Store(ctx, res.Name, truncateLog(srv.logs.Close(res.Name), srv.Config.Retention.MaxLogSize))
+		if err != nil {
+			return nil, fmt.Errorf("cannot store log of %s: %w", res.Name, err)
+		}
+	}
+	err = srv.Config.Algorithms.Store(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		srv.logs.Remove(res.Name)
+	}
+	srv.events.Emit(res)
+	return res, nil
+}
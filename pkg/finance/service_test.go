@@ -0,0 +1,301 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStartAlgorithm(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *v1.StartAlgorithmRequest
+		wantName string
+		wantCode codes.Code
+	}{
+		{
+			name: "spec name from path",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+				AlgorithmPath: "algorithms/eod-curve.yaml",
+				AlgorithmYaml: []byte("description: builds the EOD curve"),
+			},
+			wantName: "eod-curve.1",
+		},
+		{
+			name: "name suffix",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo", AlgorithmSpecName: "revaluation"},
+				AlgorithmYaml: []byte("description: revaluation"),
+				NameSuffix:    "Nightly",
+			},
+			wantName: "revaluation-nightly.1",
+		},
+		{
+			name: "default spec name",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+				AlgorithmYaml: []byte("description: anonymous"),
+			},
+			wantName: "algorithm.1",
+		},
+		{
+			name:     "missing metadata",
+			req:      &v1.StartAlgorithmRequest{AlgorithmYaml: []byte("description: foo")},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing algorithm YAML",
+			req:      &v1.StartAlgorithmRequest{Metadata: &v1.AlgorithmMetadata{}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid algorithm YAML",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{},
+				AlgorithmYaml: []byte("description: [foo"),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "required annotation present",
+			req: &v1.StartAlgorithmRequest{
+				Metadata: &v1.AlgorithmMetadata{
+					Owner:       "foo",
+					Annotations: []*v1.Annotation{{Key: "curve", Value: "EUR"}},
+				},
+				AlgorithmPath: "eod-curve.yaml",
+				AlgorithmYaml: []byte("arguments:\n- name: curve\n  required: true\n- name: date\n"),
+			},
+			wantName: "eod-curve.1",
+		},
+		{
+			name: "required annotation missing",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo", Annotations: []*v1.Annotation{{Key: "date", Value: "2022-03-01"}}},
+				AlgorithmYaml: []byte("arguments:\n- name: curve\n  required: true\n- name: date\n"),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "reserved schedule annotation",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo", Annotations: []*v1.Annotation{{Key: ScheduleAnnotation, Value: "nightly"}}},
+				AlgorithmYaml: []byte("description: revaluation"),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "argument without name",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+				AlgorithmYaml: []byte("arguments:\n- description: the curve\n"),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid trigger pattern",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+				AlgorithmYaml: []byte("triggers:\n  push:\n  - ref: refs/heads/[main\n"),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "steps",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+				AlgorithmPath: "eod-curve.yaml",
+				AlgorithmYaml: []byte("steps:\n- name: fetch\n  command: [fetch-quotes, --all]\n  timeout: 5m\ntimeout: 1h\n"),
+			},
+			wantName: "eod-curve.1",
+		},
+		{
+			name: "step without command",
+			req: &v1.StartAlgorithmRequest{
+				Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+				AlgorithmYaml: []byte("steps:\n- name: fetch\n"),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := NewService(Config{})
+			resp, err := srv.StartAlgorithm(context.Background(), test.req)
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected status code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if resp.Status.Name != test.wantName {
+				t.Errorf("unexpected name: want %s, got %s", test.wantName, resp.Status.Name)
+			}
+			if resp.Status.Phase != v1.AlgorithmPhase_PHASE_PREPARING {
+				t.Errorf("unexpected phase: want %v, got %v", v1.AlgorithmPhase_PHASE_PREPARING, resp.Status.Phase)
+			}
+			if resp.Status.Metadata.Created == nil {
+				t.Errorf("created timestamp is not set")
+			}
+		})
+	}
+}
+
+func TestAlgorithmLifecycle(t *testing.T) {
+	ctx := context.Background()
+	srv := NewService(Config{})
+
+	var names []string
+	for i := 0; i < 2; i++ {
+		resp, err := srv.StartAlgorithm(ctx, &v1.StartAlgorithmRequest{
+			Metadata:      &v1.AlgorithmMetadata{Owner: "foo", AlgorithmSpecName: "eod-curve"},
+			AlgorithmYaml: []byte("description: builds the EOD curve"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, resp.Status.Name)
+	}
+	if names[0] == names[1] {
+		t.Fatalf("algorithm names are not unique: %v", names)
+	}
+
+	list, err := srv.ListAlgorithm(ctx, &v1.ListAlgorithmRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 2 || len(list.Result) != 2 {
+		t.Fatalf("unexpected list result: total %d, %d results", list.Total, len(list.Result))
+	}
+
+	_, err = srv.ListAlgorithm(ctx, &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "foo"}}})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("listing with an unknown field: want %v, got %v", codes.InvalidArgument, code)
+	}
+
+	_, err = srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: names[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get, err := srv.GetAlgorithm(ctx, &v1.GetAlgorithmRequest{Name: names[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if get.Result.Phase != v1.AlgorithmPhase_PHASE_DONE {
+		t.Errorf("stopped algorithm is in phase %v", get.Result.Phase)
+	}
+	if get.Result.Conditions.Success {
+		t.Errorf("stopped algorithm is marked successful")
+	}
+	if get.Result.Metadata.Finished == nil {
+		t.Errorf("stopped algorithm has no finished timestamp")
+	}
+
+	_, err = srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: names[0]})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf("stopping a done algorithm: want %v, got %v", codes.FailedPrecondition, code)
+	}
+	_, err = srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: "does-not-exist.1"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("stopping an unknown algorithm: want %v, got %v", codes.NotFound, code)
+	}
+	_, err = srv.GetAlgorithm(ctx, &v1.GetAlgorithmRequest{Name: "does-not-exist.1"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("getting an unknown algorithm: want %v, got %v", codes.NotFound, code)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	srv := NewService(Config{})
+	algo := startTestAlgorithm(t, srv, "foo")
+	srv.Config.ReadOnly = true
+
+	_, err := srv.StartAlgorithm(ctx, &v1.StartAlgorithmRequest{
+		Metadata:      &v1.AlgorithmMetadata{Owner: "foo"},
+		AlgorithmYaml: []byte("description: builds the EOD curve"),
+	})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("StartAlgorithm: want %v, got %v", codes.PermissionDenied, code)
+	}
+	_, err = srv.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{PreviousAlgorithm: algo.Name})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("StartFromPreviousAlgorithm: want %v, got %v", codes.PermissionDenied, code)
+	}
+	_, err = srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: algo.Name})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("StopAlgorithm: want %v, got %v", codes.PermissionDenied, code)
+	}
+	err = srv.StartLocalAlgorithm(&fakeStartLocalServer{})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("StartLocalAlgorithm: want %v, got %v", codes.PermissionDenied, code)
+	}
+
+	// reading is still possible
+	_, err = srv.GetAlgorithm(ctx, &v1.GetAlgorithmRequest{Name: algo.Name})
+	if err != nil {
+		t.Errorf("GetAlgorithm: %v", err)
+	}
+}
+
+func TestAuthenticatedOwner(t *testing.T) {
+	srv := NewService(Config{})
+	alice := auth.NewContext(context.Background(), auth.Identity{Name: "alice"})
+	resp, err := srv.StartAlgorithm(alice, &v1.StartAlgorithmRequest{
+		Metadata:      &v1.AlgorithmMetadata{Owner: "mallory", AlgorithmSpecName: "eod-curve"},
+		AlgorithmYaml: []byte("description: builds the EOD curve"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	algo := resp.Status
+	if algo.Metadata.Owner != "alice" {
+		t.Errorf("unexpected owner: want alice, got %s", algo.Metadata.Owner)
+	}
+
+	tests := []struct {
+		name     string
+		id       auth.Identity
+		wantCode codes.Code
+	}{
+		{name: "someone else", id: auth.Identity{Name: "bob"}, wantCode: codes.PermissionDenied},
+		{name: "owner", id: auth.Identity{Name: "alice"}},
+		{name: "admin", id: auth.Identity{Name: "carol", Admin: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			algo := startTestAlgorithm(t, srv, "alice")
+			ctx := auth.NewContext(context.Background(), test.id)
+			_, err := srv.StopAlgorithm(ctx, &v1.StopAlgorithmRequest{Name: algo.Name})
+			if code := status.Code(err); code != test.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+		})
+	}
+}
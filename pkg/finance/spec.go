@@ -0,0 +1,190 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/executor"
+	"sigs.k8s.io/yaml"
+)
+
+// AlgorithmSpec is the parsed content of an Algorithm YAML file
+type AlgorithmSpec struct {
+	// Description is a human readable explanation of what the Algorithm does
+	Description string `json:"description,omitempty"`
+
+	// Arguments are the annotations the Algorithm expects to be started with
+	Arguments []ArgumentSpec `json:"arguments,omitempty"`
+
+	// Triggers are the Git events which start the Algorithm through the webhook
+	Triggers TriggerSpec `json:"triggers,omitempty"`
+
+	// Spec describes the steps which run the Algorithm
+	executor.Spec
+}
+
+// TriggerSpec lists the Git events an Algorithm is started for
+type TriggerSpec struct {
+	// Push matches pushes to a repository
+	Push []RefPattern `json:"push,omitempty"`
+
+	// Deleted matches branches which were deleted
+	Deleted []RefPattern `json:"deleted,omitempty"`
+}
+
+// RefPattern matches the repository and ref of a Git event. Both fields are patterns
+// as understood by path.Match, empty ones match everything.
+type RefPattern struct {
+	// Repository is matched against host/owner/repo, e.g. "github.com/bhojpur/*"
+	Repository string `json:"repository,omitempty"`
+
+	// Ref is matched against the full ref, e.g. "refs/heads/main"
+	Ref string `json:"ref,omitempty"`
+}
+
+// Matches returns true if the Algorithm is started for a Git event with this trigger
+// in the repository, which has its ref set
+func (t *TriggerSpec) Matches(trigger v1.AlgorithmTrigger, repo *v1.Repository) bool {
+	var patterns []RefPattern
+	switch trigger {
+	case v1.AlgorithmTrigger_TRIGGER_PUSH:
+		patterns = t.Push
+	case v1.AlgorithmTrigger_TRIGGER_DELETED:
+		patterns = t.Deleted
+	}
+
+	name := repo.GetHost() + "/" + repo.GetOwner() + "/" + repo.GetRepo()
+	for _, p := range patterns {
+		if matchPattern(p.Repository, name) && matchPattern(p.Ref, repo.GetRef()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TriggerSpec) validate() error {
+	for _, p := range append(append([]RefPattern{}, t.Push...), t.Deleted...) {
+		for _, pattern := range []string{p.Repository, p.Ref} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid trigger pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+func matchPattern(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+// ArgumentSpec describes an annotation an Algorithm expects
+type ArgumentSpec struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// DesiredAnnotations returns the arguments of the spec in their API form
+func (spec *AlgorithmSpec) DesiredAnnotations() []*v1.DesiredAnnotation {
+	res := make([]*v1.DesiredAnnotation, 0, len(spec.Arguments))
+	for _, arg := range spec.Arguments {
+		res = append(res, &v1.DesiredAnnotation{
+			Name:        arg.Name,
+			Required:    arg.Required,
+			Description: arg.Description,
+		})
+	}
+	return res
+}
+
+// ValidateAnnotations checks that all required arguments of the spec are present
+// in the annotations
+func (spec *AlgorithmSpec) ValidateAnnotations(annotations []*v1.Annotation) error {
+	present := make(map[string]bool, len(annotations))
+	for _, a := range annotations {
+		present[a.Key] = true
+	}
+
+	var missing []string
+	for _, arg := range spec.Arguments {
+		if arg.Required && !present[arg.Name] {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required annotations: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// RepoConfig is the content of the finance/config.yaml file of an application
+type RepoConfig struct {
+	// DefaultAlgorithm is the path of the Algorithm YAML which is started by default
+	DefaultAlgorithm string `json:"defaultAlgorithm,omitempty"`
+}
+
+// ParseRepoConfig parses the content of a finance/config.yaml file
+func ParseRepoConfig(content []byte) (*RepoConfig, error) {
+	var res RepoConfig
+	if err := yaml.Unmarshal(content, &res); err != nil {
+		return nil, fmt.Errorf("cannot parse config YAML: %w", err)
+	}
+	return &res, nil
+}
+
+// ParseAlgorithmSpec parses the content of an Algorithm YAML file
+func ParseAlgorithmSpec(content []byte) (*AlgorithmSpec, error) {
+	var res AlgorithmSpec
+	if err := yaml.Unmarshal(content, &res); err != nil {
+		return nil, fmt.Errorf("cannot parse algorithm YAML: %w", err)
+	}
+	for i, arg := range res.Arguments {
+		if arg.Name == "" {
+			return nil, fmt.Errorf("invalid algorithm YAML: argument %d has no name", i)
+		}
+	}
+	if err := res.Triggers.validate(); err != nil {
+		return nil, fmt.Errorf("invalid algorithm YAML: %w", err)
+	}
+	if err := res.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid algorithm YAML: %w", err)
+	}
+	return &res, nil
+}
+
+// SpecNameFromPath derives the name of an Algorithm spec from the path of its YAML file,
+// e.g. "algorithms/eod-curve.yaml" becomes "eod-curve".
+func SpecNameFromPath(path string) string {
+	name := filepath.Base(path)
+	for _, ext := range []string{".yaml", ".yml"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
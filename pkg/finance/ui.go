@@ -0,0 +1,124 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UIService implements the Bhojpur Finance UI gRPC service
+type UIService struct {
+	Config Config
+
+	v1.UnimplementedFinanceUIServer
+}
+
+// NewUIService produces a new Bhojpur Finance UI service
+func NewUIService(cfg Config) *UIService {
+	return &UIService{Config: cfg}
+}
+
+// ListAlgorithmSpecs returns the Algorithm specs found in the specs directory
+func (uis *UIService) ListAlgorithmSpecs(req *v1.ListAlgorithmSpecsRequest, resp v1.FinanceUI_ListAlgorithmSpecsServer) error {
+	if uis.Config.SpecsDir == "" {
+		return nil
+	}
+
+	specs, err := findAlgorithmSpecs(uis.Config.SpecsDir)
+	if err != nil {
+		log.WithError(err).WithField("dir", uis.Config.SpecsDir).Error("cannot list algorithm specs")
+		return status.Error(codes.Internal, "cannot list algorithm specs")
+	}
+	for _, spec := range specs {
+		err := resp.Send(spec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsReadOnly returns true if the UI is readonly
+func (uis *UIService) IsReadOnly(ctx context.Context, req *v1.IsReadOnlyRequest) (*v1.IsReadOnlyResponse, error) {
+	return &v1.IsReadOnlyResponse{Readonly: uis.Config.ReadOnly}, nil
+}
+
+// findAlgorithmSpecs reads all Algorithm YAML files in dir and its subdirectories.
+// Files which are not valid Algorithm specs are skipped.
+func findAlgorithmSpecs(dir string) ([]*v1.ListAlgorithmSpecsResponse, error) {
+	var res []*v1.ListAlgorithmSpecsResponse
+	err := walkAlgorithmSpecs(dir, func(rel string, content []byte, spec *AlgorithmSpec) error {
+		res = append(res, &v1.ListAlgorithmSpecsResponse{
+			Name:        SpecNameFromPath(rel),
+			Path:        rel,
+			Description: spec.Description,
+			Arguments:   spec.DesiredAnnotations(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// walkAlgorithmSpecs calls fn for every valid Algorithm YAML file in dir and its subdirectories
+// with its slash separated path relative to dir. Hidden directories are skipped.
+func walkAlgorithmSpecs(dir string, fn func(rel string, content []byte, spec *AlgorithmSpec) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		spec, err := ParseAlgorithmSpec(content)
+		if err != nil {
+			log.WithError(err).WithField("path", path).Warn("skipping invalid algorithm spec")
+			return nil
+		}
+		return fn(filepath.ToSlash(rel), content, spec)
+	})
+}
@@ -0,0 +1,111 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeListSpecsServer struct {
+	grpc.ServerStream
+
+	res []*v1.ListAlgorithmSpecsResponse
+}
+
+func (f *fakeListSpecsServer) Context() context.Context { return context.Background() }
+
+func (f *fakeListSpecsServer) Send(resp *v1.ListAlgorithmSpecsResponse) error {
+	f.res = append(f.res, resp)
+	return nil
+}
+
+func TestListAlgorithmSpecs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"eod-curve.yaml":         "description: builds the EOD curve\narguments:\n- name: curve\n  required: true\n  description: currency of the curve\n- name: date\n",
+		"risk/revaluation.yml":   "description: revalues the books",
+		"risk/broken.yaml":       "description: [foo",
+		"README.md":              "not a spec",
+		".git/hooks/commit.yaml": "description: not a spec either",
+	}
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	uis := NewUIService(Config{SpecsDir: dir})
+	resp := &fakeListSpecsServer{}
+	err := uis.ListAlgorithmSpecs(&v1.ListAlgorithmSpecsRequest{}, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*v1.ListAlgorithmSpecsResponse{
+		{
+			Name:        "eod-curve",
+			Path:        "eod-curve.yaml",
+			Description: "builds the EOD curve",
+			Arguments: []*v1.DesiredAnnotation{
+				{Name: "curve", Required: true, Description: "currency of the curve"},
+				{Name: "date"},
+			},
+		},
+		{
+			Name:        "revaluation",
+			Path:        "risk/revaluation.yml",
+			Description: "revalues the books",
+			Arguments:   []*v1.DesiredAnnotation{},
+		},
+	}
+	if len(resp.res) != len(want) {
+		t.Fatalf("unexpected number of specs: want %d, got %d (%v)", len(want), len(resp.res), resp.res)
+	}
+	for i := range want {
+		if !proto.Equal(resp.res[i], want[i]) {
+			t.Errorf("unexpected spec %d: want %v, got %v", i, want[i], resp.res[i])
+		}
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	for _, readOnly := range []bool{false, true} {
+		uis := NewUIService(Config{ReadOnly: readOnly})
+		resp, err := uis.IsReadOnly(context.Background(), &v1.IsReadOnlyRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Readonly != readOnly {
+			t.Errorf("unexpected read-only state: want %v, got %v", readOnly, resp.Readonly)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"sync"
+	"time"
+)
+
+// waitScheduler releases waiting Algorithms once their time has come
+type waitScheduler struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newWaitScheduler() *waitScheduler {
+	return &waitScheduler{timers: make(map[string]*time.Timer)}
+}
+
+// Schedule calls release for the Algorithm at the given time. Times in the past release
+// the Algorithm immediately. Scheduling an Algorithm again replaces its previous schedule.
+func (s *waitScheduler) Schedule(name string, at time.Time, release func(name string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.timers[name]; ok {
+		t.Stop()
+	}
+	s.timers[name] = time.AfterFunc(time.Until(at), func() {
+		s.mu.Lock()
+		delete(s.timers, name)
+		s.mu.Unlock()
+
+		release(name)
+	})
+}
+
+// Cancel removes an Algorithm from the schedule. It returns false if the Algorithm was not scheduled.
+func (s *waitScheduler) Cancel(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.timers[name]
+	if !ok {
+		return false
+	}
+	t.Stop()
+	delete(s.timers, name)
+	return true
+}
@@ -0,0 +1,184 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func waitForPhase(t *testing.T, srv *Service, name string, phase v1.AlgorithmPhase) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		algo, err := srv.Config.Algorithms.Get(context.Background(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if algo.Phase == phase {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("algorithm %s did not reach %v", name, phase)
+}
+
+func startWaitingAlgorithm(t *testing.T, srv *Service, waitUntil time.Time) *v1.AlgorithmStatus {
+	t.Helper()
+	resp, err := srv.StartAlgorithm(context.Background(), &v1.StartAlgorithmRequest{
+		Metadata:      &v1.AlgorithmMetadata{Owner: "foo", AlgorithmSpecName: "eod-curve"},
+		AlgorithmYaml: []byte("description: builds the EOD curve"),
+		WaitUntil:     timestamppb.New(waitUntil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Status
+}
+
+func TestWaitUntil(t *testing.T) {
+	srv := NewService(Config{WorkspaceDir: t.TempDir()})
+
+	algo := startWaitingAlgorithm(t, srv, time.Now().Add(100*time.Millisecond))
+	if algo.Phase != v1.AlgorithmPhase_PHASE_WAITING {
+		t.Fatalf("unexpected phase: want %v, got %v", v1.AlgorithmPhase_PHASE_WAITING, algo.Phase)
+	}
+	if algo.Conditions.WaitUntil == nil {
+		t.Errorf("wait_until condition is not set")
+	}
+	waitForPhase(t, srv, algo.Name, v1.AlgorithmPhase_PHASE_PREPARING)
+
+	algo = startWaitingAlgorithm(t, srv, time.Now().Add(-time.Hour))
+	if algo.Phase != v1.AlgorithmPhase_PHASE_PREPARING {
+		t.Errorf("algorithm waiting for the past: want %v, got %v", v1.AlgorithmPhase_PHASE_PREPARING, algo.Phase)
+	}
+}
+
+func TestWaitUntilStop(t *testing.T) {
+	srv := NewService(Config{WorkspaceDir: t.TempDir()})
+
+	algo := startWaitingAlgorithm(t, srv, time.Now().Add(time.Hour))
+	_, err := srv.StopAlgorithm(context.Background(), &v1.StopAlgorithmRequest{Name: algo.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.waiting.Cancel(algo.Name) {
+		t.Errorf("stopped algorithm is still scheduled")
+	}
+	waitForPhase(t, srv, algo.Name, v1.AlgorithmPhase_PHASE_DONE)
+}
+
+func TestStartResumesWaitingAlgorithms(t *testing.T) {
+	srv := NewService(Config{WorkspaceDir: t.TempDir()})
+
+	// simulate an algorithm that was waiting when the server went down
+	algo, err := srv.addAlgorithm(context.Background(), "eod-curve", &v1.AlgorithmStatus{
+		Metadata: &v1.AlgorithmMetadata{Owner: "foo"},
+		Phase:    v1.AlgorithmPhase_PHASE_WAITING,
+		Conditions: &v1.AlgorithmConditions{
+			WaitUntil: timestamppb.New(time.Now().Add(50 * time.Millisecond)),
+		},
+	}, []byte("description: builds the EOD curve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitForPhase(t, srv, algo.Name, v1.AlgorithmPhase_PHASE_PREPARING)
+}
+
+func TestStartFromPreviousAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	srv := NewService(Config{WorkspaceDir: t.TempDir()})
+
+	resp, err := srv.StartAlgorithm(ctx, &v1.StartAlgorithmRequest{
+		Metadata: &v1.AlgorithmMetadata{
+			Owner:             "foo",
+			AlgorithmSpecName: "eod-curve",
+			Trigger:           v1.AlgorithmTrigger_TRIGGER_MANUAL,
+			Annotations:       []*v1.Annotation{{Key: "desk", Value: "rates"}},
+		},
+		AlgorithmYaml: []byte("description: builds the EOD curve"),
+		NameSuffix:    "nightly",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := resp.Status
+	if !prev.Conditions.CanReplay {
+		t.Fatalf("algorithm cannot be replayed")
+	}
+	err = os.MkdirAll(srv.workspacePath(prev.Name), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(srv.workspacePath(prev.Name), "quotes.csv"), []byte("1,2,3"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := srv.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{PreviousAlgorithm: prev.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Status.Name != "eod-curve-nightly.2" {
+		t.Errorf("unexpected name: want eod-curve-nightly.2, got %s", replay.Status.Name)
+	}
+	md := replay.Status.Metadata
+	if md.Owner != "foo" || md.AlgorithmSpecName != "eod-curve" || len(md.Annotations) != 1 || md.Annotations[0].Value != "rates" {
+		t.Errorf("metadata was not cloned: %v", md)
+	}
+	spec, err := srv.Config.Algorithms.GetSpec(ctx, replay.Status.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(spec) != "description: builds the EOD curve" {
+		t.Errorf("spec was not cloned: %s", spec)
+	}
+	content, err := os.ReadFile(filepath.Join(srv.workspacePath(replay.Status.Name), "quotes.csv"))
+	if err != nil || string(content) != "1,2,3" {
+		t.Errorf("workspace was not cloned: %q, %v", content, err)
+	}
+
+	_, err = srv.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{PreviousAlgorithm: "does-not-exist.1"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("replaying an unknown algorithm: want %v, got %v", codes.NotFound, code)
+	}
+
+	prev.Conditions.CanReplay = false
+	if _, err := srv.updateAlgorithm(ctx, prev); err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{PreviousAlgorithm: prev.Name})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf("replaying a non-replayable algorithm: want %v, got %v", codes.FailedPrecondition, code)
+	}
+}
@@ -0,0 +1,70 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/webhook"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StartFromEvent starts every Algorithm in the specs directory whose triggers match a Git event
+// and returns the names of the started Algorithms. Specs which cannot be started, e.g. because
+// they require arguments, are skipped.
+func (srv *Service) StartFromEvent(ctx context.Context, ev *webhook.Event) ([]string, error) {
+	if err := srv.checkWritable(); err != nil {
+		return nil, err
+	}
+	if srv.Config.SpecsDir == "" {
+		return nil, nil
+	}
+
+	var started []string
+	err := walkAlgorithmSpecs(srv.Config.SpecsDir, func(rel string, content []byte, spec *AlgorithmSpec) error {
+		if !spec.Triggers.Matches(ev.Trigger, ev.Repository) {
+			return nil
+		}
+		algo, err := srv.startAlgorithm(ctx, algorithmStart{
+			Metadata: &v1.AlgorithmMetadata{
+				Owner:      ev.Sender,
+				Repository: ev.Repository,
+				Trigger:    ev.Trigger,
+			},
+			AlgorithmPath: rel,
+			AlgorithmYAML: content,
+		})
+		if err != nil {
+			log.WithError(err).WithField("path", rel).WithField("repository", ev.RepositoryName()).Warn("cannot start algorithm for Git event")
+			return nil
+		}
+		started = append(started, algo.Name)
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).WithField("dir", srv.Config.SpecsDir).Error("cannot list algorithm specs")
+		return started, status.Error(codes.Internal, "cannot list algorithm specs")
+	}
+	return started, nil
+}
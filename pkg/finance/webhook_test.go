@@ -0,0 +1,176 @@
+package finance
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/webhook"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStartFromEvent(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"eod-curve.yaml":       "triggers:\n  push:\n  - repository: github.com/bhojpur/rates\n    ref: refs/heads/main\n",
+		"risk/revaluation.yml": "triggers:\n  push:\n  - repository: github.com/bhojpur/*\n",
+		"teardown.yaml":        "triggers:\n  deleted:\n  - ref: refs/heads/*\n",
+		"needs-args.yaml":      "arguments:\n- name: curve\n  required: true\ntriggers:\n  push:\n  - {}\n",
+		"manual.yaml":          "description: never triggered",
+	}
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		event *webhook.Event
+		want  []string
+	}{
+		{
+			name: "push to main",
+			event: &webhook.Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "rates", Ref: "refs/heads/main", Revision: "3f5a7c9e"},
+				Sender:     "prao",
+			},
+			want: []string{"eod-curve.1", "revaluation.1"},
+		},
+		{
+			name: "push to branch",
+			event: &webhook.Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "rates", Ref: "refs/heads/develop", Revision: "5b7d9f1a"},
+				Sender:     "prao",
+			},
+			want: []string{"revaluation.2"},
+		},
+		{
+			name: "push elsewhere",
+			event: &webhook.Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "gitlab.com", Owner: "bhojpur/quant", Repo: "risk-engine", Ref: "refs/heads/main", Revision: "da156088"},
+				Sender:     "kiyer",
+			},
+		},
+		{
+			name: "deleted branch",
+			event: &webhook.Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_DELETED,
+				Repository: &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "rates", Ref: "refs/heads/feature"},
+				Sender:     "prao",
+			},
+			want: []string{"teardown.1"},
+		},
+	}
+	ctx := context.Background()
+	srv := NewService(Config{WorkspaceDir: t.TempDir(), SpecsDir: dir})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			started, err := srv.StartFromEvent(ctx, test.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(started)
+			if len(started) != len(test.want) {
+				t.Fatalf("unexpected algorithms: want %v, got %v", test.want, started)
+			}
+			for i, name := range started {
+				if name != test.want[i] {
+					t.Errorf("unexpected algorithms: want %v, got %v", test.want, started)
+				}
+				algo, err := srv.Config.Algorithms.Get(ctx, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				md := algo.Metadata
+				if md.Trigger != test.event.Trigger || md.Owner != test.event.Sender || md.Repository.Revision != test.event.Repository.Revision {
+					t.Errorf("unexpected metadata of %s: %v", name, md)
+				}
+			}
+		})
+	}
+
+	srv.Config.ReadOnly = true
+	_, err := srv.StartFromEvent(ctx, tests[0].event)
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("starting from an event while read-only: want %v, got %v", codes.PermissionDenied, code)
+	}
+}
+
+func TestReplayGitOps(t *testing.T) {
+	ctx := context.Background()
+	srv := NewService(Config{WorkspaceDir: t.TempDir(), GitOpsToken: "s3cr3t"})
+	resp, err := srv.StartAlgorithm(ctx, &v1.StartAlgorithmRequest{
+		Metadata: &v1.AlgorithmMetadata{
+			Owner:      "prao",
+			Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+			Repository: &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "rates", Ref: "refs/heads/main", Revision: "3f5a7c9e"},
+		},
+		AlgorithmPath: "eod-curve.yaml",
+		AlgorithmYaml: []byte("description: builds the EOD curve"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := resp.Status.Name
+
+	tests := []struct {
+		name        string
+		token       string
+		wantTrigger v1.AlgorithmTrigger
+		wantCode    codes.Code
+	}{
+		{name: "with token", token: "s3cr3t", wantTrigger: v1.AlgorithmTrigger_TRIGGER_PUSH},
+		{name: "without token", wantTrigger: v1.AlgorithmTrigger_TRIGGER_MANUAL},
+		{name: "invalid token", token: "guessed", wantCode: codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replay, err := srv.StartFromPreviousAlgorithm(ctx, &v1.StartFromPreviousAlgorithmRequest{PreviousAlgorithm: prev, GitopsToken: test.token})
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+			if err != nil {
+				return
+			}
+			md := replay.Status.Metadata
+			if md.Trigger != test.wantTrigger {
+				t.Errorf("unexpected trigger: want %v, got %v", test.wantTrigger, md.Trigger)
+			}
+			if md.Repository.GetRevision() != "3f5a7c9e" {
+				t.Errorf("repository was not kept: %v", md.Repository)
+			}
+		})
+	}
+}
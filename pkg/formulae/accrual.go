@@ -0,0 +1,80 @@
+package formulae
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/bhojpur/finance/pkg/formulae/daycount"
+)
+
+// setYearFractions derives the year fraction between the start date of every period and the start of the next
+// period under the day count convention of the config. It does nothing unless a DayCount is set.
+func (c *Config) setYearFractions() error {
+	c.yearFractions = nil
+	if c.DayCount == "" {
+		return nil
+	}
+	for i := range c.startDates {
+		years, err := daycount.YearFraction(dateOf(c.startDates[i]), dateOf(c.endDates[i]).AddDate(0, 0, 1), c.DayCount)
+		if err != nil {
+			return ErrDayCount
+		}
+		c.yearFractions = append(c.yearFractions, decimal.NewFromFloat(years))
+	}
+	return nil
+}
+
+// periodRate converts an annual rate to the interest rate of a period, counted from zero. Under a day count the
+// period accrues for its year fraction, otherwise for its share of the year scaled by the interest factor of stubs.
+func (c *Config) periodRate(period int, annual decimal.Decimal) decimal.Decimal {
+	return c.stubRate(period, c.regularRate(period, annual))
+}
+
+// regularRate converts an annual rate to the interest rate of a period as if it were a regular one. Under a day
+// count the period accrues for its year fraction, otherwise for its share of the year.
+func (c *Config) regularRate(period int, annual decimal.Decimal) decimal.Decimal {
+	if c.yearFractions != nil {
+		return annual.Mul(c.yearFractions[period])
+	}
+	return annual.Div(decimal.NewFromInt(int64(c.Frequency.Value())))
+}
+
+// stubRate scales the rate of a regular period by the interest factor of a period, counted from zero, if it is
+// a stub. Year fractions already account for the length of stubs.
+func (c *Config) stubRate(period int, rate decimal.Decimal) decimal.Decimal {
+	if c.yearFractions != nil {
+		return rate
+	}
+	if factor, ok := c.interestFactor(int64(period + 1)); ok {
+		return rate.Mul(factor)
+	}
+	return rate
+}
+
+// periodRates returns the interest rate of every period given the annual rate in force in it.
+func (c *Config) periodRates() []decimal.Decimal {
+	rates := make([]decimal.Decimal, c.periods)
+	for i := range rates {
+		rates[i] = c.periodRate(i, c.annualRates[i])
+	}
+	return rates
+}
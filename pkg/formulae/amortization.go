@@ -47,6 +47,15 @@ func NewAmortization(c *Config) (*Amortization, error) {
 	if err := a.Config.setPeriodsAndDates(); err != nil {
 		return nil, err
 	}
+	if err := a.Config.setYearFractions(); err != nil {
+		return nil, err
+	}
+	if err := a.Config.setAnnualRates(); err != nil {
+		return nil, err
+	}
+	if err := a.Config.validatePrepayments(); err != nil {
+		return nil, err
+	}
 	switch a.Config.InterestType {
 	case interesttype.REDUCING:
 		a.Financial = &Reducing{}
@@ -56,28 +65,62 @@ func NewAmortization(c *Config) (*Amortization, error) {
 	return &a, nil
 }
 
-// Row represents a single row in an amortization schedule.
+// Row represents a single row in an amortization schedule. Payments are negative, while the balance is the
+// principal outstanding after the period.
 type Row struct {
-	Period    int64
-	StartDate time.Time
-	EndDate   time.Time
-	Payment   decimal.Decimal
-	Interest  decimal.Decimal
-	Principal decimal.Decimal
+	Period     int64
+	StartDate  time.Time
+	EndDate    time.Time
+	Payment    decimal.Decimal
+	Interest   decimal.Decimal
+	Principal  decimal.Decimal
+	Prepayment decimal.Decimal
+	Charge     decimal.Decimal
+	Balance    decimal.Decimal
 }
 
 // GenerateTable constructs the amortization table based on the configuration.
 func (a Amortization) GenerateTable() ([]Row, error) {
 	var result []Row
-	for i := int64(1); i <= a.Config.periods; i++ {
+	var simulated []scheduled
+	periods, principal := a.Config.periods, a.Config.AmountBorrowed
+	if a.Config.yearFractions != nil || len(a.Config.Prepayments) > 0 || len(a.Config.RateResets) > 0 {
+		var err error
+		simulated, err = a.Config.simulate()
+		if err != nil {
+			return nil, err
+		}
+		periods = int64(len(simulated))
+	}
+	balance := a.Config.AmountBorrowed
+	for i := int64(1); i <= periods; i++ {
 		var row Row
 		row.Period = i
 		row.StartDate = a.Config.startDates[i-1]
 		row.EndDate = a.Config.endDates[i-1]
 
-		payment := a.Financial.GetPayment(*a.Config)
-		principalPayment := a.Financial.GetPrincipal(*a.Config, i)
-		interestPayment := a.Financial.GetInterest(*a.Config, i)
+		var payment, principalPayment, interestPayment decimal.Decimal
+		if simulated != nil {
+			// simulated schedules already charge every period its own rate, stubs included
+			s := simulated[i-1]
+			payment, principalPayment, interestPayment = s.payment.Neg(), s.principal.Neg(), s.interest.Neg()
+			row.Prepayment, row.Charge = s.prepayment.Neg(), s.charge.Neg()
+			if a.Config.EnableRounding {
+				row.Prepayment = row.Prepayment.Round(a.Config.RoundingPlaces)
+				row.Charge = row.Charge.Round(a.Config.RoundingPlaces)
+			}
+			// principal which is prepaid isn't collected by the payments
+			principal = principal.Add(row.Prepayment)
+		} else {
+			payment = a.Financial.GetPayment(*a.Config)
+			principalPayment = a.Financial.GetPrincipal(*a.Config, i)
+			interestPayment = a.Financial.GetInterest(*a.Config, i)
+			if factor, ok := a.Config.interestFactor(i); ok {
+				// stubs pay their principal as scheduled and interest for the days they span
+				interestPayment = interestPayment.Mul(factor)
+				payment = principalPayment.Add(interestPayment)
+			}
+		}
 		if a.Config.EnableRounding {
 			row.Payment = payment.Round(a.Config.RoundingPlaces)
 			row.Principal = principalPayment.Round(a.Config.RoundingPlaces)
@@ -88,12 +131,14 @@ func (a Amortization) GenerateTable() ([]Row, error) {
 			row.Principal = principalPayment
 			row.Interest = interestPayment
 		}
-		if i == a.Config.periods {
-			DoPrincipalAdjustmentDueToRounding(&row, result, a.Config.AmountBorrowed, a.Config.EnableRounding, a.Config.RoundingPlaces)
+		if i == periods {
+			DoPrincipalAdjustmentDueToRounding(&row, result, principal, a.Config.EnableRounding, a.Config.RoundingPlaces)
 		}
 		if err := sanityCheckUpdate(&row, a.Config.RoundingErrorTolerance); err != nil {
 			return nil, err
 		}
+		balance = balance.Add(row.Principal).Add(row.Prepayment)
+		row.Balance = balance
 		result = append(result, row)
 	}
 	return result, nil
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -35,9 +36,11 @@ import (
 
 	"github.com/bhojpur/finance/pkg/enums/interesttype"
 	"github.com/bhojpur/finance/pkg/enums/paymentperiod"
+	"github.com/bhojpur/finance/pkg/enums/recasttype"
 	"github.com/smartystreets/assertions"
 
 	"github.com/bhojpur/finance/pkg/enums/frequency"
+	"github.com/bhojpur/finance/pkg/enums/stubtype"
 )
 
 const (
@@ -85,6 +88,55 @@ func Test_amortization_GenerateTable(t *testing.T) {
 			want:    getRowsFlatWithRounding(t),
 			wantErr: false,
 		},
+		{
+			// the front stub spans 17 of 31 days and the back stub 15 of 31 days, regular periods pay 1% of 1500
+			name: "monthly table with front and back stubs, flat interest",
+			fields: fields{
+				Config: &Config{
+					StartDate:        time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC),
+					EndDate:          time.Date(2022, 5, 15, 0, 0, 0, 0, time.UTC),
+					FirstPaymentDate: time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC),
+					BackStub:         stubtype.SHORT,
+					Frequency:        frequency.MONTHLY,
+					AmountBorrowed:   decimal.NewFromInt(1500),
+					InterestType:     interesttype.FLAT,
+					Interest:         decimal.NewFromInt(1200),
+					EnableRounding:   true,
+					RoundingPlaces:   2,
+				},
+			},
+			want: []Row{
+				getRow(t, 1, "2022-01-15", "2022-01-31", "-308.23", "-8.23", "-300"),
+				getRow(t, 2, "2022-02-01", "2022-02-28", "-315", "-15", "-300"),
+				getRow(t, 3, "2022-03-01", "2022-03-31", "-315", "-15", "-300"),
+				getRow(t, 4, "2022-04-01", "2022-04-30", "-315", "-15", "-300"),
+				getRow(t, 5, "2022-05-01", "2022-05-15", "-307.26", "-7.26", "-300"),
+			},
+			wantErr: false,
+		},
+		{
+			// January and March accrue 31 and February 28 days of 12% a year on 1000
+			name: "monthly table with ACT365 day count, flat interest",
+			fields: fields{
+				Config: &Config{
+					StartDate:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:        time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+					Frequency:      frequency.MONTHLY,
+					AmountBorrowed: decimal.NewFromInt(1000),
+					InterestType:   interesttype.FLAT,
+					Interest:       decimal.NewFromInt(1200),
+					DayCount:       "ACT365",
+					EnableRounding: true,
+					RoundingPlaces: 2,
+				},
+			},
+			want: []Row{
+				getRow(t, 1, "2022-01-01", "2022-01-31", "-343.53", "-10.2", "-333.33"),
+				getRow(t, 2, "2022-02-01", "2022-02-28", "-342.54", "-9.21", "-333.33"),
+				getRow(t, 3, "2022-03-01", "2022-03-31", "-343.54", "-10.2", "-333.34"),
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -257,6 +309,33 @@ func timeParseUtil(t *testing.T, input string) time.Time {
 	return resultTime
 }
 
+// getRow returns the row of a period from its dates and amounts, which is what schedules print
+func getRow(t *testing.T, period int64, start, end, payment, interest, principal string) Row {
+	return Row{
+		Period:    period,
+		StartDate: timeParseUtil(t, start+" 00:00:00 +0000 UTC"),
+		EndDate:   timeParseUtil(t, end+" 23:59:59 +0000 UTC"),
+		Payment:   decimal.RequireFromString(payment),
+		Interest:  decimal.RequireFromString(interest),
+		Principal: decimal.RequireFromString(principal),
+	}
+}
+
+// getLoanConfig returns a monthly loan of 100000 at 12% over 2022, the base of the prepayment and rate reset tests
+func getLoanConfig(interestType interesttype.Type, recast recasttype.Type) *Config {
+	return &Config{
+		StartDate:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+		Frequency:      frequency.MONTHLY,
+		AmountBorrowed: decimal.NewFromInt(100000),
+		InterestType:   interestType,
+		Interest:       decimal.NewFromInt(1200),
+		EnableRounding: true,
+		RoundingPlaces: 2,
+		Recast:         recast,
+	}
+}
+
 func getConfigDto(frequency frequency.Type, round bool, interestType interesttype.Type, amount decimal.Decimal, interest decimal.Decimal, places int32) *Config {
 	return &Config{
 		StartDate:      time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC),
@@ -290,6 +369,16 @@ func TestPlotRows(t *testing.T) {
 		},
 	}
 
+	// PlotRows writes to the working directory, which mustn't be the source tree
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
@@ -376,6 +465,92 @@ func getHtmlWithoutUniqueId(input string) string {
 	return strings.Join(result, "\n")
 }
 
+func TestAmortization_GenerateTableWithDayCount(t *testing.T) {
+	c := &Config{
+		StartDate:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+		Frequency:      frequency.MONTHLY,
+		AmountBorrowed: decimal.NewFromInt(1000),
+		InterestType:   interesttype.REDUCING,
+		Interest:       decimal.NewFromInt(1200),
+		DayCount:       "ACT365",
+	}
+	a, err := NewAmortization(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := a.GenerateTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a reducing loan pays interest on the outstanding balance for the days of each period
+	balance := decimal.NewFromInt(1000)
+	for i, r := range rows {
+		days := decimal.NewFromInt(int64(r.EndDate.Day()))
+		interest := balance.Mul(decimal.NewFromFloat(0.12)).Mul(days).Div(decimal.NewFromInt(365))
+		if !r.Interest.Neg().Sub(interest).Abs().LessThan(decimal.NewFromFloat(precision)) {
+			t.Errorf("period %d: want interest %v, got %v", i+1, interest, r.Interest.Neg())
+		}
+		if i < len(rows)-1 && !r.Payment.Equal(rows[0].Payment) {
+			t.Errorf("period %d: want payment %v, got %v", i+1, rows[0].Payment, r.Payment)
+		}
+		balance = balance.Add(r.Principal)
+	}
+	if !balance.Abs().LessThan(decimal.NewFromFloat(precision)) {
+		t.Errorf("want the loan repaid, got a balance of %v", balance)
+	}
+}
+
+func TestAmortization_GenerateTableWithDayCountMatchesPeriodicRate(t *testing.T) {
+	// under 30E360 every month is a twelfth of a year, so the schedule must not change
+	for _, pp := range []paymentperiod.Type{paymentperiod.ENDING, paymentperiod.BEGINNING} {
+		config := func(dayCount string) *Config {
+			return &Config{
+				StartDate:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:        time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+				Frequency:      frequency.MONTHLY,
+				AmountBorrowed: decimal.NewFromInt(100000),
+				InterestType:   interesttype.REDUCING,
+				Interest:       decimal.NewFromInt(850),
+				PaymentPeriod:  pp,
+				DayCount:       dayCount,
+				EnableRounding: true,
+				RoundingPlaces: 2,
+			}
+		}
+		var tables [2][]Row
+		for i, dayCount := range []string{"", "30E360"} {
+			a, err := NewAmortization(config(dayCount))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tables[i], err = a.GenerateTable(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := range tables[0] {
+			if err := verifyRow(t, tables[1][i], tables[0][i]); err != nil {
+				t.Errorf("payment period %v: %v", pp, err)
+			}
+		}
+	}
+}
+
+func TestAmortization_GenerateTableWithUnknownDayCount(t *testing.T) {
+	c := &Config{
+		StartDate:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+		Frequency:      frequency.MONTHLY,
+		AmountBorrowed: decimal.NewFromInt(1000),
+		InterestType:   interesttype.REDUCING,
+		Interest:       decimal.NewFromInt(1200),
+		DayCount:       "ACT999",
+	}
+	if _, err := NewAmortization(c); !errors.Is(err, ErrDayCount) {
+		t.Errorf("want %v, got %v", ErrDayCount, err)
+	}
+}
+
 func getExpectedHtmlString() string {
 	return `
 <!DOCTYPE html>
@@ -21,6 +21,7 @@ package formulae
 // THE SOFTWARE.
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -30,13 +31,17 @@ import (
 	"github.com/bhojpur/finance/pkg/enums/interesttype"
 
 	"github.com/bhojpur/finance/pkg/enums/frequency"
+
+	"github.com/bhojpur/finance/pkg/enums/recasttype"
+
+	"github.com/bhojpur/finance/pkg/enums/stubtype"
 )
 
 // Config is used to store details used in generation of amortization table.
 type Config struct {
 	StartDate              time.Time          // Starting day of the amortization schedule(inclusive)
 	EndDate                time.Time          // Ending day of the amortization schedule(inclusive)
-	Frequency              frequency.Type     // Frequency enum with DAILY, WEEKLY, MONTHLY or ANNUALLY
+	Frequency              frequency.Type     // Frequency enum with DAILY, WEEKLY, FORTNIGHTLY, MONTHLY, QUARTERLY, HALF_YEARLY or ANNUALLY
 	AmountBorrowed         decimal.Decimal    // Amount Borrowed
 	InterestType           interesttype.Type  // InterestType enum with FLAT or REDUCING value.
 	Interest               decimal.Decimal    // Interest in basis points
@@ -44,12 +49,30 @@ type Config struct {
 	EnableRounding         bool               // If enabled, the final values in amortization schedule are rounded
 	RoundingPlaces         int32              // If specified, the final values in amortization schedule are rounded to these many places
 	RoundingErrorTolerance decimal.Decimal    // Any difference in [payment-(principal+interest)] will be adjusted in interest component, upto the RoundingErrorTolerance value specified
+	FirstPaymentDate       time.Time          // If set, the end of the first period. Payments fall due whole periods apart from it and broken periods become stubs instead of failing with ErrUnevenEndDate
+	BackStub               stubtype.Type      // Whether a broken period at the end is paid as a SHORT period of its own (the default) or as part of a LONG last period
+	DayCount               string             // If set, a day count convention of the daycount package, e.g. ACT365. Each period then accrues interest for the time between its dates instead of a fixed share of the year
+	Prepayments            []Prepayment       // Principal repaid ahead of the schedule, including foreclosures
+	Recast                 recasttype.Type    // Whether prepayments and rate resets keep the payment and change the tenor (KEEP_PAYMENT) or keep the tenor and change the payment (KEEP_TENOR). Unless set, prepayments keep the payment and rate resets the tenor. Schedules never run past the end date, so KEEP_PAYMENT fails with ErrRecast when a rate rise means the payment no longer repays the loan in time
+	RateResets             []RateReset        // Benchmark rates of a floating rate loan. If set, Interest is the rate until the first reset
+	Spread                 decimal.Decimal    // Spread in basis points over the benchmark rate
+	ResetFrequency         frequency.Type     // How often the rate is reset, counting from the start date. If not set, it is reset every period
+	RateCap                decimal.Decimal    // If positive, the highest rate in basis points resets may set
+	RateFloor              decimal.Decimal    // If positive, the lowest rate in basis points resets may set
+	MaxRateChange          decimal.Decimal    // If positive, the most a single reset may change the rate by in basis points
+	MaxPeriods             int64              // If positive, the most periods NewAmortization accepts. Longer schedules fail with ErrTooManyPeriods before their dates are derived
 	periods                int64              // derived
 	startDates             []time.Time        // derived
 	endDates               []time.Time        // derived
+	interestFactors        []decimal.Decimal  // derived, the share of a regular period's interest due in each period. Only set with stubs.
+	yearFractions          []decimal.Decimal  // derived, the years each period accrues interest for. Only set with a day count.
+	annualRates            []decimal.Decimal  // derived, the annual interest rate in force in each period.
 }
 
 func (c *Config) setPeriodsAndDates() error {
+	if !c.FirstPaymentDate.IsZero() {
+		return c.setStubbedPeriodsAndDates()
+	}
 	sy, sm, sd := c.StartDate.Date()
 	startDate := time.Date(sy, sm, sd, 0, 0, 0, 0, c.StartDate.Location())
 
@@ -60,6 +83,9 @@ func (c *Config) setPeriodsAndDates() error {
 	if err != nil {
 		return err
 	}
+	if c.MaxPeriods > 0 && int64(period) > c.MaxPeriods {
+		return c.tooManyPeriods()
+	}
 	c.periods = int64(period)
 	for i := 0; i < period; i++ {
 		date, err := getStartDate(startDate, c.Frequency, i)
@@ -80,6 +106,133 @@ func (c *Config) setPeriodsAndDates() error {
 	return nil
 }
 
+// tooManyPeriods is the error of schedules with more than MaxPeriods periods
+func (c *Config) tooManyPeriods() error {
+	return fmt.Errorf("%w: the schedule has more than %d periods", ErrTooManyPeriods, c.MaxPeriods)
+}
+
+// Periods returns the number of periods of the schedule. It's known once the config was passed to NewAmortization.
+func (c *Config) Periods() int64 {
+	return c.periods
+}
+
+// setStubbedPeriodsAndDates derives the periods from the first payment date. The first period runs from
+// the start date to the first payment date, which makes it a short or long front stub unless it spans
+// exactly one period. A broken period after the last regular payment date is a back stub. Stubs pay
+// interest pro-rated by the number of days they span compared to the regular period they replace.
+func (c *Config) setStubbedPeriodsAndDates() error {
+	start, end, first := dateOf(c.StartDate), dateOf(c.EndDate), dateOf(c.FirstPaymentDate)
+	if !first.After(start) || first.After(end) {
+		return ErrFirstPaymentDate
+	}
+
+	// ends are the last days of the periods, nominal[i] and nominal[i+1] bound the regular period of the i-th period
+	var ends, nominal []time.Time
+	prev, err := AddPeriods(first, c.Frequency, -1)
+	if err != nil {
+		return err
+	}
+	nominal = append(nominal, prev)
+	for n := 0; ; n++ {
+		date, err := AddPeriods(first, c.Frequency, n)
+		if err != nil {
+			return err
+		}
+		if date.After(end) {
+			if c.BackStub == stubtype.LONG {
+				ends[len(ends)-1] = end
+			} else {
+				ends = append(ends, end)
+				nominal = append(nominal, date)
+			}
+			break
+		}
+		ends = append(ends, date)
+		nominal = append(nominal, date)
+		if date.Equal(end) {
+			break
+		}
+		if c.MaxPeriods > 0 && int64(len(ends)) > c.MaxPeriods {
+			// stop early, the schedule only gets longer
+			return c.tooManyPeriods()
+		}
+	}
+	if c.MaxPeriods > 0 && int64(len(ends)) > c.MaxPeriods {
+		return c.tooManyPeriods()
+	}
+
+	c.periods = int64(len(ends))
+	c.startDates, c.endDates, c.interestFactors = nil, nil, nil
+	periodStart := start
+	for i, e := range ends {
+		if i == 0 {
+			c.startDates = append(c.startDates, c.StartDate)
+		} else {
+			c.startDates = append(c.startDates, time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day(), 0, 0, 0, 0, c.StartDate.Location()))
+		}
+		c.endDates = append(c.endDates, time.Date(e.Year(), e.Month(), e.Day(), 23, 59, 59, 0, c.StartDate.Location()))
+
+		actual := daysBetween(periodStart.AddDate(0, 0, -1), e)
+		regular := daysBetween(nominal[i], nominal[i+1])
+		c.interestFactors = append(c.interestFactors, decimal.NewFromInt(actual).Div(decimal.NewFromInt(regular)))
+		periodStart = e.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// interestFactor returns the share of a regular period's interest due in a period which is a stub
+func (c *Config) interestFactor(period int64) (decimal.Decimal, bool) {
+	if period < 1 || period > int64(len(c.interestFactors)) {
+		return decimal.Decimal{}, false
+	}
+	factor := c.interestFactors[period-1]
+	if factor.Equal(decimal.NewFromInt(1)) {
+		return decimal.Decimal{}, false
+	}
+	return factor, true
+}
+
+// AddPeriods moves a date by n periods. Monthly and longer periods keep the day of the month
+// where possible and fall on the last day of shorter months otherwise.
+func AddPeriods(date time.Time, freq frequency.Type, n int) (time.Time, error) {
+	var months int
+	switch freq {
+	case frequency.DAILY:
+		return date.AddDate(0, 0, n), nil
+	case frequency.WEEKLY:
+		return date.AddDate(0, 0, 7*n), nil
+	case frequency.FORTNIGHTLY:
+		return date.AddDate(0, 0, 14*n), nil
+	case frequency.MONTHLY:
+		months = n
+	case frequency.QUARTERLY:
+		months = 3 * n
+	case frequency.HALF_YEARLY:
+		months = 6 * n
+	case frequency.ANNUALLY:
+		months = 12 * n
+	default:
+		return time.Time{}, ErrInvalidFrequency
+	}
+	firstOfMonth := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()).AddDate(0, months, 0)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1), nil
+}
+
+// dateOf returns the day of t at midnight UTC, which makes day arithmetic immune to DST changes
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of days from one date to another
+func daysBetween(from, to time.Time) int64 {
+	return int64(dateOf(to).Sub(dateOf(from)).Hours() / 24)
+}
+
 func GetPeriodDifference(from time.Time, to time.Time, freq frequency.Type) (int, error) {
 	var periods int
 	switch freq {
@@ -91,12 +244,30 @@ func GetPeriodDifference(from time.Time, to time.Time, freq frequency.Type) (int
 			return -1, ErrUnevenEndDate
 		}
 		periods = days / 7
+	case frequency.FORTNIGHTLY:
+		days := int(to.Sub(from).Hours()/24) + 1
+		if days%14 != 0 {
+			return -1, ErrUnevenEndDate
+		}
+		periods = days / 14
 	case frequency.MONTHLY:
-		months, err := getMonthsBetweenDates(from, to)
+		months, err := getMonthsBetweenDates(from, to, 1)
 		if err != nil {
 			return -1, err
 		}
 		periods = *months
+	case frequency.QUARTERLY:
+		quarters, err := getMonthsBetweenDates(from, to, 3)
+		if err != nil {
+			return -1, err
+		}
+		periods = *quarters
+	case frequency.HALF_YEARLY:
+		halfYears, err := getMonthsBetweenDates(from, to, 6)
+		if err != nil {
+			return -1, err
+		}
+		periods = *halfYears
 	case frequency.ANNUALLY:
 		years, err := getYearsBetweenDates(from, to)
 		if err != nil {
@@ -116,8 +287,14 @@ func getStartDate(date time.Time, freq frequency.Type, index int) (time.Time, er
 		startDate = date.AddDate(0, 0, index)
 	case frequency.WEEKLY:
 		startDate = date.AddDate(0, 0, 7*index)
+	case frequency.FORTNIGHTLY:
+		startDate = date.AddDate(0, 0, 14*index)
 	case frequency.MONTHLY:
 		startDate = date.AddDate(0, index, 0)
+	case frequency.QUARTERLY:
+		startDate = date.AddDate(0, 3*index, 0)
+	case frequency.HALF_YEARLY:
+		startDate = date.AddDate(0, 6*index, 0)
 	case frequency.ANNUALLY:
 		startDate = date.AddDate(index, 0, 0)
 	default:
@@ -126,10 +303,11 @@ func getStartDate(date time.Time, freq frequency.Type, index int) (time.Time, er
 	return startDate, nil
 }
 
-func getMonthsBetweenDates(start time.Time, end time.Time) (*int, error) {
+// getMonthsBetweenDates counts the periods of the given number of months between start and end
+func getMonthsBetweenDates(start time.Time, end time.Time, months int) (*int, error) {
 	count := 0
 	for start.Before(end) {
-		start = start.AddDate(0, 1, 0)
+		start = start.AddDate(0, months, 0)
 		count++
 	}
 	finalDate := start.AddDate(0, 0, -1)
@@ -160,9 +338,18 @@ func getEndDates(date time.Time, freq frequency.Type) (time.Time, error) {
 	case frequency.WEEKLY:
 		date = date.AddDate(0, 0, 6)
 		nextDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	case frequency.FORTNIGHTLY:
+		date = date.AddDate(0, 0, 13)
+		nextDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
 	case frequency.MONTHLY:
 		date = date.AddDate(0, 1, 0).AddDate(0, 0, -1)
 		nextDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	case frequency.QUARTERLY:
+		date = date.AddDate(0, 3, 0).AddDate(0, 0, -1)
+		nextDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	case frequency.HALF_YEARLY:
+		date = date.AddDate(0, 6, 0).AddDate(0, 0, -1)
+		nextDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
 	case frequency.ANNUALLY:
 		date = date.AddDate(1, 0, 0).AddDate(0, 0, -1)
 		nextDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
@@ -25,7 +25,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	Frequency "github.com/bhojpur/finance/pkg/enums/frequency"
+	"github.com/bhojpur/finance/pkg/enums/stubtype"
 )
 
 type dateGroup struct {
@@ -216,6 +219,48 @@ func TestConfig_SetPeriodsAndDates(t *testing.T) {
 				{timeParseUtil(t, "2021-05-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-04-30 23:59:59 +0000 UTC")},
 			},
 		},
+		{
+			name: "fortnightly", fields: fields{getDate(2020, 1, 1), getDate(2020, 2, 25), Frequency.FORTNIGHTLY}, wantErr: false,
+			wantPeriods: 4, wantDates: []dateGroup{
+				{timeParseUtil(t, "2020-01-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-01-14 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-01-15 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-01-28 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-01-29 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-02-11 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-02-12 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-02-25 23:59:59 +0000 UTC")},
+			},
+		},
+		{
+			name: "fortnightly uneven", fields: fields{getDate(2020, 1, 1), getDate(2020, 1, 21), Frequency.FORTNIGHTLY}, wantErr: true,
+		},
+		{
+			name: "quarterly", fields: fields{getDate(2020, 4, 1), getDate(2021, 3, 31), Frequency.QUARTERLY}, wantErr: false,
+			wantPeriods: 4, wantDates: []dateGroup{
+				{timeParseUtil(t, "2020-04-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-06-30 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-07-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-09-30 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-10-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-12-31 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2021-01-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2021-03-31 23:59:59 +0000 UTC")},
+			},
+		},
+		{
+			name: "quarterly uneven", fields: fields{getDate(2020, 4, 1), getDate(2021, 4, 30), Frequency.QUARTERLY}, wantErr: true,
+		},
+		{
+			name: "half-yearly", fields: fields{getDate(2020, 4, 1), getDate(2021, 9, 30), Frequency.HALF_YEARLY}, wantErr: false,
+			wantPeriods: 3, wantDates: []dateGroup{
+				{timeParseUtil(t, "2020-04-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-09-30 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-10-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2021-03-31 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2021-04-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2021-09-30 23:59:59 +0000 UTC")},
+			},
+		},
+		{
+			name: "half-yearly financial year", fields: fields{getDate(2020, 4, 1), getDate(2021, 3, 31), Frequency.HALF_YEARLY}, wantErr: false,
+			wantPeriods: 2, wantDates: []dateGroup{
+				{timeParseUtil(t, "2020-04-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2020-09-30 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2020-10-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2021-03-31 23:59:59 +0000 UTC")},
+			},
+		},
+		{
+			name: "half-yearly uneven", fields: fields{getDate(2020, 4, 1), getDate(2020, 12, 31), Frequency.HALF_YEARLY}, wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -237,6 +282,111 @@ func TestConfig_SetPeriodsAndDates(t *testing.T) {
 	}
 }
 
+func TestConfig_Stubs(t *testing.T) {
+	tests := []struct {
+		name        string
+		start       time.Time
+		first       time.Time
+		end         time.Time
+		frequency   Frequency.Type
+		backStub    stubtype.Type
+		wantErr     error
+		wantDates   []dateGroup
+		wantFactors [][2]int64
+	}{
+		{
+			name: "short front and back stub", start: getDate(2022, 1, 15), first: getDate(2022, 1, 31), end: getDate(2022, 4, 15), frequency: Frequency.MONTHLY,
+			wantDates: []dateGroup{
+				{timeParseUtil(t, "2022-01-15 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-01-31 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-02-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-02-28 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-03-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-03-31 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-04-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-04-15 23:59:59 +0000 UTC")},
+			},
+			wantFactors: [][2]int64{{17, 31}, {1, 1}, {1, 1}, {15, 30}},
+		},
+		{
+			name: "long front and back stub", start: getDate(2021, 12, 20), first: getDate(2022, 1, 31), end: getDate(2022, 4, 15), frequency: Frequency.MONTHLY, backStub: stubtype.LONG,
+			wantDates: []dateGroup{
+				{timeParseUtil(t, "2021-12-20 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-01-31 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-02-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-02-28 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-03-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-04-15 23:59:59 +0000 UTC")},
+			},
+			wantFactors: [][2]int64{{43, 31}, {1, 1}, {46, 31}},
+		},
+		{
+			name: "no stubs at month ends", start: getDate(2021, 11, 1), first: getDate(2022, 1, 31), end: getDate(2022, 7, 31), frequency: Frequency.QUARTERLY,
+			wantDates: []dateGroup{
+				{timeParseUtil(t, "2021-11-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-01-31 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-02-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-04-30 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-05-01 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-07-31 23:59:59 +0000 UTC")},
+			},
+			wantFactors: [][2]int64{{1, 1}, {1, 1}, {1, 1}},
+		},
+		{
+			name: "weekly short front stub", start: getDate(2022, 1, 5), first: getDate(2022, 1, 7), end: getDate(2022, 1, 14), frequency: Frequency.WEEKLY,
+			wantDates: []dateGroup{
+				{timeParseUtil(t, "2022-01-05 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-01-07 23:59:59 +0000 UTC")},
+				{timeParseUtil(t, "2022-01-08 00:00:00 +0000 UTC"), timeParseUtil(t, "2022-01-14 23:59:59 +0000 UTC")},
+			},
+			wantFactors: [][2]int64{{3, 7}, {1, 1}},
+		},
+		{
+			name: "first payment on start date", start: getDate(2022, 1, 15), first: getDate(2022, 1, 15), end: getDate(2022, 4, 15), frequency: Frequency.MONTHLY,
+			wantErr: ErrFirstPaymentDate,
+		},
+		{
+			name: "first payment after end date", start: getDate(2022, 1, 15), first: getDate(2022, 5, 15), end: getDate(2022, 4, 15), frequency: Frequency.MONTHLY,
+			wantErr: ErrFirstPaymentDate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				StartDate:        tt.start,
+				EndDate:          tt.end,
+				FirstPaymentDate: tt.first,
+				Frequency:        tt.frequency,
+				BackStub:         tt.backStub,
+			}
+			err := c.setPeriodsAndDates()
+			if err != tt.wantErr {
+				t.Fatalf("SetPeriodsAndDates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if c.periods != int64(len(tt.wantDates)) {
+				t.Fatalf("want periods: %v, got periods:%v", len(tt.wantDates), c.periods)
+			}
+			if err := areDatesEqual(c.startDates, c.endDates, tt.wantDates); err != nil {
+				t.Fatalf("dates are not equal. error:%v", err)
+			}
+			for i, f := range tt.wantFactors {
+				want := decimal.NewFromInt(f[0]).Div(decimal.NewFromInt(f[1]))
+				if !c.interestFactors[i].Equal(want) {
+					t.Errorf("period %d: want interest factor %v, got %v", i+1, want, c.interestFactors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_InterestRatePerPeriod(t *testing.T) {
+	tests := []struct {
+		frequency Frequency.Type
+		want      string
+	}{
+		{Frequency.MONTHLY, "0.01"},
+		{Frequency.QUARTERLY, "0.03"},
+		{Frequency.HALF_YEARLY, "0.06"},
+		{Frequency.ANNUALLY, "0.12"},
+		{Frequency.FORTNIGHTLY, "0.0046153846153846"},
+	}
+	for _, tt := range tests {
+		c := &Config{Frequency: tt.frequency, Interest: decimal.NewFromInt(1200)}
+		if got := c.getInterestRatePerPeriodInDecimal().String(); got != tt.want {
+			t.Errorf("frequency %v: want rate per period %s, got %s", tt.frequency, tt.want, got)
+		}
+	}
+}
+
 func areDatesEqual(actualStartDates []time.Time, actualEndDates []time.Time, expected []dateGroup) error {
 	for idx := range expected {
 		if !actualStartDates[idx].Equal(expected[idx].startDate) || !actualEndDates[idx].Equal(expected[idx].endDate) {
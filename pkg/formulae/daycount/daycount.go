@@ -62,6 +62,18 @@ var conventions = map[string]struct {
 	},
 }
 
+// years converts the time between two dates into years. Besides the
+// conventions above, it knows ACT365 (fixed), which has no coupon
+// fraction of its own.
+var years = map[string]dateDiffFunc{
+	"30E360":    per(days30e360, 360.0),
+	"EUROBOND":  per(eurobond, 360.0),
+	"BONDBASIS": per(bondbasis, 360.0),
+	"ACT360":    per(act, 360.0),
+	"ACT365":    per(act, 365.0),
+	"ACTACT":    actactYears,
+}
+
 // Implemented returns a slice of strings of the implemented day count conventions
 func Implemented() []string {
 	list := []string{}
@@ -110,6 +122,33 @@ func Days(date1, date2 time.Time, basis string) (float64, error) {
 	return conv.Numerator(date1, date2), nil
 }
 
+// ImplementedYearFractions returns a slice of strings of the day count conventions YearFraction implements
+func ImplementedYearFractions() []string {
+	list := []string{}
+	for conv := range years {
+		list = append(list, conv)
+	}
+	return list
+}
+
+// YearFraction returns the time between two dates in years
+func YearFraction(date1, date2 time.Time, basis string) (float64, error) {
+
+	// use default if basis is empty
+	if basis == "" {
+		basis = Default
+	}
+
+	// look for convention
+	year, ok := years[basis]
+	if !ok {
+		return 0.0, fmt.Errorf("day count convention %s not implemented", basis)
+	}
+
+	// calculate year fraction
+	return year(date1, date2), nil
+}
+
 // days30360 is the helper function to calculate the days between two dates for the 30/360 methods
 func days30360(d1, d2 time.Time, day1, day2 int) float64 {
 	return 360.0*float64(d2.Year()-d1.Year()) + 30.0*float64(d2.Month()-d1.Month()) + float64(day2-day1)
@@ -160,3 +199,33 @@ func bondbasis(date1, date2 time.Time) float64 {
 func act(date1, date2 time.Time) float64 {
 	return date2.Sub(date1).Hours() / 24.0
 }
+
+// per divides the days between two dates by a fixed year length
+func per(days dateDiffFunc, year float64) dateDiffFunc {
+	return func(date1, date2 time.Time) float64 {
+		return days(date1, date2) / year
+	}
+}
+
+// actactYears splits the time between two dates by calendar year and
+// divides the days of each part by the length of its year (ISDA)
+func actactYears(date1, date2 time.Time) float64 {
+	if date2.Before(date1) {
+		return -actactYears(date2, date1)
+	}
+	years := 0.0
+	for date1.Year() < date2.Year() {
+		next := time.Date(date1.Year()+1, 1, 1, 0, 0, 0, 0, date1.Location())
+		years += act(date1, next) / daysInYear(date1.Year())
+		date1 = next
+	}
+	return years + act(date1, date2)/daysInYear(date1.Year())
+}
+
+// daysInYear returns 366 for leap years and 365 otherwise
+func daysInYear(year int) float64 {
+	if time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+		return 366.0
+	}
+	return 365.0
+}
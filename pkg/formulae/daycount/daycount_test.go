@@ -97,3 +97,36 @@ func TestDayCountFraction_MissingConvention(t *testing.T) {
 		t.Errorf("day count fraction should return an error when basis is not implemented")
 	}
 }
+
+func TestDayCountYearFraction(t *testing.T) {
+	testData := []struct {
+		Date1    time.Time
+		Date2    time.Time
+		Basis    string
+		Expected float64
+	}{
+		{Date1: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Basis: "30E360", Expected: 30.0 / 360.0},
+		{Date1: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Basis: "", Expected: 30.0 / 360.0},
+		{Date1: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Basis: "ACT360", Expected: 31.0 / 360.0},
+		{Date1: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Basis: "ACT365", Expected: 31.0 / 365.0},
+		{Date1: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), Basis: "ACTACT", Expected: 29.0 / 366.0},
+		{Date1: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Basis: "ACTACT", Expected: 31.0/366.0 + 31.0/365.0},
+		{Date1: time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC), Date2: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), Basis: "ACTACT", Expected: 184.0/365.0 + 1.0 + 181.0/365.0},
+	}
+
+	tolerance := 1e-12
+	for nr, test := range testData {
+		frac, err := daycount.YearFraction(test.Date1, test.Date2, test.Basis)
+		if err != nil || math.Abs(frac-test.Expected) > tolerance {
+			t.Errorf("test %d for %s failed, got: %f, %v, want: %f", nr, test.Basis, frac, err, test.Expected)
+		}
+	}
+
+	if _, err := daycount.YearFraction(testData[0].Date1, testData[0].Date2, "THISISNOTIMPLEMENTED"); err == nil {
+		t.Errorf("year fraction should return an error when basis is not implemented")
+	}
+	// ACT365 counts years only, coupon fractions don't know it
+	if _, err := daycount.Fraction(testData[0].Date1, testData[0].Date2, testData[0].Date2, "ACT365"); err == nil {
+		t.Errorf("day count fraction should return an error for ACT365")
+	}
+}
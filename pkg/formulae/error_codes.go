@@ -26,6 +26,12 @@ var (
 	ErrPayment          = errors.New("payment not matching interest plus principal")
 	ErrUnevenEndDate    = errors.New("uneven end date")
 	ErrInvalidFrequency = errors.New("invalid frequency")
+	ErrFirstPaymentDate = errors.New("first payment date must be after the start date and not after the end date")
+	ErrDayCount         = errors.New("day count convention not implemented")
+	ErrTooManyPeriods   = errors.New("too many periods")
+	ErrPrepayment       = errors.New("invalid prepayment")
+	ErrRateReset        = errors.New("invalid rate reset")
+	ErrRecast           = errors.New("payment no longer repays the loan by the end date")
 	ErrNotEqual         = errors.New("input values are not equal")
 	ErrOutOfBounds      = errors.New("error in representing data as it is out of bounds")
 	ErrTolerence        = errors.New("nan error as tolerence level exceeded")
@@ -86,7 +86,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2010-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-24000000",
-	//		"Principal": "-5364848"
+	//		"Principal": "-5364848",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "194635152"
 	//	},
 	//	{
 	//		"Period": 2,
@@ -94,7 +97,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2011-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-23356218",
-	//		"Principal": "-6008630"
+	//		"Principal": "-6008630",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "188626522"
 	//	},
 	//	{
 	//		"Period": 3,
@@ -102,7 +108,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2012-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-22635183",
-	//		"Principal": "-6729665"
+	//		"Principal": "-6729665",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "181896857"
 	//	},
 	//	{
 	//		"Period": 4,
@@ -110,7 +119,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2013-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-21827623",
-	//		"Principal": "-7537225"
+	//		"Principal": "-7537225",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "174359632"
 	//	},
 	//	{
 	//		"Period": 5,
@@ -118,7 +130,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2014-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-20923156",
-	//		"Principal": "-8441692"
+	//		"Principal": "-8441692",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "165917940"
 	//	},
 	//	{
 	//		"Period": 6,
@@ -126,7 +141,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2015-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-19910153",
-	//		"Principal": "-9454695"
+	//		"Principal": "-9454695",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "156463245"
 	//	},
 	//	{
 	//		"Period": 7,
@@ -134,7 +152,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2016-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-18775589",
-	//		"Principal": "-10589259"
+	//		"Principal": "-10589259",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "145873986"
 	//	},
 	//	{
 	//		"Period": 8,
@@ -142,7 +163,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2017-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-17504878",
-	//		"Principal": "-11859970"
+	//		"Principal": "-11859970",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "134014016"
 	//	},
 	//	{
 	//		"Period": 9,
@@ -150,7 +174,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2018-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-16081682",
-	//		"Principal": "-13283166"
+	//		"Principal": "-13283166",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "120730850"
 	//	},
 	//	{
 	//		"Period": 10,
@@ -158,7 +185,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2019-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-14487702",
-	//		"Principal": "-14877146"
+	//		"Principal": "-14877146",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "105853704"
 	//	},
 	//	{
 	//		"Period": 11,
@@ -166,7 +196,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2020-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-12702445",
-	//		"Principal": "-16662403"
+	//		"Principal": "-16662403",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "89191301"
 	//	},
 	//	{
 	//		"Period": 12,
@@ -174,7 +207,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2021-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-10702956",
-	//		"Principal": "-18661892"
+	//		"Principal": "-18661892",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "70529409"
 	//	},
 	//	{
 	//		"Period": 13,
@@ -182,7 +218,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2022-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-8463529",
-	//		"Principal": "-20901319"
+	//		"Principal": "-20901319",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "49628090"
 	//	},
 	//	{
 	//		"Period": 14,
@@ -190,7 +229,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2023-11-10T23:59:59+05:30",
 	//		"Payment": "-29364848",
 	//		"Interest": "-5955371",
-	//		"Principal": "-23409477"
+	//		"Principal": "-23409477",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "26218613"
 	//	},
 	//	{
 	//		"Period": 15,
@@ -198,7 +240,10 @@ func ExampleAmortization_GenerateTable() {
 	//		"EndDate": "2024-11-10T23:59:59+05:30",
 	//		"Payment": "-29364847",
 	//		"Interest": "-3146234",
-	//		"Principal": "-26218613"
+	//		"Principal": "-26218613",
+	//		"Prepayment": "0",
+	//		"Charge": "0",
+	//		"Balance": "0"
 	//	}
 	// ]
 }
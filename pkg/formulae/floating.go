@@ -0,0 +1,121 @@
+package formulae
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateReset is a benchmark rate, e.g. a repo rate, which applies from its date on.
+type RateReset struct {
+	Date time.Time       // Day the benchmark rate is set
+	Rate decimal.Decimal // Benchmark rate in basis points
+}
+
+// setAnnualRates derives the annual interest rate in force in every period. It is Interest, unless rate resets
+// are set. Then the rate is reset to the latest benchmark rate plus the spread on every reset date and applies
+// from the first period starting on or after it.
+func (c *Config) setAnnualRates() error {
+	if c.RateCap.IsNegative() || c.RateFloor.IsNegative() || c.MaxRateChange.IsNegative() {
+		return ErrRateReset
+	}
+	if c.RateCap.IsPositive() && c.RateFloor.GreaterThan(c.RateCap) {
+		return ErrRateReset
+	}
+
+	dates, err := c.resetDates()
+	if err != nil {
+		return err
+	}
+	resets := append([]RateReset(nil), c.RateResets...)
+	sort.SliceStable(resets, func(i, j int) bool { return resets[i].Date.Before(resets[j].Date) })
+
+	rate := c.Interest
+	c.annualRates = make([]decimal.Decimal, c.periods)
+	for i := range c.annualRates {
+		for len(dates) > 0 && !dates[0].After(dateOf(c.startDates[i])) {
+			if benchmark, ok := benchmarkOn(resets, dates[0]); ok {
+				rate = c.resetRate(rate, benchmark)
+			}
+			dates = dates[1:]
+		}
+		c.annualRates[i] = rate.Div(decimal.NewFromInt(10000))
+	}
+	return nil
+}
+
+// resetDates returns the dates the rate is reset on. These are every ResetFrequency from the start date, or the
+// start of every period after the first if it is not set.
+func (c *Config) resetDates() ([]time.Time, error) {
+	var res []time.Time
+	if len(c.RateResets) == 0 {
+		return res, nil
+	}
+	if c.ResetFrequency == 0 {
+		for i := 1; i < len(c.startDates); i++ {
+			res = append(res, dateOf(c.startDates[i]))
+		}
+		return res, nil
+	}
+	for n := 1; ; n++ {
+		date, err := AddPeriods(dateOf(c.StartDate), c.ResetFrequency, n)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(dateOf(c.EndDate)) {
+			return res, nil
+		}
+		res = append(res, date)
+	}
+}
+
+// resetRate returns the rate in basis points after a reset to the benchmark rate, within the caps and floors.
+func (c *Config) resetRate(rate, benchmark decimal.Decimal) decimal.Decimal {
+	res := benchmark.Add(c.Spread)
+	if c.MaxRateChange.IsPositive() {
+		res = decimal.Min(decimal.Max(res, rate.Sub(c.MaxRateChange)), rate.Add(c.MaxRateChange))
+	}
+	if c.RateCap.IsPositive() {
+		res = decimal.Min(res, c.RateCap)
+	}
+	if c.RateFloor.IsPositive() {
+		res = decimal.Max(res, c.RateFloor)
+	}
+	return res
+}
+
+// benchmarkOn returns the latest benchmark rate set on or before a date. The resets are sorted by date.
+func benchmarkOn(resets []RateReset, date time.Time) (decimal.Decimal, bool) {
+	var (
+		res decimal.Decimal
+		ok  bool
+	)
+	for _, r := range resets {
+		if dateOf(r.Date).After(date) {
+			break
+		}
+		res, ok = r.Rate, true
+	}
+	return res, ok
+}
@@ -0,0 +1,185 @@
+package formulae
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/bhojpur/finance/pkg/enums/frequency"
+	"github.com/bhojpur/finance/pkg/enums/interesttype"
+	"github.com/bhojpur/finance/pkg/enums/recasttype"
+)
+
+// floatingConfig resets the loan quarterly to the benchmark plus 3%, from mid-March on
+func floatingConfig(recast recasttype.Type, benchmark int64) *Config {
+	c := getLoanConfig(interesttype.REDUCING, recast)
+	c.RateResets = []RateReset{
+		{Date: time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC), Rate: decimal.NewFromInt(benchmark)},
+		{Date: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), Rate: decimal.NewFromInt(900)},
+	}
+	c.Spread = decimal.NewFromInt(300)
+	c.ResetFrequency = frequency.QUARTERLY
+	return c
+}
+
+func TestAmortization_GenerateTableWithRateResets(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		// the interest of the first period after the reset, the payment in the quarter after it and the last payment
+		wantInterest, wantPayment, wantLast string
+		wantErr                             error
+	}{
+		{
+			name:         "rise keeping the tenor",
+			config:       floatingConfig(recasttype.KEEP_TENOR, 950),
+			wantInterest: "-792.79",
+			wantPayment:  "-8902.97",
+			wantLast:     "-8902.98",
+		},
+		{
+			// keeping the payment would fail on any rate rise, unless set, rate resets keep the tenor
+			name:         "rise by default",
+			config:       floatingConfig(0, 950),
+			wantInterest: "-792.79",
+			wantPayment:  "-8902.97",
+			wantLast:     "-8902.98",
+		},
+		{
+			name:    "rise keeping the payment",
+			config:  floatingConfig(recasttype.KEEP_PAYMENT, 950),
+			wantErr: ErrRecast,
+		},
+		{
+			name:         "fall keeping the tenor",
+			config:       floatingConfig(recasttype.KEEP_TENOR, 600),
+			wantInterest: "-570.81",
+			wantPayment:  "-8776.72",
+			wantLast:     "-8776.73",
+		},
+		{
+			name:         "fall keeping the payment",
+			config:       floatingConfig(recasttype.KEEP_PAYMENT, 600),
+			wantInterest: "-570.81",
+			wantPayment:  "-8884.88",
+			wantLast:     "-7881.76",
+		},
+		{
+			name: "change capped",
+			config: func() *Config {
+				c := floatingConfig(recasttype.KEEP_TENOR, 950)
+				c.MaxRateChange = decimal.NewFromInt(25)
+				return c
+			}(),
+			wantInterest: "-776.93",
+			wantPayment:  "-8893.92",
+			wantLast:     "-8900.31",
+		},
+		{
+			name: "rate capped",
+			config: func() *Config {
+				c := floatingConfig(recasttype.KEEP_TENOR, 950)
+				c.RateCap = decimal.NewFromInt(1220)
+				return c
+			}(),
+			wantInterest: "-773.76",
+			wantPayment:  "-8892.11",
+			wantLast:     "-8892.11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAmortization(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, err := a.GenerateTable()
+			if tt.wantErr != nil || err != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if len(rows) != 12 {
+				t.Fatalf("want 12 rows, got %d", len(rows))
+			}
+			// the benchmark rises or falls before the reset on the 1st of April
+			for i := 0; i < 3; i++ {
+				if got := rows[i].Payment.String(); got != "-8884.88" {
+					t.Errorf("period %d: want payment -8884.88 before the reset, got %s", i+1, got)
+				}
+			}
+			if got := rows[3].Interest.String(); got != tt.wantInterest {
+				t.Errorf("want interest %s after the reset, got %s", tt.wantInterest, got)
+			}
+			for i := 3; i < 6; i++ {
+				if got := rows[i].Payment.String(); got != tt.wantPayment {
+					t.Errorf("period %d: want payment %s after the reset, got %s", i+1, tt.wantPayment, got)
+				}
+			}
+			if got := rows[11].Payment.String(); got != tt.wantLast {
+				t.Errorf("want a last payment of %s, got %s", tt.wantLast, got)
+			}
+			if !rows[11].Balance.IsZero() {
+				t.Errorf("want the loan repaid, got a balance of %v", rows[11].Balance)
+			}
+		})
+	}
+}
+
+func TestAmortization_GenerateTableWithMonthlyRateResets(t *testing.T) {
+	c := floatingConfig(recasttype.KEEP_TENOR, 950)
+	c.ResetFrequency = 0
+	c.RateResets = append(c.RateResets, RateReset{Date: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), Rate: decimal.NewFromInt(1000)})
+	a, err := NewAmortization(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1200", "1300", "1300", "1250", "1250"}
+	for i, w := range want {
+		if got := a.Config.annualRates[i].Mul(decimal.NewFromInt(10000)).String(); got != w {
+			t.Errorf("period %d: want a rate of %s, got %s", i+1, w, got)
+		}
+	}
+}
+
+func TestAmortization_InvalidRateResets(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{name: "floor above cap", modify: func(c *Config) { c.RateFloor, c.RateCap = decimal.NewFromInt(900), decimal.NewFromInt(800) }},
+		{name: "negative change", modify: func(c *Config) { c.MaxRateChange = decimal.NewFromInt(-1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := floatingConfig(recasttype.KEEP_TENOR, 950)
+			tt.modify(c)
+			if _, err := NewAmortization(c); !errors.Is(err, ErrRateReset) {
+				t.Errorf("want %v, got %v", ErrRateReset, err)
+			}
+		})
+	}
+}
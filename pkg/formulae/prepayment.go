@@ -0,0 +1,206 @@
+package formulae
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/bhojpur/finance/pkg/enums/interesttype"
+	"github.com/bhojpur/finance/pkg/enums/paymentperiod"
+	"github.com/bhojpur/finance/pkg/enums/recasttype"
+)
+
+// Prepayment is a repayment of principal ahead of the schedule. It takes effect after the payment of the
+// period it falls in.
+type Prepayment struct {
+	Date      time.Time       // Day the prepayment is made
+	Amount    decimal.Decimal // Principal prepaid. Amounts beyond the outstanding balance close the loan
+	Foreclose bool            // If set, the whole outstanding balance is repaid and Amount is ignored
+	Charge    decimal.Decimal // Charge in basis points of the principal prepaid, e.g. a foreclosure charge
+}
+
+// validatePrepayments checks that every prepayment falls within the schedule and prepays a positive amount.
+func (c *Config) validatePrepayments() error {
+	start, end := dateOf(c.StartDate), dateOf(c.EndDate)
+	for _, p := range c.Prepayments {
+		date := dateOf(p.Date)
+		switch {
+		case date.Before(start) || date.After(end):
+			return fmt.Errorf("%w: %s is not within the schedule", ErrPrepayment, date.Format("2006-01-02"))
+		case !p.Foreclose && !p.Amount.IsPositive():
+			return fmt.Errorf("%w: the prepayment on %s must be positive", ErrPrepayment, date.Format("2006-01-02"))
+		case p.Charge.IsNegative():
+			return fmt.Errorf("%w: the charge on %s must not be negative", ErrPrepayment, date.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// scheduled holds the amounts of a period of a simulated schedule. All amounts are positive.
+type scheduled struct {
+	payment    decimal.Decimal
+	principal  decimal.Decimal
+	interest   decimal.Decimal
+	prepayment decimal.Decimal
+	charge     decimal.Decimal
+}
+
+// simulate runs the schedule period by period on the outstanding balance, which supports a different rate
+// in every period, rate resets and prepayments. Reducing loans pay a level instalment which clears the balance
+// by the end of the schedule at the rate in force. Flat loans repay equal principal and pay interest on the
+// amount borrowed. After a prepayment, flat loans pay interest on the balance left and the schedule is recast
+// by the Recast policy: KEEP_PAYMENT keeps the instalment, so the loan is repaid early, while KEEP_TENOR spreads
+// the balance left over the remaining periods. Reducing loans are recast at rate resets, too, which keep the tenor
+// unless KEEP_PAYMENT is set.
+func (c *Config) simulate() ([]scheduled, error) {
+	rates := c.periodRates()
+	n := len(rates)
+
+	// regular holds the rate of the interest paid with each payment as if every period were a regular one, which
+	// splits the instalment into interest and principal, and due the rate actually paid. They differ in stubs only,
+	// which pay their principal as scheduled and interest for the days they span, like the closed-form schedule.
+	// A payment at the beginning of a period pays the interest of the period before it.
+	regular, due := make([]decimal.Decimal, n), make([]decimal.Decimal, n)
+	for i := range rates {
+		switch {
+		case c.PaymentPeriod != paymentperiod.BEGINNING:
+			regular[i] = c.regularRate(i, c.annualRates[i])
+		case i > 0:
+			regular[i] = c.regularRate(i-1, c.annualRates[i-1])
+		}
+		due[i] = c.stubRate(i, regular[i])
+	}
+
+	prepayments := append([]Prepayment(nil), c.Prepayments...)
+	sort.SliceStable(prepayments, func(i, j int) bool { return prepayments[i].Date.Before(prepayments[j].Date) })
+
+	flat := c.InterestType == interesttype.FLAT
+	balance, basis := c.AmountBorrowed, c.AmountBorrowed
+	instalment := c.AmountBorrowed.Div(decimal.NewFromInt(int64(n)))
+	if !flat {
+		instalment = annuity(balance, c.projectedDue(0, regular))
+	}
+
+	var result []scheduled
+	for i := 0; i < n && balance.IsPositive(); i++ {
+		if i > 0 && !flat && !c.annualRates[i].Equal(c.annualRates[i-1]) {
+			var err error
+			instalment, err = c.recast(instalment, balance, c.projectedDue(i, regular), true)
+			if err != nil {
+				return nil, fmt.Errorf("%w after the rate reset in the period from %s", err, c.startDates[i].Format("2006-01-02"))
+			}
+		}
+		var s scheduled
+		if flat {
+			s.interest = basis.Mul(rates[i])
+			s.principal = instalment
+		} else {
+			s.interest = balance.Mul(due[i])
+			s.principal = instalment.Sub(balance.Mul(regular[i]))
+		}
+		if i == n-1 || s.principal.GreaterThan(balance) {
+			s.principal = balance
+		}
+		s.payment = s.principal.Add(s.interest)
+		balance = balance.Sub(s.principal)
+
+		end := dateOf(c.endDates[i])
+		for len(prepayments) > 0 && !dateOf(prepayments[0].Date).After(end) {
+			p := prepayments[0]
+			prepayments = prepayments[1:]
+			amount := decimal.Min(p.Amount, balance)
+			if p.Foreclose {
+				amount = balance
+			}
+			balance = balance.Sub(amount)
+			s.prepayment = s.prepayment.Add(amount)
+			s.charge = s.charge.Add(amount.Mul(p.Charge).Div(decimal.NewFromInt(10000)))
+		}
+		if s.prepayment.IsPositive() && balance.IsPositive() {
+			basis = balance
+			switch {
+			case !flat:
+				var err error
+				instalment, err = c.recast(instalment, balance, c.projectedDue(i+1, regular), false)
+				if err != nil {
+					return nil, err
+				}
+			case c.keepTenor(false):
+				instalment = balance.Div(decimal.NewFromInt(int64(n - i - 1)))
+			}
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// projectedDue returns the regular rates of the interest paid with the payments from a period on, assuming that
+// the rate in force in the period holds for the rest of the schedule.
+func (c *Config) projectedDue(period int, regular []decimal.Decimal) []decimal.Decimal {
+	res := []decimal.Decimal{regular[period]}
+	for i := period + 1; i < len(regular); i++ {
+		if c.PaymentPeriod == paymentperiod.BEGINNING {
+			res = append(res, c.regularRate(i-1, c.annualRates[period]))
+		} else {
+			res = append(res, c.regularRate(i, c.annualRates[period]))
+		}
+	}
+	return res
+}
+
+// keepTenor returns true if the schedule is recast keeping its tenor after a rate reset or a prepayment. Unless
+// a Recast policy is set, rate resets keep the tenor, as keeping the payment fails on any rate rise, while
+// prepayments keep the payment.
+func (c *Config) keepTenor(reset bool) bool {
+	if c.Recast == 0 {
+		return reset
+	}
+	return c.Recast == recasttype.KEEP_TENOR
+}
+
+// recast returns the instalment which repays the balance with the payments left at the given rates after a rate
+// reset or a prepayment. Keeping the payment keeps the current instalment and fails with ErrRecast if it no longer
+// repays the balance by the end of the schedule.
+func (c *Config) recast(instalment, balance decimal.Decimal, due []decimal.Decimal, reset bool) (decimal.Decimal, error) {
+	required := annuity(balance, due)
+	if c.keepTenor(reset) {
+		return required, nil
+	}
+	if required.GreaterThan(instalment) {
+		return decimal.Decimal{}, fmt.Errorf("%w: it takes a payment of %s instead of %s", ErrRecast, required.StringFixed(2), instalment.StringFixed(2))
+	}
+	return instalment, nil
+}
+
+// annuity returns the level instalment which repays amount over periods paying interest at the given rates
+func annuity(amount decimal.Decimal, rates []decimal.Decimal) decimal.Decimal {
+	one := decimal.NewFromInt(1)
+	sum, discount := decimal.Zero, one
+	for _, rate := range rates {
+		discount = discount.Div(one.Add(rate))
+		sum = sum.Add(discount)
+	}
+	return amount.Div(sum)
+}
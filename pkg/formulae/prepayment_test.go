@@ -0,0 +1,215 @@
+package formulae
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/bhojpur/finance/pkg/enums/interesttype"
+	"github.com/bhojpur/finance/pkg/enums/paymentperiod"
+	"github.com/bhojpur/finance/pkg/enums/recasttype"
+)
+
+func TestAmortization_GenerateTableWithPrepayments(t *testing.T) {
+	partPayment := Prepayment{Date: time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(20000)}
+	foreclosure := Prepayment{Date: time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC), Foreclose: true, Charge: decimal.NewFromInt(200)}
+	tests := []struct {
+		name         string
+		interestType interesttype.Type
+		recast       recasttype.Type
+		prepayment   Prepayment
+		wantRows     int
+		// level payments before and after the prepayment
+		wantBefore, wantAfter string
+		// the prepayment in the third or the last row
+		wantPrepayment, wantCharge string
+	}{
+		{
+			name:           "keep payment",
+			interestType:   interesttype.REDUCING,
+			recast:         recasttype.KEEP_PAYMENT,
+			prepayment:     partPayment,
+			wantRows:       10,
+			wantBefore:     "-8884.88",
+			wantAfter:      "-8884.88",
+			wantPrepayment: "-20000",
+			wantCharge:     "0",
+		},
+		{
+			name:           "keep tenor",
+			interestType:   interesttype.REDUCING,
+			recast:         recasttype.KEEP_TENOR,
+			prepayment:     partPayment,
+			wantRows:       12,
+			wantBefore:     "-8884.88",
+			wantAfter:      "-6550.07",
+			wantPrepayment: "-20000",
+			wantCharge:     "0",
+		},
+		{
+			name:           "foreclosure",
+			interestType:   interesttype.REDUCING,
+			recast:         recasttype.KEEP_PAYMENT,
+			prepayment:     foreclosure,
+			wantRows:       6,
+			wantBefore:     "-8884.88",
+			wantPrepayment: "-51492.11",
+			wantCharge:     "-1029.84",
+		},
+		{
+			name:           "flat keep tenor",
+			interestType:   interesttype.FLAT,
+			recast:         recasttype.KEEP_TENOR,
+			prepayment:     partPayment,
+			wantRows:       12,
+			wantBefore:     "-9333.33",
+			wantAfter:      "-6661.11",
+			wantPrepayment: "-20000",
+			wantCharge:     "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := getLoanConfig(tt.interestType, tt.recast)
+			c.Prepayments = []Prepayment{tt.prepayment}
+			a, err := NewAmortization(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, err := a.GenerateTable()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != tt.wantRows {
+				t.Fatalf("want %d rows, got %d", tt.wantRows, len(rows))
+			}
+
+			prepaid := rows[2]
+			if tt.wantAfter == "" {
+				prepaid = rows[len(rows)-1]
+			}
+			if prepaid.Prepayment.String() != tt.wantPrepayment || prepaid.Charge.String() != tt.wantCharge {
+				t.Errorf("want prepayment %s and charge %s, got %v and %v", tt.wantPrepayment, tt.wantCharge, prepaid.Prepayment, prepaid.Charge)
+			}
+			if got := rows[0].Payment.String(); got != tt.wantBefore {
+				t.Errorf("want payment %s before the prepayment, got %s", tt.wantBefore, got)
+			}
+			if tt.wantAfter != "" {
+				if got := rows[3].Payment.String(); got != tt.wantAfter {
+					t.Errorf("want payment %s after the prepayment, got %s", tt.wantAfter, got)
+				}
+			}
+
+			// the balance runs down by principal and prepayments to zero
+			balance := c.AmountBorrowed
+			for i, r := range rows {
+				balance = balance.Add(r.Principal).Add(r.Prepayment)
+				if !r.Balance.Equal(balance) {
+					t.Errorf("period %d: want balance %v, got %v", i+1, balance, r.Balance)
+				}
+			}
+			if !balance.IsZero() {
+				t.Errorf("want the loan repaid, got a balance of %v", balance)
+			}
+		})
+	}
+}
+
+func TestAmortization_GenerateTableBeforePrepayment(t *testing.T) {
+	prepayment := Prepayment{Date: time.Date(2022, 10, 20, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(1)}
+	stubbed := func(interestType interesttype.Type, paymentPeriod paymentperiod.Type) *Config {
+		c := getLoanConfig(interestType, recasttype.KEEP_PAYMENT)
+		c.StartDate = time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC)
+		c.FirstPaymentDate = time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+		c.PaymentPeriod = paymentPeriod
+		return c
+	}
+	tests := []struct {
+		name   string
+		config func() *Config
+	}{
+		{name: "reducing", config: func() *Config { return getLoanConfig(interesttype.REDUCING, recasttype.KEEP_PAYMENT) }},
+		{name: "reducing with a front stub", config: func() *Config { return stubbed(interesttype.REDUCING, paymentperiod.ENDING) }},
+		{name: "reducing with a front stub paid in advance", config: func() *Config { return stubbed(interesttype.REDUCING, paymentperiod.BEGINNING) }},
+		{name: "flat with a front stub", config: func() *Config { return stubbed(interesttype.FLAT, paymentperiod.ENDING) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAmortization(tt.config())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := a.GenerateTable()
+			if err != nil {
+				t.Fatal(err)
+			}
+			prepaid := tt.config()
+			prepaid.Prepayments = []Prepayment{prepayment}
+			a, err = NewAmortization(prepaid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := a.GenerateTable()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the prepayment falls in the tenth period, all periods before it are as scheduled
+			if len(got) != len(want) || got[9].Prepayment.IsZero() {
+				t.Fatalf("want the prepayment in the tenth of %d rows, got %v", len(want), got)
+			}
+			for i := range want[:9] {
+				if err := verifyRow(t, got[i], want[i]); err != nil {
+					t.Error(err)
+				}
+				if !got[i].Balance.Equal(want[i].Balance) {
+					t.Errorf("period %d: want balance %v, got %v", i+1, want[i].Balance, got[i].Balance)
+				}
+			}
+		})
+	}
+}
+
+func TestAmortization_InvalidPrepayments(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepayment Prepayment
+	}{
+		{name: "before start", prepayment: Prepayment{Date: time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(1)}},
+		{name: "after end", prepayment: Prepayment{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Foreclose: true}},
+		{name: "no amount", prepayment: Prepayment{Date: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)}},
+		{name: "negative charge", prepayment: Prepayment{Date: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), Foreclose: true, Charge: decimal.NewFromInt(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := getLoanConfig(interesttype.REDUCING, recasttype.KEEP_PAYMENT)
+			c.Prepayments = []Prepayment{tt.prepayment}
+			_, err := NewAmortization(c)
+			if !errors.Is(err, ErrPrepayment) {
+				t.Errorf("want %v, got %v", ErrPrepayment, err)
+			}
+		})
+	}
+}
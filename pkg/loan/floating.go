@@ -0,0 +1,139 @@
+package loan
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/enums/frequency"
+	"github.com/bhojpur/finance/pkg/formulae"
+	"github.com/bhojpur/finance/pkg/formulae/daycount"
+	"github.com/bhojpur/finance/pkg/pricing"
+	"github.com/bhojpur/finance/pkg/securities/term"
+	"github.com/shopspring/decimal"
+)
+
+// setFloatingRate validates the rate resets of a floating rate loan and sets them on the amortization config
+func setFloatingRate(res *formulae.Config, c *v1.LoanConfig) error {
+	var err error
+	for i, r := range c.RateResets {
+		field := fmt.Sprintf("rate_resets[%d]", i)
+		if r == nil {
+			return &FieldError{Field: field, Err: fmt.Errorf("is required")}
+		}
+		var reset formulae.RateReset
+		reset.Date, err = parseDate(field+".date", r.Date)
+		if err != nil {
+			return err
+		}
+		reset.Rate, err = parseDecimal(field+".rate", r.Rate, false)
+		if err != nil {
+			return err
+		}
+		res.RateResets = append(res.RateResets, reset)
+	}
+	res.Spread, err = parseDecimal("spread", c.Spread, true)
+	if err != nil {
+		return err
+	}
+	if c.ResetFrequency != v1.LoanFrequency_FREQUENCY_UNSPECIFIED {
+		var ok bool
+		res.ResetFrequency, ok = frequencies[c.ResetFrequency]
+		if !ok {
+			return &FieldError{Field: "reset_frequency", Err: formulae.ErrInvalidFrequency}
+		}
+	}
+	for _, bound := range []struct {
+		field string
+		value string
+		res   *decimal.Decimal
+	}{
+		{field: "rate_cap", value: c.RateCap, res: &res.RateCap},
+		{field: "rate_floor", value: c.RateFloor, res: &res.RateFloor},
+		{field: "max_rate_change", value: c.MaxRateChange, res: &res.MaxRateChange},
+	} {
+		*bound.res, err = parseDecimal(bound.field, bound.value, true)
+		if err != nil {
+			return err
+		}
+		if bound.res.IsNegative() {
+			return &FieldError{Field: bound.field, Err: fmt.Errorf("must not be negative")}
+		}
+	}
+	if res.RateCap.IsPositive() && res.RateFloor.GreaterThan(res.RateCap) {
+		return &FieldError{Field: "rate_floor", Err: fmt.Errorf("must not be above the rate cap")}
+	}
+
+	if c.BenchmarkCurve == "" {
+		return nil
+	}
+	if len(c.RateResets) > 0 {
+		return &FieldError{Field: "benchmark_curve", Err: fmt.Errorf("must not be set together with rate_resets")}
+	}
+	ts, err := pricing.ParseTermStructure(c.BenchmarkCurve)
+	if err != nil {
+		return &FieldError{Field: "benchmark_curve", Err: err}
+	}
+	freq := res.ResetFrequency
+	if freq == 0 {
+		freq = res.Frequency
+	}
+	res.RateResets, err = ForwardResets(ts, res.StartDate, res.EndDate, freq, MaxPeriods)
+	if err != nil {
+		return &FieldError{Field: "reset_frequency", Err: err}
+	}
+	return nil
+}
+
+// ForwardResets projects the benchmark rates of a floating rate loan from a term structure. There is a reset
+// every period from the start date on, at the simple forward rate until the next one. More than max resets
+// fail with formulae.ErrTooManyPeriods.
+func ForwardResets(ts term.Structure, start, end time.Time, freq frequency.Type, max int) ([]formulae.RateReset, error) {
+	var res []formulae.RateReset
+	for n := 0; ; n++ {
+		from, err := formulae.AddPeriods(start, freq, n)
+		if err != nil {
+			return nil, err
+		}
+		if from.After(end) {
+			return res, nil
+		}
+		if n >= max {
+			return nil, fmt.Errorf("%w: more than %d resets", formulae.ErrTooManyPeriods, max)
+		}
+		to, err := formulae.AddPeriods(start, freq, n+1)
+		if err != nil {
+			return nil, err
+		}
+		t1, err := daycount.YearFraction(start, from, "ACT365")
+		if err != nil {
+			return nil, err
+		}
+		t2, err := daycount.YearFraction(start, to, "ACT365")
+		if err != nil {
+			return nil, err
+		}
+		forward := (ts.Z(t1)/ts.Z(t2) - 1) / (t2 - t1)
+		res = append(res, formulae.RateReset{Date: from, Rate: decimal.NewFromFloat(forward * 10000).Round(2)})
+	}
+}
@@ -0,0 +1,56 @@
+package loan
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/finance/pkg/enums/frequency"
+	"github.com/bhojpur/finance/pkg/formulae"
+	"github.com/bhojpur/finance/pkg/securities/term"
+	"github.com/shopspring/decimal"
+)
+
+func TestForwardResets(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	resets, err := ForwardResets(&term.Flat{R: 6.5}, start, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC), frequency.QUARTERLY, MaxPeriods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resets) != 4 {
+		t.Fatalf("expected 4 resets, got %d", len(resets))
+	}
+	for i, r := range resets {
+		if want := start.AddDate(0, 3*i, 0); !r.Date.Equal(want) {
+			t.Errorf("reset %d: expected a date of %s, got %s", i+1, want.Format(DateLayout), r.Date.Format(DateLayout))
+		}
+		// the simple forward rate of 6.5% continuously compounded over a quarter
+		if r.Rate.LessThan(decimal.NewFromInt(655)) || r.Rate.GreaterThan(decimal.NewFromInt(656)) {
+			t.Errorf("reset %d: expected a rate of about 655 basis points, got %s", i+1, r.Rate)
+		}
+	}
+
+	if _, err := ForwardResets(&term.Flat{R: 6.5}, start, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC), frequency.DAILY, 4); !errors.Is(err, formulae.ErrTooManyPeriods) {
+		t.Errorf("expected %v, got %v", formulae.ErrTooManyPeriods, err)
+	}
+}
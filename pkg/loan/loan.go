@@ -0,0 +1,357 @@
+package loan
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/enums/frequency"
+	"github.com/bhojpur/finance/pkg/enums/interesttype"
+	"github.com/bhojpur/finance/pkg/enums/paymentperiod"
+	"github.com/bhojpur/finance/pkg/enums/recasttype"
+	"github.com/bhojpur/finance/pkg/enums/stubtype"
+	"github.com/bhojpur/finance/pkg/formulae"
+	"github.com/bhojpur/finance/pkg/formulae/daycount"
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DateLayout is the format of all dates of the loan API
+const DateLayout = "2006-01-02"
+
+// MaxPeriods is the longest schedule the service computes, e.g. 100 years of monthly payments.
+// The effort of formulae.Amortization grows faster than the number of periods.
+const MaxPeriods = 1200
+
+// Service implements the LoanService on top of formulae.Amortization
+type Service struct {
+	v1.UnimplementedLoanServiceServer
+}
+
+// NewService produces a new loan service
+func NewService() *Service {
+	return &Service{}
+}
+
+// GenerateSchedule computes the amortization schedule of a loan
+func (srv *Service) GenerateSchedule(ctx context.Context, req *v1.GenerateScheduleRequest) (*v1.GenerateScheduleResponse, error) {
+	cfg, err := NewConfig(req.Config)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	amortization, err := formulae.NewAmortization(cfg)
+	if err != nil {
+		return nil, invalidArgument(ConfigError(err))
+	}
+	rows, err := amortization.GenerateTable()
+	if err != nil {
+		return nil, invalidArgument(ConfigError(err))
+	}
+	return NewScheduleResponse(rows), nil
+}
+
+// FieldError is a configuration error caused by a single field
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError attributes the errors of formulae.NewAmortization and GenerateTable to the fields causing them
+func ConfigError(err error) error {
+	var ferr *FieldError
+	switch {
+	case errors.As(err, &ferr):
+		return err
+	case errors.Is(err, formulae.ErrUnevenEndDate):
+		return &FieldError{Field: "end_date", Err: fmt.Errorf("%w: the schedule must end on the last day of a period unless first_payment_date is set", err)}
+	case errors.Is(err, formulae.ErrTooManyPeriods):
+		return &FieldError{Field: "end_date", Err: err}
+	case errors.Is(err, formulae.ErrFirstPaymentDate):
+		return &FieldError{Field: "first_payment_date", Err: err}
+	case errors.Is(err, formulae.ErrPrepayment):
+		return &FieldError{Field: "prepayments", Err: err}
+	case errors.Is(err, formulae.ErrRateReset):
+		return &FieldError{Field: "rate_resets", Err: err}
+	case errors.Is(err, formulae.ErrRecast):
+		return &FieldError{Field: "recast", Err: err}
+	case errors.Is(err, formulae.ErrDayCount):
+		return &FieldError{Field: "day_count", Err: err}
+	case errors.Is(err, formulae.ErrInvalidFrequency):
+		return &FieldError{Field: "frequency", Err: err}
+	case errors.Is(err, formulae.ErrPayment):
+		return &FieldError{Field: "rounding_error_tolerance", Err: fmt.Errorf("%w beyond the tolerance", err)}
+	default:
+		return err
+	}
+}
+
+// invalidArgument produces an InvalidArgument status which carries a BadRequest detail for field errors
+func invalidArgument(err error) error {
+	s := status.New(codes.InvalidArgument, err.Error())
+	var ferr *FieldError
+	if !errors.As(err, &ferr) {
+		return s.Err()
+	}
+	detailed, derr := s.WithDetails(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+		{Field: "config." + ferr.Field, Description: ferr.Err.Error()},
+	}})
+	if derr != nil {
+		return s.Err()
+	}
+	return detailed.Err()
+}
+
+// frequencies maps the API frequencies to their enums
+var frequencies = map[v1.LoanFrequency]frequency.Type{
+	v1.LoanFrequency_FREQUENCY_DAILY:       frequency.DAILY,
+	v1.LoanFrequency_FREQUENCY_WEEKLY:      frequency.WEEKLY,
+	v1.LoanFrequency_FREQUENCY_MONTHLY:     frequency.MONTHLY,
+	v1.LoanFrequency_FREQUENCY_ANNUALLY:    frequency.ANNUALLY,
+	v1.LoanFrequency_FREQUENCY_FORTNIGHTLY: frequency.FORTNIGHTLY,
+	v1.LoanFrequency_FREQUENCY_QUARTERLY:   frequency.QUARTERLY,
+	v1.LoanFrequency_FREQUENCY_HALF_YEARLY: frequency.HALF_YEARLY,
+}
+
+// NewConfig validates the API form of a loan and converts it to an amortization config
+func NewConfig(c *v1.LoanConfig) (*formulae.Config, error) {
+	if c == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+
+	var (
+		res formulae.Config
+		err error
+	)
+	res.StartDate, err = parseDate("start_date", c.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	res.EndDate, err = parseDate("end_date", c.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if res.EndDate.Before(res.StartDate) {
+		return nil, &FieldError{Field: "end_date", Err: fmt.Errorf("must not be before start_date")}
+	}
+
+	var ok bool
+	res.Frequency, ok = frequencies[c.Frequency]
+	if !ok {
+		return nil, &FieldError{Field: "frequency", Err: formulae.ErrInvalidFrequency}
+	}
+	res.MaxPeriods = MaxPeriods
+	if c.FirstPaymentDate != "" {
+		res.FirstPaymentDate, err = parseDate("first_payment_date", c.FirstPaymentDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch c.BackStub {
+	case v1.BackStub_BACK_STUB_UNSPECIFIED, v1.BackStub_BACK_STUB_SHORT:
+		res.BackStub = stubtype.SHORT
+	case v1.BackStub_BACK_STUB_LONG:
+		res.BackStub = stubtype.LONG
+	default:
+		return nil, &FieldError{Field: "back_stub", Err: fmt.Errorf("invalid back stub %v", c.BackStub)}
+	}
+	if c.DayCount != "" {
+		var known bool
+		for _, b := range daycount.ImplementedYearFractions() {
+			known = known || b == c.DayCount
+		}
+		if !known {
+			return nil, &FieldError{Field: "day_count", Err: fmt.Errorf("%w: %q", formulae.ErrDayCount, c.DayCount)}
+		}
+		res.DayCount = c.DayCount
+	}
+
+	switch c.InterestType {
+	case v1.InterestType_INTEREST_TYPE_FLAT:
+		res.InterestType = interesttype.FLAT
+	case v1.InterestType_INTEREST_TYPE_REDUCING:
+		res.InterestType = interesttype.REDUCING
+	default:
+		return nil, &FieldError{Field: "interest_type", Err: fmt.Errorf("invalid interest type %v", c.InterestType)}
+	}
+
+	switch c.PaymentPeriod {
+	case v1.PaymentPeriod_PAYMENT_PERIOD_BEGINNING:
+		res.PaymentPeriod = paymentperiod.BEGINNING
+	case v1.PaymentPeriod_PAYMENT_PERIOD_UNSPECIFIED, v1.PaymentPeriod_PAYMENT_PERIOD_ENDING:
+		res.PaymentPeriod = paymentperiod.ENDING
+	default:
+		return nil, &FieldError{Field: "payment_period", Err: fmt.Errorf("invalid payment period %v", c.PaymentPeriod)}
+	}
+
+	res.AmountBorrowed, err = parseDecimal("amount_borrowed", c.AmountBorrowed, false)
+	if err != nil {
+		return nil, err
+	}
+	if !res.AmountBorrowed.IsPositive() {
+		return nil, &FieldError{Field: "amount_borrowed", Err: fmt.Errorf("must be positive")}
+	}
+	res.Interest, err = parseDecimal("interest", c.Interest, false)
+	if err != nil {
+		return nil, err
+	}
+	if res.Interest.IsNegative() {
+		return nil, &FieldError{Field: "interest", Err: fmt.Errorf("must not be negative")}
+	}
+	res.RoundingErrorTolerance, err = parseDecimal("rounding_error_tolerance", c.RoundingErrorTolerance, true)
+	if err != nil {
+		return nil, err
+	}
+	if res.RoundingErrorTolerance.IsNegative() {
+		return nil, &FieldError{Field: "rounding_error_tolerance", Err: fmt.Errorf("must not be negative")}
+	}
+
+	for i, p := range c.Prepayments {
+		prepayment, err := newPrepayment(fmt.Sprintf("prepayments[%d]", i), p)
+		if err != nil {
+			return nil, err
+		}
+		res.Prepayments = append(res.Prepayments, prepayment)
+	}
+	switch c.Recast {
+	case v1.RecastPolicy_RECAST_POLICY_UNSPECIFIED:
+		// left unset, the schedule keeps the payment after prepayments and the tenor at rate resets
+	case v1.RecastPolicy_RECAST_POLICY_KEEP_PAYMENT:
+		res.Recast = recasttype.KEEP_PAYMENT
+	case v1.RecastPolicy_RECAST_POLICY_KEEP_TENOR:
+		res.Recast = recasttype.KEEP_TENOR
+	default:
+		return nil, &FieldError{Field: "recast", Err: fmt.Errorf("invalid recast policy %v", c.Recast)}
+	}
+	if err := setFloatingRate(&res, c); err != nil {
+		return nil, err
+	}
+
+	res.EnableRounding = c.EnableRounding
+	res.RoundingPlaces = c.RoundingPlaces
+	if res.RoundingPlaces < 0 {
+		return nil, &FieldError{Field: "rounding_places", Err: fmt.Errorf("must not be negative")}
+	}
+	return &res, nil
+}
+
+// newPrepayment validates the API form of a prepayment. Whether it falls within the schedule is checked by
+// formulae.NewAmortization.
+func newPrepayment(field string, p *v1.LoanPrepayment) (formulae.Prepayment, error) {
+	var (
+		res formulae.Prepayment
+		err error
+	)
+	if p == nil {
+		return res, &FieldError{Field: field, Err: fmt.Errorf("is required")}
+	}
+	res.Date, err = parseDate(field+".date", p.Date)
+	if err != nil {
+		return res, err
+	}
+	res.Foreclose = p.Foreclose
+	res.Amount, err = parseDecimal(field+".amount", p.Amount, p.Foreclose)
+	if err != nil {
+		return res, err
+	}
+	if !p.Foreclose && !res.Amount.IsPositive() {
+		return res, &FieldError{Field: field + ".amount", Err: fmt.Errorf("must be positive")}
+	}
+	res.Charge, err = parseDecimal(field+".charge", p.Charge, true)
+	if err != nil {
+		return res, err
+	}
+	if res.Charge.IsNegative() {
+		return res, &FieldError{Field: field + ".charge", Err: fmt.Errorf("must not be negative")}
+	}
+	return res, nil
+}
+
+func parseDate(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, &FieldError{Field: field, Err: fmt.Errorf("is required")}
+	}
+	res, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, &FieldError{Field: field, Err: fmt.Errorf("%q is not a YYYY-MM-DD date", value)}
+	}
+	return res, nil
+}
+
+func parseDecimal(field, value string, optional bool) (decimal.Decimal, error) {
+	if value == "" {
+		if optional {
+			return decimal.Zero, nil
+		}
+		return decimal.Zero, &FieldError{Field: field, Err: fmt.Errorf("is required")}
+	}
+	res, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, &FieldError{Field: field, Err: fmt.Errorf("%q is not a decimal", value)}
+	}
+	return res, nil
+}
+
+// NewScheduleResponse converts the rows of an amortization table to their API form
+func NewScheduleResponse(rows []formulae.Row) *v1.GenerateScheduleResponse {
+	var (
+		res                          = &v1.GenerateScheduleResponse{Rows: make([]*v1.LoanScheduleRow, 0, len(rows))}
+		payment, interest, principal = decimal.Zero, decimal.Zero, decimal.Zero
+		prepayment, charge           = decimal.Zero, decimal.Zero
+	)
+	for _, row := range rows {
+		res.Rows = append(res.Rows, &v1.LoanScheduleRow{
+			Period:     row.Period,
+			StartDate:  row.StartDate.Format(DateLayout),
+			EndDate:    row.EndDate.Format(DateLayout),
+			Payment:    row.Payment.String(),
+			Interest:   row.Interest.String(),
+			Principal:  row.Principal.String(),
+			Prepayment: row.Prepayment.String(),
+			Charge:     row.Charge.String(),
+			Balance:    row.Balance.String(),
+		})
+		payment = payment.Add(row.Payment)
+		interest = interest.Add(row.Interest)
+		principal = principal.Add(row.Principal)
+		prepayment = prepayment.Add(row.Prepayment)
+		charge = charge.Add(row.Charge)
+	}
+	res.TotalPayment = payment.String()
+	res.TotalInterest = interest.String()
+	res.TotalPrincipal = principal.String()
+	res.TotalPrepayment = prepayment.String()
+	res.TotalCharge = charge.String()
+	return res
+}
@@ -0,0 +1,185 @@
+package loan
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func monthly() *v1.LoanConfig {
+	return &v1.LoanConfig{
+		StartDate:              "2022-01-01",
+		EndDate:                "2022-12-31",
+		Frequency:              v1.LoanFrequency_FREQUENCY_MONTHLY,
+		AmountBorrowed:         "12000",
+		InterestType:           v1.InterestType_INTEREST_TYPE_REDUCING,
+		Interest:               "1200",
+		EnableRounding:         true,
+		RoundingPlaces:         2,
+		RoundingErrorTolerance: "0.05",
+	}
+}
+
+func TestGenerateSchedule(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(c *v1.LoanConfig)
+		wantRows  int
+		wantEnd   string
+		wantField string
+	}{
+		{name: "monthly reducing", wantRows: 12},
+		{name: "flat", modify: func(c *v1.LoanConfig) { c.InterestType = v1.InterestType_INTEREST_TYPE_FLAT }, wantRows: 12},
+		{name: "quarterly", modify: func(c *v1.LoanConfig) { c.Frequency = v1.LoanFrequency_FREQUENCY_QUARTERLY }, wantRows: 4},
+		{name: "half-yearly", modify: func(c *v1.LoanConfig) { c.Frequency = v1.LoanFrequency_FREQUENCY_HALF_YEARLY }, wantRows: 2},
+		{name: "fortnightly", modify: func(c *v1.LoanConfig) {
+			c.Frequency = v1.LoanFrequency_FREQUENCY_FORTNIGHTLY
+			c.EndDate = "2022-12-30"
+		}, wantRows: 26},
+		{name: "short stubs", modify: func(c *v1.LoanConfig) { c.FirstPaymentDate = "2022-01-20" }, wantRows: 13},
+		{name: "long back stub", modify: func(c *v1.LoanConfig) {
+			c.FirstPaymentDate = "2022-01-20"
+			c.BackStub = v1.BackStub_BACK_STUB_LONG
+		}, wantRows: 12},
+		{name: "first payment before start", modify: func(c *v1.LoanConfig) { c.FirstPaymentDate = "2021-12-31" }, wantField: "config.first_payment_date"},
+		{name: "actual/365", modify: func(c *v1.LoanConfig) { c.DayCount = "ACT365" }, wantRows: 12},
+		{name: "unknown day count", modify: func(c *v1.LoanConfig) { c.DayCount = "ACT999" }, wantField: "config.day_count"},
+		{name: "part-payment keeping the payment", modify: func(c *v1.LoanConfig) {
+			c.Prepayments = []*v1.LoanPrepayment{{Date: "2022-03-15", Amount: "4000"}}
+		}, wantRows: 8, wantEnd: "2022-08-31"},
+		{name: "part-payment keeping the tenor", modify: func(c *v1.LoanConfig) {
+			c.Prepayments = []*v1.LoanPrepayment{{Date: "2022-03-15", Amount: "4000"}}
+			c.Recast = v1.RecastPolicy_RECAST_POLICY_KEEP_TENOR
+		}, wantRows: 12},
+		{name: "foreclosure", modify: func(c *v1.LoanConfig) {
+			c.Prepayments = []*v1.LoanPrepayment{{Date: "2022-06-10", Foreclose: true, Charge: "200"}}
+		}, wantRows: 6, wantEnd: "2022-06-30"},
+		{name: "prepayment after end", modify: func(c *v1.LoanConfig) {
+			c.Prepayments = []*v1.LoanPrepayment{{Date: "2023-01-01", Foreclose: true}}
+		}, wantField: "config.prepayments"},
+		{name: "prepayment without amount", modify: func(c *v1.LoanConfig) {
+			c.Prepayments = []*v1.LoanPrepayment{{Date: "2022-03-15"}}
+		}, wantField: "config.prepayments[0].amount"},
+		{name: "unknown recast policy", modify: func(c *v1.LoanConfig) { c.Recast = 9 }, wantField: "config.recast"},
+		{name: "floating rate", modify: func(c *v1.LoanConfig) {
+			c.RateResets = []*v1.LoanRateReset{{Date: "2022-01-01", Rate: "650"}, {Date: "2022-03-15", Rate: "900"}}
+			c.Spread = "300"
+			c.ResetFrequency = v1.LoanFrequency_FREQUENCY_QUARTERLY
+			c.RateCap = "1250"
+		}, wantRows: 12},
+		{name: "rate rise", modify: func(c *v1.LoanConfig) {
+			c.RateResets = []*v1.LoanRateReset{{Date: "2022-03-15", Rate: "1000"}}
+			c.Spread = "300"
+		}, wantRows: 12},
+		{name: "rate rise keeping the payment", modify: func(c *v1.LoanConfig) {
+			c.RateResets = []*v1.LoanRateReset{{Date: "2022-03-15", Rate: "1000"}}
+			c.Spread = "300"
+			c.Recast = v1.RecastPolicy_RECAST_POLICY_KEEP_PAYMENT
+		}, wantField: "config.recast"},
+		{name: "benchmark curve", modify: func(c *v1.LoanConfig) {
+			c.BenchmarkCurve = `{"r": 6.5, "spread": 0}`
+			c.Spread = "300"
+		}, wantRows: 12},
+		{name: "benchmark curve and rate resets", modify: func(c *v1.LoanConfig) {
+			c.BenchmarkCurve = `{"r": 6.5, "spread": 0}`
+			c.RateResets = []*v1.LoanRateReset{{Date: "2022-01-01", Rate: "650"}}
+		}, wantField: "config.benchmark_curve"},
+		{name: "bad benchmark curve", modify: func(c *v1.LoanConfig) { c.BenchmarkCurve = "6.5" }, wantField: "config.benchmark_curve"},
+		{name: "bad rate reset", modify: func(c *v1.LoanConfig) {
+			c.RateResets = []*v1.LoanRateReset{{Date: "2022-01-01", Rate: "high"}}
+		}, wantField: "config.rate_resets[0].rate"},
+		{name: "floor above cap", modify: func(c *v1.LoanConfig) { c.RateCap, c.RateFloor = "900", "1000" }, wantField: "config.rate_floor"},
+		{name: "beginning", modify: func(c *v1.LoanConfig) { c.PaymentPeriod = v1.PaymentPeriod_PAYMENT_PERIOD_BEGINNING }, wantRows: 12},
+		{name: "uneven end date", modify: func(c *v1.LoanConfig) { c.EndDate = "2022-12-30" }, wantField: "config.end_date"},
+		{name: "end before start", modify: func(c *v1.LoanConfig) { c.EndDate = "2021-12-31" }, wantField: "config.end_date"},
+		{name: "bad date", modify: func(c *v1.LoanConfig) { c.StartDate = "01/01/2022" }, wantField: "config.start_date"},
+		{name: "unspecified frequency", modify: func(c *v1.LoanConfig) { c.Frequency = v1.LoanFrequency_FREQUENCY_UNSPECIFIED }, wantField: "config.frequency"},
+		{name: "unspecified interest type", modify: func(c *v1.LoanConfig) { c.InterestType = v1.InterestType_INTEREST_TYPE_UNSPECIFIED }, wantField: "config.interest_type"},
+		{name: "bad amount", modify: func(c *v1.LoanConfig) { c.AmountBorrowed = "12k" }, wantField: "config.amount_borrowed"},
+		{name: "zero amount", modify: func(c *v1.LoanConfig) { c.AmountBorrowed = "0" }, wantField: "config.amount_borrowed"},
+		{name: "negative interest", modify: func(c *v1.LoanConfig) { c.Interest = "-1" }, wantField: "config.interest"},
+		{name: "too many periods", modify: func(c *v1.LoanConfig) { c.EndDate = "2122-12-31" }, wantField: "config.end_date"},
+		{name: "too many stubbed periods", modify: func(c *v1.LoanConfig) {
+			c.Frequency, c.StartDate, c.FirstPaymentDate, c.EndDate = v1.LoanFrequency_FREQUENCY_DAILY, "0001-01-01", "0001-01-02", "9999-12-30"
+		}, wantField: "config.end_date"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := monthly()
+			if test.modify != nil {
+				test.modify(cfg)
+			}
+			res, err := NewService().GenerateSchedule(context.Background(), &v1.GenerateScheduleRequest{Config: cfg})
+			if test.wantField != "" {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("expected InvalidArgument, got %v", err)
+				}
+				var fields []string
+				for _, d := range status.Convert(err).Details() {
+					if br, ok := d.(*errdetails.BadRequest); ok {
+						for _, v := range br.FieldViolations {
+							fields = append(fields, v.Field)
+						}
+					}
+				}
+				if len(fields) != 1 || fields[0] != test.wantField {
+					t.Errorf("expected a violation of %s, got %v", test.wantField, fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Rows) != test.wantRows {
+				t.Errorf("expected %d rows, got %d", test.wantRows, len(res.Rows))
+			}
+			principal, err := decimal.NewFromString(res.TotalPrincipal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prepayment, err := decimal.NewFromString(res.TotalPrepayment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !principal.Add(prepayment).Equal(decimal.NewFromInt(-12000)) {
+				t.Errorf("expected a total principal and prepayment of -12000, got %s and %s", principal, prepayment)
+			}
+			last := res.Rows[len(res.Rows)-1]
+			wantEnd := test.wantEnd
+			if wantEnd == "" {
+				wantEnd = cfg.EndDate
+			}
+			if res.Rows[0].StartDate != "2022-01-01" || last.EndDate != wantEnd {
+				t.Errorf("unexpected dates %s to %s", res.Rows[0].StartDate, last.EndDate)
+			}
+			if last.Balance != "0" {
+				t.Errorf("expected the loan to be repaid, got a balance of %s", last.Balance)
+			}
+		})
+	}
+}
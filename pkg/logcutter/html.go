@@ -0,0 +1,130 @@
+package logcutter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// escapeSequence matches ANSI CSI escape sequences, e.g. "\x1b[1;31m"
+var escapeSequence = regexp.MustCompile("\x1b\\[([0-9;]*)([A-Za-z])")
+
+// textStyle is the set of SGR attributes active at some point in a line
+type textStyle struct {
+	bold, italic, underline bool
+	fg, bg                  int
+}
+
+func (s textStyle) classes() []string {
+	var res []string
+	if s.bold {
+		res = append(res, "term-fg1")
+	}
+	if s.italic {
+		res = append(res, "term-fg3")
+	}
+	if s.underline {
+		res = append(res, "term-fg4")
+	}
+	if s.fg != 0 {
+		res = append(res, fmt.Sprintf("term-fg%d", s.fg))
+	}
+	if s.bg != 0 {
+		res = append(res, fmt.Sprintf("term-bg%d", s.bg))
+	}
+	return res
+}
+
+func (s *textStyle) apply(params string) {
+	if params == "" {
+		*s = textStyle{}
+		return
+	}
+	for _, p := range strings.Split(params, ";") {
+		code, err := strconv.Atoi(p)
+		if err != nil {
+			continue
+		}
+		switch {
+		case code == 0:
+			*s = textStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 22:
+			s.bold = false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 39:
+			s.fg = 0
+		case code == 49:
+			s.bg = 0
+		case (code >= 30 && code <= 37) || (code >= 90 && code <= 97):
+			s.fg = code
+		case (code >= 40 && code <= 47) || (code >= 100 && code <= 107):
+			s.bg = code
+		}
+	}
+}
+
+// ANSIToHTML converts a line of terminal output to HTML. SGR escape sequences become
+// span elements with "term-*" CSS classes, all other escape sequences are dropped and
+// the text is HTML escaped.
+func ANSIToHTML(line string) string {
+	var (
+		res   strings.Builder
+		style textStyle
+		pos   int
+	)
+	write := func(text string) {
+		if text == "" {
+			return
+		}
+		cls := style.classes()
+		if len(cls) > 0 {
+			res.WriteString(`<span class="` + strings.Join(cls, " ") + `">`)
+		}
+		res.WriteString(html.EscapeString(text))
+		if len(cls) > 0 {
+			res.WriteString("</span>")
+		}
+	}
+
+	for _, m := range escapeSequence.FindAllStringSubmatchIndex(line, -1) {
+		write(line[pos:m[0]])
+		pos = m[1]
+		if line[m[4]:m[5]] == "m" {
+			style.apply(line[m[2]:m[3]])
+		}
+	}
+	write(line[pos:])
+
+	return res.String()
+}
@@ -0,0 +1,139 @@
+package logcutter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+// Cutter splits a log stream into slices for more structured display
+type Cutter interface {
+	// Slice reads on the in reader line-by-line. For each line it can produce several events
+	// on the events channel. Once the reader returns EOF the events and errchan are closed.
+	// If anything goes wrong while reading a single error is written to errchan and both
+	// channels are closed.
+	Slice(in io.Reader) (events <-chan *v1.LogSliceEvent, errchan <-chan error)
+}
+
+const (
+	// DefaultBufferSize is the number of events the cutter buffers before blocking
+	DefaultBufferSize = 64
+
+	// maxLineLength is the longest log line the cutter can process
+	maxLineLength = 1024 * 1024
+)
+
+// DefaultCutter is the cutter used by Bhojpur Finance. Algorithms mark up their output
+// using the following line prefixes:
+//
+//	[<slice>|PHASE] <description>   starts a new phase of the algorithm
+//	[<slice>] <content>             adds content to a slice, starting it if need be
+//	[<slice>|DONE]                  marks a slice as successfully finished
+//	[<slice>|FAIL] <reason>         marks a slice as failed
+//	[<type>|RESULT] <payload>       publishes a result of the algorithm
+//
+// Lines without a prefix are content of the unnamed slice. When the log ends, all
+// slices that were started but not finished are abandoned.
+var DefaultCutter Cutter = defaultCutter{}
+
+type defaultCutter struct{}
+
+var markerRegexp = regexp.MustCompile(`^\[([^\]|]+)(?:\|(PHASE|DONE|FAIL|RESULT))?\]\s?(.*)$`)
+
+// Slice cuts a log stream into pieces based on the slice markers
+func (defaultCutter) Slice(in io.Reader) (events <-chan *v1.LogSliceEvent, errchan <-chan error) {
+	evts := make(chan *v1.LogSliceEvent, DefaultBufferSize)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(evts)
+		defer close(errc)
+
+		var (
+			open  = make(map[string]struct{})
+			order []string
+		)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+		for scanner.Scan() {
+			for _, evt := range cutLine(scanner.Text(), open) {
+				if evt.Type == v1.LogSliceType_SLICE_START {
+					order = append(order, evt.Name)
+				}
+				evts <- evt
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			errc <- err
+			return
+		}
+
+		for _, name := range order {
+			if _, ok := open[name]; !ok {
+				continue
+			}
+			delete(open, name)
+			evts <- &v1.LogSliceEvent{Name: name, Type: v1.LogSliceType_SLICE_ABANDONED}
+		}
+	}()
+
+	return evts, errc
+}
+
+// cutLine produces the events for a single log line. open holds the names of all
+// slices which have been started but not finished yet.
+func cutLine(line string, open map[string]struct{}) []*v1.LogSliceEvent {
+	m := markerRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return []*v1.LogSliceEvent{{Type: v1.LogSliceType_SLICE_CONTENT, Payload: line}}
+	}
+
+	name, verb, payload := m[1], m[2], m[3]
+	switch verb {
+	case "PHASE":
+		return []*v1.LogSliceEvent{{Name: name, Type: v1.LogSliceType_SLICE_PHASE, Payload: payload}}
+	case "RESULT":
+		return []*v1.LogSliceEvent{{Name: name, Type: v1.LogSliceType_SLICE_RESULT, Payload: payload}}
+	case "DONE", "FAIL":
+		if _, ok := open[name]; !ok {
+			// we never saw this slice start, hence there's nothing to finish
+			return nil
+		}
+		delete(open, name)
+		tpe := v1.LogSliceType_SLICE_DONE
+		if verb == "FAIL" {
+			tpe = v1.LogSliceType_SLICE_FAIL
+		}
+		return []*v1.LogSliceEvent{{Name: name, Type: tpe, Payload: payload}}
+	}
+
+	var res []*v1.LogSliceEvent
+	if _, ok := open[name]; !ok {
+		open[name] = struct{}{}
+		res = append(res, &v1.LogSliceEvent{Name: name, Type: v1.LogSliceType_SLICE_START})
+	}
+	res = append(res, &v1.LogSliceEvent{Name: name, Type: v1.LogSliceType_SLICE_CONTENT, Payload: payload})
+	return res
+}
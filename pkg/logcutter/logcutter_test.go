@@ -0,0 +1,149 @@
+package logcutter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCutterSlice(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "unsliced content",
+			input: "hello\nworld\n",
+			want:  []string{"SLICE_CONTENT||hello", "SLICE_CONTENT||world"},
+		},
+		{
+			name: "full lifecycle",
+			input: strings.Join([]string{
+				"[build|PHASE] building the curve",
+				"[fetch] loading quotes",
+				"[fetch] 42 quotes loaded",
+				"[fetch|DONE]",
+				"[fit] fitting",
+				"[fit|FAIL] did not converge",
+				"[curve|RESULT] https://example.com/curve.json",
+			}, "\n"),
+			want: []string{
+				"SLICE_PHASE|build|building the curve",
+				"SLICE_START|fetch|",
+				"SLICE_CONTENT|fetch|loading quotes",
+				"SLICE_CONTENT|fetch|42 quotes loaded",
+				"SLICE_DONE|fetch|",
+				"SLICE_START|fit|",
+				"SLICE_CONTENT|fit|fitting",
+				"SLICE_FAIL|fit|did not converge",
+				"SLICE_RESULT|curve|https://example.com/curve.json",
+			},
+		},
+		{
+			name:  "abandoned slices",
+			input: "[a] foo\n[b] bar\n[a|DONE]\n[c] baz\n",
+			want: []string{
+				"SLICE_START|a|",
+				"SLICE_CONTENT|a|foo",
+				"SLICE_START|b|",
+				"SLICE_CONTENT|b|bar",
+				"SLICE_DONE|a|",
+				"SLICE_START|c|",
+				"SLICE_CONTENT|c|baz",
+				"SLICE_ABANDONED|b|",
+				"SLICE_ABANDONED|c|",
+			},
+		},
+		{
+			name:  "done without start",
+			input: "[a|DONE]\n",
+			want:  nil,
+		},
+		{
+			name:  "slice restarts after done",
+			input: "[a] foo\n[a|DONE]\n[a] bar\n",
+			want: []string{
+				"SLICE_START|a|",
+				"SLICE_CONTENT|a|foo",
+				"SLICE_DONE|a|",
+				"SLICE_START|a|",
+				"SLICE_CONTENT|a|bar",
+				"SLICE_ABANDONED|a|",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			evts, errc := DefaultCutter.Slice(strings.NewReader(test.input))
+			var act []string
+			for evt := range evts {
+				act = append(act, fmt.Sprintf("%s|%s|%s", evt.Type, evt.Name, evt.Payload))
+			}
+			if err := <-errc; err != nil {
+				t.Fatal(err)
+			}
+
+			if len(act) != len(test.want) {
+				t.Fatalf("unexpected events: want %v, got %v", test.want, act)
+			}
+			for i := range act {
+				if act[i] != test.want[i] {
+					t.Errorf("event %d: want %s, got %s", i, test.want[i], act[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultCutterEmpty(t *testing.T) {
+	evts, errc := DefaultCutter.Slice(strings.NewReader(""))
+	for evt := range evts {
+		t.Errorf("unexpected event: %v", evt)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestANSIToHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain", input: "hello world", want: "hello world"},
+		{name: "escaping", input: "<b>&</b>", want: "&lt;b&gt;&amp;&lt;/b&gt;"},
+		{name: "color", input: "\x1b[31mred\x1b[0m plain", want: `<span class="term-fg31">red</span> plain`},
+		{name: "bold and color", input: "\x1b[1;32mok\x1b[m", want: `<span class="term-fg1 term-fg32">ok</span>`},
+		{name: "background", input: "\x1b[41mbg\x1b[49m none", want: `<span class="term-bg41">bg</span> none`},
+		{name: "non-SGR sequences are dropped", input: "\x1b[2Kprogress", want: "progress"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if act := ANSIToHTML(test.input); act != test.want {
+				t.Errorf("ANSIToHTML(%q): want %q, got %q", test.input, test.want, act)
+			}
+		})
+	}
+}
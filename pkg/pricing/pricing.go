@@ -0,0 +1,248 @@
+package pricing
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/formulae/daycount"
+	"github.com/bhojpur/finance/pkg/securities"
+	"github.com/bhojpur/finance/pkg/securities/instrument/bond"
+	"github.com/bhojpur/finance/pkg/securities/instrument/forward"
+	"github.com/bhojpur/finance/pkg/securities/instrument/option"
+	"github.com/bhojpur/finance/pkg/securities/instrument/swap"
+	"github.com/bhojpur/finance/pkg/securities/maturity"
+	"github.com/bhojpur/finance/pkg/securities/term"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Service implements the PricingService on top of the securities package
+type Service struct {
+	v1.UnimplementedPricingServiceServer
+}
+
+// NewService produces a new pricing service
+func NewService() *Service {
+	return &Service{}
+}
+
+// accruer is implemented by instruments which accrue interest
+type accruer interface {
+	Accrued() float64
+}
+
+// Price values an instrument on a term structure
+func (srv *Service) Price(ctx context.Context, req *v1.PriceRequest) (*v1.PriceResponse, error) {
+	ts, err := ParseTermStructure(req.TermStructure)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	sec, err := NewSecurity(req.Instrument)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if o, ok := sec.(*option.Indian); ok && o.Vola <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "option: volatility must be positive")
+	}
+
+	res := &v1.PriceResponse{PresentValue: sec.PresentValue(ts)}
+	if a, ok := sec.(accruer); ok {
+		res.Accrued = a.Accrued()
+	}
+	if s, ok := sec.(securities.TermSecurity); ok {
+		res.TermRisk = &v1.TermRisk{
+			Duration:  s.Duration(ts),
+			Convexity: s.Convexity(ts),
+			Pvbp:      securities.PVBP(s, ts),
+		}
+	}
+	if o, ok := sec.(*option.Indian); ok {
+		res.Greeks = &v1.Greeks{
+			Delta: o.Delta(ts),
+			Gamma: o.Gamma(ts),
+			Rho:   o.Rho(ts),
+			Vega:  o.Vega(ts),
+		}
+	}
+	return res, nil
+}
+
+// Solve finds the yield, spread or volatility at which an instrument is worth a given price
+func (srv *Service) Solve(ctx context.Context, req *v1.SolveRequest) (*v1.SolveResponse, error) {
+	sec, err := NewSecurity(req.Instrument)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var value float64
+	switch req.SolveFor {
+	case v1.SolveFor_SOLVE_FOR_IRR:
+		value, err = securities.Irr(req.Price, sec)
+	case v1.SolveFor_SOLVE_FOR_SPREAD:
+		ts, perr := ParseTermStructure(req.TermStructure)
+		if perr != nil {
+			return nil, status.Error(codes.InvalidArgument, perr.Error())
+		}
+		value, err = securities.Spread(req.Price, sec, ts)
+	case v1.SolveFor_SOLVE_FOR_IMPLIED_VOLATILITY:
+		o, ok := sec.(*option.Indian)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "implied volatility can only be solved for options")
+		}
+		ts, perr := ParseTermStructure(req.TermStructure)
+		if perr != nil {
+			return nil, status.Error(codes.InvalidArgument, perr.Error())
+		}
+		value, err = securities.ImpliedVola(req.Price, o, ts)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "cannot solve for %v", req.SolveFor)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "no solution for price %v: %v", req.Price, err)
+	}
+	return &v1.SolveResponse{Value: value}, nil
+}
+
+// ParseTermStructure reads a term structure from the JSON understood by term.Parse
+func ParseTermStructure(definition string) (term.Structure, error) {
+	if strings.TrimSpace(definition) == "" {
+		return nil, fmt.Errorf("term_structure is required")
+	}
+	ts, err := term.Parse([]byte(definition))
+	if err != nil {
+		return nil, fmt.Errorf("invalid term_structure: %w", err)
+	}
+	if s, ok := ts.(*term.Spline); ok && s.Spline == nil {
+		return nil, fmt.Errorf("invalid term_structure: spline curves cannot be defined in JSON")
+	}
+	return ts, nil
+}
+
+// NewSecurity converts the API form of an instrument to its securities counterpart
+func NewSecurity(inst *v1.Instrument) (securities.Security, error) {
+	switch kind := inst.GetKind().(type) {
+	case *v1.Instrument_StraightBond:
+		return newStraightBond(kind.StraightBond)
+	case *v1.Instrument_FloatingBond:
+		return newFloatingBond(kind.FloatingBond)
+	case *v1.Instrument_InterestRateSwap:
+		floating, err := newFloatingBond(kind.InterestRateSwap.GetFloating())
+		if err != nil {
+			return nil, fmt.Errorf("floating leg: %w", err)
+		}
+		fixed, err := newStraightBond(kind.InterestRateSwap.GetFixed())
+		if err != nil {
+			return nil, fmt.Errorf("fixed leg: %w", err)
+		}
+		return &swap.InterestRateSwap{Floating: *floating, Fixed: *fixed}, nil
+	case *v1.Instrument_ForwardRateAgreement:
+		fra := kind.ForwardRateAgreement
+		if fra.T1 < 0 || fra.T2 < fra.T1 {
+			return nil, fmt.Errorf("forward rate agreement: t2 must not be before t1, which must not be negative")
+		}
+		return &forward.RateAgreement{N: fra.Notional, M: fra.Factor, T1: fra.T1, T2: fra.T2}, nil
+	case *v1.Instrument_ForwardContract:
+		fc := kind.ForwardContract
+		if fc.Maturity < 0 {
+			return nil, fmt.Errorf("forward contract: maturity must not be negative")
+		}
+		return &forward.Contract{K: fc.Strike, F: fc.ForwardPrice, T: fc.Maturity}, nil
+	case *v1.Instrument_Option:
+		return newOption(kind.Option)
+	default:
+		return nil, fmt.Errorf("instrument is required")
+	}
+}
+
+func newStraightBond(b *v1.StraightBond) (*bond.Straight, error) {
+	if b == nil {
+		return nil, fmt.Errorf("bond is required")
+	}
+	sched, err := newSchedule(b.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &bond.Straight{Schedule: *sched, Coupon: b.Coupon, Redemption: b.Redemption}, nil
+}
+
+func newFloatingBond(b *v1.FloatingBond) (*bond.Floating, error) {
+	if b == nil {
+		return nil, fmt.Errorf("bond is required")
+	}
+	sched, err := newSchedule(b.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &bond.Floating{Schedule: *sched, Rate: b.Rate, Redemption: b.Redemption}, nil
+}
+
+// newSchedule validates a schedule up front as the maturity package panics on invalid ones
+func newSchedule(s *v1.MaturitySchedule) (*maturity.Schedule, error) {
+	if s.GetSettlement() == nil || s.GetMaturity() == nil {
+		return nil, fmt.Errorf("schedule: settlement and maturity are required")
+	}
+	res := &maturity.Schedule{
+		Settlement: s.Settlement.AsTime(),
+		Maturity:   s.Maturity.AsTime(),
+		Frequency:  int(s.Frequency),
+		Basis:      s.Basis,
+	}
+	if !res.Maturity.After(res.Settlement) {
+		return nil, fmt.Errorf("schedule: maturity must be after settlement")
+	}
+	if res.Frequency < 0 || res.Frequency > 12 || 12%res.Compounding() != 0 {
+		return nil, fmt.Errorf("schedule: invalid frequency %d, must be one of 1, 2, 3, 4, 6 and 12", res.Frequency)
+	}
+	if res.Basis != "" {
+		var known bool
+		for _, b := range daycount.Implemented() {
+			known = known || b == res.Basis
+		}
+		if !known {
+			return nil, fmt.Errorf("schedule: unknown day count basis %q", res.Basis)
+		}
+	}
+	return res, nil
+}
+
+func newOption(o *v1.EquityOption) (*option.Indian, error) {
+	res := &option.Indian{S: o.Spot, K: o.Strike, T: o.Maturity, Q: o.DividendYield, Vola: o.Volatility}
+	switch o.Type {
+	case v1.OptionType_OPTION_CALL:
+		res.Type = option.Call
+	case v1.OptionType_OPTION_PUT:
+		res.Type = option.Put
+	default:
+		return nil, fmt.Errorf("option: unknown type %v", o.Type)
+	}
+	if o.Spot <= 0 || o.Strike <= 0 || o.Maturity <= 0 {
+		return nil, fmt.Errorf("option: spot, strike and maturity must be positive")
+	}
+	if o.Volatility < 0 || math.IsNaN(o.Volatility) {
+		return nil, fmt.Errorf("option: volatility must not be negative")
+	}
+	return res, nil
+}
@@ -0,0 +1,213 @@
+package pricing
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// chGovt are the term structure parameters per 2021-04-01 for CH govt bonds
+const chGovt = `{"b0": -0.266372, "b1": -0.471343, "b2": 5.68789, "b3": -5.12324, "t1": 5.74881, "t2": 4.14426, "spread": 0}`
+
+// chBond is ISIN CH0224396983, quoted at 109.70 per 2021-04-01
+func chBond() *v1.Instrument {
+	return &v1.Instrument{Kind: &v1.Instrument_StraightBond{StraightBond: &v1.StraightBond{
+		Schedule: &v1.MaturitySchedule{
+			Settlement: timestamppb.New(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)),
+			Maturity:   timestamppb.New(time.Date(2026, 5, 28, 0, 0, 0, 0, time.UTC)),
+			Frequency:  1,
+		},
+		Coupon:     1.25,
+		Redemption: 100,
+	}}}
+}
+
+func testOption(tpe v1.OptionType) *v1.Instrument {
+	return &v1.Instrument{Kind: &v1.Instrument_Option{Option: &v1.EquityOption{
+		Type:       tpe,
+		Spot:       110,
+		Strike:     100,
+		Maturity:   2,
+		Volatility: 0.3,
+	}}}
+}
+
+func TestPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *v1.PriceRequest
+		wantCode  codes.Code
+		wantPV    float64
+		wantRisk  bool
+		wantDelta float64
+	}{
+		{
+			name:     "straight bond",
+			req:      &v1.PriceRequest{TermStructure: chGovt, Instrument: chBond()},
+			wantPV:   109.70 + 1.25*303.0/360.0,
+			wantRisk: true,
+		},
+		{
+			name:      "call",
+			req:       &v1.PriceRequest{TermStructure: `{"r": 2, "spread": 0}`, Instrument: testOption(v1.OptionType_OPTION_CALL)},
+			wantPV:    25.1291,
+			wantDelta: 0.7023,
+		},
+		{
+			name:      "put",
+			req:       &v1.PriceRequest{TermStructure: `{"r": 2, "spread": 0}`, Instrument: testOption(v1.OptionType_OPTION_PUT)},
+			wantPV:    11.2080,
+			wantDelta: -0.2977,
+		},
+		{
+			name: "forward contract",
+			req: &v1.PriceRequest{TermStructure: `{"r": 2, "spread": 0}`, Instrument: &v1.Instrument{Kind: &v1.Instrument_ForwardContract{
+				ForwardContract: &v1.ForwardContract{Strike: 100, ForwardPrice: 101, Maturity: 1},
+			}}},
+			wantPV: math.Exp(-0.02),
+		},
+		{
+			name:     "missing term structure",
+			req:      &v1.PriceRequest{Instrument: chBond()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid term structure",
+			req:      &v1.PriceRequest{TermStructure: `{"foo": 1}`, Instrument: chBond()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing instrument",
+			req:      &v1.PriceRequest{TermStructure: chGovt},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid frequency",
+			req: &v1.PriceRequest{TermStructure: chGovt, Instrument: func() *v1.Instrument {
+				res := chBond()
+				res.GetStraightBond().Schedule.Frequency = 24
+				return res
+			}()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "unknown basis",
+			req: &v1.PriceRequest{TermStructure: chGovt, Instrument: func() *v1.Instrument {
+				res := chBond()
+				res.GetStraightBond().Schedule.Basis = "ACT365"
+				return res
+			}()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "option without volatility",
+			req: &v1.PriceRequest{TermStructure: `{"r": 2, "spread": 0}`, Instrument: func() *v1.Instrument {
+				res := testOption(v1.OptionType_OPTION_CALL)
+				res.GetOption().Volatility = 0
+				return res
+			}()},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := NewService().Price(context.Background(), test.req)
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(res.PresentValue-test.wantPV) > 0.05 {
+				t.Errorf("unexpected present value: want %v, got %v", test.wantPV, res.PresentValue)
+			}
+			if (res.TermRisk != nil) != test.wantRisk {
+				t.Errorf("unexpected term risk: %v", res.TermRisk)
+			}
+			if test.wantDelta != 0 && math.Abs(res.GetGreeks().GetDelta()-test.wantDelta) > 0.0001 {
+				t.Errorf("unexpected delta: want %v, got %v", test.wantDelta, res.GetGreeks().GetDelta())
+			}
+		})
+	}
+}
+
+func TestSolve(t *testing.T) {
+	dirty := 109.70 + 1.25*303.0/360.0
+	tests := []struct {
+		name     string
+		req      *v1.SolveRequest
+		wantCode codes.Code
+		want     float64
+	}{
+		{
+			name: "irr",
+			req:  &v1.SolveRequest{Instrument: chBond(), Price: dirty, SolveFor: v1.SolveFor_SOLVE_FOR_IRR},
+			want: -0.574,
+		},
+		{
+			name: "spread",
+			req:  &v1.SolveRequest{Instrument: chBond(), Price: dirty, SolveFor: v1.SolveFor_SOLVE_FOR_SPREAD, TermStructure: chGovt},
+			want: 0,
+		},
+		{
+			name: "implied volatility",
+			req:  &v1.SolveRequest{Instrument: testOption(v1.OptionType_OPTION_CALL), Price: 25.1291, SolveFor: v1.SolveFor_SOLVE_FOR_IMPLIED_VOLATILITY, TermStructure: `{"r": 2, "spread": 0}`},
+			want: 0.3,
+		},
+		{
+			name:     "spread without term structure",
+			req:      &v1.SolveRequest{Instrument: chBond(), Price: dirty, SolveFor: v1.SolveFor_SOLVE_FOR_SPREAD},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "implied volatility of a bond",
+			req:      &v1.SolveRequest{Instrument: chBond(), Price: dirty, SolveFor: v1.SolveFor_SOLVE_FOR_IMPLIED_VOLATILITY, TermStructure: chGovt},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no solution",
+			req:      &v1.SolveRequest{Instrument: testOption(v1.OptionType_OPTION_CALL), Price: 200, SolveFor: v1.SolveFor_SOLVE_FOR_IMPLIED_VOLATILITY, TermStructure: `{"r": 2, "spread": 0}`},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := NewService().Solve(context.Background(), test.req)
+			if code := status.Code(err); code != test.wantCode {
+				t.Fatalf("unexpected code: want %v, got %v (%v)", test.wantCode, code, err)
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(res.Value-test.want) > 0.1 {
+				t.Errorf("unexpected value: want %v, got %v", test.want, res.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package query
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+// operators maps the textual filter operators to their operation. Longer operators
+// come first so that they take precedence when parsing.
+var operators = []struct {
+	Token     string
+	Operation v1.FilterOp
+	Negate    bool
+}{
+	{"==", v1.FilterOp_OP_EQUALS, false},
+	{"!=", v1.FilterOp_OP_EQUALS, true},
+	{"~=", v1.FilterOp_OP_CONTAINS, false},
+	{"|=", v1.FilterOp_OP_STARTS_WITH, false},
+	{"=|", v1.FilterOp_OP_ENDS_WITH, false},
+}
+
+// ParseFilterTerm parses a filter term of the form "field==value". Supported operators are
+// == (equals), != (does not equal), ~= (contains), |= (starts with) and =| (ends with).
+// A field without operator and value tests for the field's existence. Prefixing a term
+// with ! negates it, e.g. "!metadata.finished" or "!name~=nightly".
+func ParseFilterTerm(s string) (*v1.FilterTerm, error) {
+	var negate bool
+	if strings.HasPrefix(s, "!") {
+		negate = true
+		s = s[1:]
+	}
+
+	var (
+		res     *v1.FilterTerm
+		opStart = -1
+	)
+	for _, op := range operators {
+		idx := strings.Index(s, op.Token)
+		if idx < 0 || (opStart >= 0 && idx >= opStart) {
+			continue
+		}
+		opStart = idx
+		res = &v1.FilterTerm{
+			Field:     s[:idx],
+			Value:     s[idx+len(op.Token):],
+			Operation: op.Operation,
+			Negate:    op.Negate != negate,
+		}
+	}
+	if res == nil {
+		res = &v1.FilterTerm{Field: s, Operation: v1.FilterOp_OP_EXISTS, Negate: negate}
+	}
+	if res.Field == "" {
+		return nil, fmt.Errorf("invalid filter term %q: field is missing", s)
+	}
+
+	var empty v1.AlgorithmStatus
+	if _, _, err := Value(&empty, res.Field); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ParseOrder parses an order expression of the form "field:asc" or "field:desc".
+// Without a direction, the order is ascending.
+func ParseOrder(s string) (*v1.OrderExpression, error) {
+	field, dir := s, "asc"
+	if idx := strings.LastIndex(s, ":"); idx >= 0 {
+		field, dir = s[:idx], s[idx+1:]
+	}
+
+	res := &v1.OrderExpression{Field: field}
+	switch dir {
+	case "asc":
+		res.Ascending = true
+	case "desc":
+		res.Ascending = false
+	default:
+		return nil, fmt.Errorf("invalid order %q: direction must be asc or desc", s)
+	}
+
+	var empty v1.AlgorithmStatus
+	if _, _, err := Value(&empty, res.Field); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,314 @@
+package query
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const annotationPrefix = "metadata.annotations."
+
+// timeFormat is a fixed-width time format so that timestamps sort lexicographically
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// getter resolves the value of a single field. Fields are addressed by their path,
+// e.g. "phase", "metadata.owner" or "metadata.repository.repo".
+type getter func(s *v1.AlgorithmStatus) (value string, ok bool)
+
+var fields = map[string]getter{
+	"name":    func(s *v1.AlgorithmStatus) (string, bool) { return s.Name, s.Name != "" },
+	"phase":   func(s *v1.AlgorithmStatus) (string, bool) { return PhaseString(s.Phase), true },
+	"details": func(s *v1.AlgorithmStatus) (string, bool) { return s.Details, s.Details != "" },
+
+	"metadata.owner":               metadataString(func(md *v1.AlgorithmMetadata) string { return md.Owner }),
+	"metadata.algorithm_spec_name": metadataString(func(md *v1.AlgorithmMetadata) string { return md.AlgorithmSpecName }),
+	"metadata.trigger": func(s *v1.AlgorithmStatus) (string, bool) {
+		return TriggerString(s.GetMetadata().GetTrigger()), s.GetMetadata() != nil
+	},
+	"metadata.created":  metadataTime(func(md *v1.AlgorithmMetadata) *timestamppb.Timestamp { return md.Created }),
+	"metadata.finished": metadataTime(func(md *v1.AlgorithmMetadata) *timestamppb.Timestamp { return md.Finished }),
+
+	"metadata.repository.host":     repositoryString(func(r *v1.Repository) string { return r.Host }),
+	"metadata.repository.owner":    repositoryString(func(r *v1.Repository) string { return r.Owner }),
+	"metadata.repository.repo":     repositoryString(func(r *v1.Repository) string { return r.Repo }),
+	"metadata.repository.ref":      repositoryString(func(r *v1.Repository) string { return r.Ref }),
+	"metadata.repository.revision": repositoryString(func(r *v1.Repository) string { return r.Revision }),
+
+	"conditions.success":     conditionsBool(func(c *v1.AlgorithmConditions) bool { return c.Success }),
+	"conditions.can_replay":  conditionsBool(func(c *v1.AlgorithmConditions) bool { return c.CanReplay }),
+	"conditions.did_execute": conditionsBool(func(c *v1.AlgorithmConditions) bool { return c.DidExecute }),
+	"conditions.failure_count": func(s *v1.AlgorithmStatus) (string, bool) {
+		return strconv.Itoa(int(s.GetConditions().GetFailureCount())), s.GetConditions() != nil
+	},
+	"conditions.wait_until": func(s *v1.AlgorithmStatus) (string, bool) {
+		return formatTime(s.GetConditions().GetWaitUntil())
+	},
+}
+
+// numericFields are compared as numbers rather than strings when sorting
+var numericFields = map[string]bool{
+	"conditions.failure_count": true,
+}
+
+func metadataString(f func(md *v1.AlgorithmMetadata) string) getter {
+	return func(s *v1.AlgorithmStatus) (string, bool) {
+		if s.Metadata == nil {
+			return "", false
+		}
+		v := f(s.Metadata)
+		return v, v != ""
+	}
+}
+
+func metadataTime(f func(md *v1.AlgorithmMetadata) *timestamppb.Timestamp) getter {
+	return func(s *v1.AlgorithmStatus) (string, bool) {
+		if s.Metadata == nil {
+			return "", false
+		}
+		return formatTime(f(s.Metadata))
+	}
+}
+
+func repositoryString(f func(r *v1.Repository) string) getter {
+	return func(s *v1.AlgorithmStatus) (string, bool) {
+		repo := s.GetMetadata().GetRepository()
+		if repo == nil {
+			return "", false
+		}
+		v := f(repo)
+		return v, v != ""
+	}
+}
+
+func conditionsBool(f func(c *v1.AlgorithmConditions) bool) getter {
+	return func(s *v1.AlgorithmStatus) (string, bool) {
+		if s.Conditions == nil {
+			return "", false
+		}
+		return strconv.FormatBool(f(s.Conditions)), true
+	}
+}
+
+func formatTime(ts *timestamppb.Timestamp) (string, bool) {
+	if ts == nil {
+		return "", false
+	}
+	return ts.AsTime().UTC().Format(timeFormat), true
+}
+
+// PhaseString returns the filter value of a phase, e.g. "running" for PHASE_RUNNING
+func PhaseString(p v1.AlgorithmPhase) string {
+	return strings.ToLower(strings.TrimPrefix(p.String(), "PHASE_"))
+}
+
+// TriggerString returns the filter value of a trigger, e.g. "push" for TRIGGER_PUSH
+func TriggerString(t v1.AlgorithmTrigger) string {
+	return strings.ToLower(strings.TrimPrefix(t.String(), "TRIGGER_"))
+}
+
+// Value resolves a field path against an AlgorithmStatus. Annotations are addressed as
+// "metadata.annotations.<key>". If the field is not set on the status, ok is false.
+// Unknown fields result in an error.
+func Value(s *v1.AlgorithmStatus, field string) (value string, ok bool, err error) {
+	if strings.HasPrefix(field, annotationPrefix) {
+		key := strings.TrimPrefix(field, annotationPrefix)
+		if key == "" {
+			return "", false, fmt.Errorf("annotation key is missing in field %s", field)
+		}
+		for _, a := range s.GetMetadata().GetAnnotations() {
+			if a.Key == key {
+				return a.Value, true, nil
+			}
+		}
+		return "", false, nil
+	}
+
+	get, exists := fields[field]
+	if !exists {
+		return "", false, fmt.Errorf("unknown field %s", field)
+	}
+	value, ok = get(s)
+	return value, ok, nil
+}
+
+// Validate checks that all fields referenced by filter and order are known
+// and that the paging parameters are sensible.
+func Validate(filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int32) error {
+	var empty v1.AlgorithmStatus
+	for _, expr := range filter {
+		for _, term := range expr.Terms {
+			if _, _, err := Value(&empty, term.Field); err != nil {
+				return err
+			}
+			if _, ok := v1.FilterOp_name[int32(term.Operation)]; !ok {
+				return fmt.Errorf("unknown filter operation %d", term.Operation)
+			}
+		}
+	}
+	for _, o := range order {
+		if _, _, err := Value(&empty, o.Field); err != nil {
+			return err
+		}
+	}
+	if start < 0 {
+		return fmt.Errorf("start must not be negative")
+	}
+	if limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	return nil
+}
+
+// MatchesFilter returns true if the status matches at least one of the filter expressions,
+// i.e. the terms within an expression are AND'ed while the expressions are OR'ed.
+// An empty filter matches everything.
+func MatchesFilter(s *v1.AlgorithmStatus, filter []*v1.FilterExpression) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, expr := range filter {
+		if MatchesExpression(s, expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesExpression returns true if the status matches all terms of the expression
+func MatchesExpression(s *v1.AlgorithmStatus, expr *v1.FilterExpression) bool {
+	for _, term := range expr.GetTerms() {
+		if !MatchesTerm(s, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesTerm returns true if the status matches a single filter term
+func MatchesTerm(s *v1.AlgorithmStatus, term *v1.FilterTerm) bool {
+	value, ok, err := Value(s, term.Field)
+	if err != nil {
+		return false
+	}
+
+	var res bool
+	switch term.Operation {
+	case v1.FilterOp_OP_EQUALS:
+		res = ok && value == term.Value
+	case v1.FilterOp_OP_STARTS_WITH:
+		res = ok && strings.HasPrefix(value, term.Value)
+	case v1.FilterOp_OP_ENDS_WITH:
+		res = ok && strings.HasSuffix(value, term.Value)
+	case v1.FilterOp_OP_CONTAINS:
+		res = ok && strings.Contains(value, term.Value)
+	case v1.FilterOp_OP_EXISTS:
+		res = ok
+	}
+	if term.Negate {
+		res = !res
+	}
+	return res
+}
+
+// Sort stably sorts the statuses by the order expressions. Earlier expressions take
+// precedence over later ones. Unset fields sort before set ones in ascending order.
+func Sort(res []*v1.AlgorithmStatus, order []*v1.OrderExpression) {
+	if len(order) == 0 {
+		return
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		for _, o := range order {
+			c := compare(res[i], res[j], o.Field)
+			if c == 0 {
+				continue
+			}
+			if o.Ascending {
+				return c < 0
+			}
+			return c > 0
+		}
+		return false
+	})
+}
+
+func compare(a, b *v1.AlgorithmStatus, field string) int {
+	va, oka, _ := Value(a, field)
+	vb, okb, _ := Value(b, field)
+	switch {
+	case !oka && !okb:
+		return 0
+	case !oka:
+		return -1
+	case !okb:
+		return 1
+	}
+
+	if !numericFields[field] {
+		return strings.Compare(va, vb)
+	}
+	na, erra := strconv.ParseInt(va, 10, 64)
+	nb, errb := strconv.ParseInt(vb, 10, 64)
+	if erra == nil && errb == nil {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(va, vb)
+}
+
+// Evaluate filters, orders and pages the statuses according to the request.
+// It returns the requested page and the total number of statuses matching the filter.
+func Evaluate(all []*v1.AlgorithmStatus, req *v1.ListAlgorithmRequest) (page []*v1.AlgorithmStatus, total int, err error) {
+	err = Validate(req.Filter, req.Order, req.Start, req.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matches []*v1.AlgorithmStatus
+	for _, s := range all {
+		if MatchesFilter(s, req.Filter) {
+			matches = append(matches, s)
+		}
+	}
+	Sort(matches, req.Order)
+
+	total = len(matches)
+	start := int(req.Start)
+	if start > total {
+		start = total
+	}
+	end := total
+	if req.Limit > 0 && start+int(req.Limit) < end {
+		end = start + int(req.Limit)
+	}
+	return matches[start:end], total, nil
+}
@@ -0,0 +1,333 @@
+package query
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newStatus(name, owner, repo string, phase v1.AlgorithmPhase, created time.Time, annotations map[string]string) *v1.AlgorithmStatus {
+	var annos []*v1.Annotation
+	for k, v := range annotations {
+		annos = append(annos, &v1.Annotation{Key: k, Value: v})
+	}
+	return &v1.AlgorithmStatus{
+		Name:  name,
+		Phase: phase,
+		Metadata: &v1.AlgorithmMetadata{
+			Owner:       owner,
+			Repository:  &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: repo, Ref: "main"},
+			Trigger:     v1.AlgorithmTrigger_TRIGGER_MANUAL,
+			Created:     timestamppb.New(created),
+			Annotations: annos,
+		},
+		Conditions: &v1.AlgorithmConditions{},
+	}
+}
+
+func names(res []*v1.AlgorithmStatus) []string {
+	var r []string
+	for _, s := range res {
+		r = append(r, s.Name)
+	}
+	return r
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func term(field, value string, op v1.FilterOp, negate bool) *v1.FilterTerm {
+	return &v1.FilterTerm{Field: field, Value: value, Operation: op, Negate: negate}
+}
+
+func fixture() []*v1.AlgorithmStatus {
+	t0 := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	return []*v1.AlgorithmStatus{
+		newStatus("eod-curve.1", "alice", "finance", v1.AlgorithmPhase_PHASE_DONE, t0, map[string]string{"desk": "rates"}),
+		newStatus("eod-curve.2", "bob", "finance", v1.AlgorithmPhase_PHASE_RUNNING, t0.Add(2*time.Hour), map[string]string{"desk": "fx"}),
+		newStatus("revaluation.1", "alice", "books", v1.AlgorithmPhase_PHASE_RUNNING, t0.Add(time.Hour), nil),
+		newStatus("revaluation.2", "carol", "books", v1.AlgorithmPhase_PHASE_WAITING, t0.Add(3*time.Hour), map[string]string{"desk": ""}),
+	}
+}
+
+func TestEvaluateFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter []*v1.FilterExpression
+		want   []string
+	}{
+		{
+			name: "no filter",
+			want: []string{"eod-curve.1", "eod-curve.2", "revaluation.1", "revaluation.2"},
+		},
+		{
+			name:   "equals owner",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.owner", "alice", v1.FilterOp_OP_EQUALS, false)}}},
+			want:   []string{"eod-curve.1", "revaluation.1"},
+		},
+		{
+			name:   "equals phase",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("phase", "running", v1.FilterOp_OP_EQUALS, false)}}},
+			want:   []string{"eod-curve.2", "revaluation.1"},
+		},
+		{
+			name: "terms are AND'ed",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+				term("metadata.owner", "alice", v1.FilterOp_OP_EQUALS, false),
+				term("metadata.repository.repo", "books", v1.FilterOp_OP_EQUALS, false),
+			}}},
+			want: []string{"revaluation.1"},
+		},
+		{
+			name: "expressions are OR'ed",
+			filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{term("metadata.owner", "carol", v1.FilterOp_OP_EQUALS, false)}},
+				{Terms: []*v1.FilterTerm{term("name", "eod-curve.2", v1.FilterOp_OP_EQUALS, false)}},
+			},
+			want: []string{"eod-curve.2", "revaluation.2"},
+		},
+		{
+			name:   "starts with",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("name", "reval", v1.FilterOp_OP_STARTS_WITH, false)}}},
+			want:   []string{"revaluation.1", "revaluation.2"},
+		},
+		{
+			name:   "ends with",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("name", ".1", v1.FilterOp_OP_ENDS_WITH, false)}}},
+			want:   []string{"eod-curve.1", "revaluation.1"},
+		},
+		{
+			name:   "contains",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.annotations.desk", "a", v1.FilterOp_OP_CONTAINS, false)}}},
+			want:   []string{"eod-curve.1"},
+		},
+		{
+			name:   "annotation exists",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.annotations.desk", "", v1.FilterOp_OP_EXISTS, false)}}},
+			want:   []string{"eod-curve.1", "eod-curve.2", "revaluation.2"},
+		},
+		{
+			name:   "negated annotation exists",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.annotations.desk", "", v1.FilterOp_OP_EXISTS, true)}}},
+			want:   []string{"revaluation.1"},
+		},
+		{
+			name:   "negated equals",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.owner", "alice", v1.FilterOp_OP_EQUALS, true)}}},
+			want:   []string{"eod-curve.2", "revaluation.2"},
+		},
+		{
+			name:   "trigger",
+			filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.trigger", "manual", v1.FilterOp_OP_EQUALS, false)}}},
+			want:   []string{"eod-curve.1", "eod-curve.2", "revaluation.1", "revaluation.2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, total, err := Evaluate(fixture(), &v1.ListAlgorithmRequest{Filter: test.filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := names(res); !equal(act, test.want) {
+				t.Errorf("unexpected result: want %v, got %v", test.want, act)
+			}
+			if total != len(test.want) {
+				t.Errorf("unexpected total: want %d, got %d", len(test.want), total)
+			}
+		})
+	}
+}
+
+func TestEvaluateOrderAndPaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *v1.ListAlgorithmRequest
+		want      []string
+		wantTotal int
+	}{
+		{
+			name:      "created descending",
+			req:       &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.created"}}},
+			want:      []string{"revaluation.2", "eod-curve.2", "revaluation.1", "eod-curve.1"},
+			wantTotal: 4,
+		},
+		{
+			name: "multiple keys",
+			req: &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{
+				{Field: "metadata.owner", Ascending: true},
+				{Field: "metadata.created", Ascending: false},
+			}},
+			want:      []string{"revaluation.1", "eod-curve.1", "eod-curve.2", "revaluation.2"},
+			wantTotal: 4,
+		},
+		{
+			name:      "stable on equal keys",
+			req:       &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.repository.host", Ascending: true}}},
+			want:      []string{"eod-curve.1", "eod-curve.2", "revaluation.1", "revaluation.2"},
+			wantTotal: 4,
+		},
+		{
+			name:      "unset fields sort first",
+			req:       &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.annotations.desk", Ascending: true}}},
+			want:      []string{"revaluation.1", "revaluation.2", "eod-curve.2", "eod-curve.1"},
+			wantTotal: 4,
+		},
+		{
+			name: "start and limit",
+			req: &v1.ListAlgorithmRequest{
+				Order: []*v1.OrderExpression{{Field: "metadata.created", Ascending: true}},
+				Start: 1,
+				Limit: 2,
+			},
+			want:      []string{"revaluation.1", "eod-curve.2"},
+			wantTotal: 4,
+		},
+		{
+			name: "total counts all matches",
+			req: &v1.ListAlgorithmRequest{
+				Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("metadata.owner", "alice", v1.FilterOp_OP_EQUALS, false)}}},
+				Limit:  1,
+			},
+			want:      []string{"eod-curve.1"},
+			wantTotal: 2,
+		},
+		{
+			name:      "start beyond end",
+			req:       &v1.ListAlgorithmRequest{Start: 10},
+			want:      nil,
+			wantTotal: 4,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, total, err := Evaluate(fixture(), test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := names(res); !equal(act, test.want) {
+				t.Errorf("unexpected result: want %v, got %v", test.want, act)
+			}
+			if total != test.wantTotal {
+				t.Errorf("unexpected total: want %d, got %d", test.wantTotal, total)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *v1.ListAlgorithmRequest
+		wantErr bool
+	}{
+		{name: "empty", req: &v1.ListAlgorithmRequest{}},
+		{name: "unknown filter field", req: &v1.ListAlgorithmRequest{Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("foo", "bar", v1.FilterOp_OP_EQUALS, false)}}}}, wantErr: true},
+		{name: "unknown order field", req: &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.foo"}}}, wantErr: true},
+		{name: "empty annotation key", req: &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.annotations."}}}, wantErr: true},
+		{name: "negative start", req: &v1.ListAlgorithmRequest{Start: -1}, wantErr: true},
+		{name: "negative limit", req: &v1.ListAlgorithmRequest{Limit: -1}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(test.req.Filter, test.req.Order, test.req.Start, test.req.Limit)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFilterTerm(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    *v1.FilterTerm
+		wantErr bool
+	}{
+		{input: "phase==running", want: term("phase", "running", v1.FilterOp_OP_EQUALS, false)},
+		{input: "phase!=done", want: term("phase", "done", v1.FilterOp_OP_EQUALS, true)},
+		{input: "name~=curve", want: term("name", "curve", v1.FilterOp_OP_CONTAINS, false)},
+		{input: "name|=eod", want: term("name", "eod", v1.FilterOp_OP_STARTS_WITH, false)},
+		{input: "name=|.1", want: term("name", ".1", v1.FilterOp_OP_ENDS_WITH, false)},
+		{input: "!name~=nightly", want: term("name", "nightly", v1.FilterOp_OP_CONTAINS, true)},
+		{input: "!phase!=done", want: term("phase", "done", v1.FilterOp_OP_EQUALS, false)},
+		{input: "metadata.finished", want: term("metadata.finished", "", v1.FilterOp_OP_EXISTS, false)},
+		{input: "!metadata.annotations.desk", want: term("metadata.annotations.desk", "", v1.FilterOp_OP_EXISTS, true)},
+		{input: "metadata.annotations.expr==a!=b", want: term("metadata.annotations.expr", "a!=b", v1.FilterOp_OP_EQUALS, false)},
+		{input: "details==", want: term("details", "", v1.FilterOp_OP_EQUALS, false)},
+		{input: "==running", wantErr: true},
+		{input: "foo==bar", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			act, err := ParseFilterTerm(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseFilterTerm() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if act.Field != test.want.Field || act.Value != test.want.Value || act.Operation != test.want.Operation || act.Negate != test.want.Negate {
+				t.Errorf("unexpected term: want %v, got %v", test.want, act)
+			}
+		})
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    *v1.OrderExpression
+		wantErr bool
+	}{
+		{input: "name", want: &v1.OrderExpression{Field: "name", Ascending: true}},
+		{input: "metadata.created:desc", want: &v1.OrderExpression{Field: "metadata.created"}},
+		{input: "metadata.annotations.desk:asc", want: &v1.OrderExpression{Field: "metadata.annotations.desk", Ascending: true}},
+		{input: "name:up", wantErr: true},
+		{input: "foo:asc", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			act, err := ParseOrder(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseOrder() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if act.Field != test.want.Field || act.Ascending != test.want.Ascending {
+				t.Errorf("unexpected order: want %v, got %v", test.want, act)
+			}
+		})
+	}
+}
@@ -25,14 +25,19 @@ import (
 	"fmt"
 )
 
-var (
-	registered = map[Structure][]string{
-		&NelsonSiegelSvensson{}: []string{"b0", "b1", "b2", "b3", "t1", "t2", "spread"},
-		&Flat{}:                 []string{"r", "spread"},
-		&Spline{}:               []string{"spline", "spread"},
-	}
-)
+// registered lists the term structures Parse knows together with the keys which identify them.
+// Every call gets a structure of its own, hence Parse can be used concurrently.
+var registered = []struct {
+	keys []string
+	new  func() Structure
+}{
+	{[]string{"b0", "b1", "b2", "b3", "t1", "t2", "spread"}, func() Structure { return &NelsonSiegelSvensson{} }},
+	{[]string{"r", "spread"}, func() Structure { return &Flat{} }},
+	{[]string{"spline", "spread"}, func() Structure { return &Spline{} }},
+}
 
+// Parse reads a term structure from its JSON definition. The type of the structure is
+// determined by the keys present.
 func Parse(data []byte) (Structure, error) {
 	// unmarshal data into map[string]interface{}
 	anonymous := make(map[string]interface{})
@@ -40,25 +45,19 @@ func Parse(data []byte) (Structure, error) {
 	if err != nil {
 		return nil, err
 	}
-	for term, keys := range registered {
-		for _, key := range keys {
-			// fmt.Println("checking", key)
+nextTerm:
+	for _, reg := range registered {
+		for _, key := range reg.keys {
 			if _, ok := anonymous[key]; !ok {
-				// fmt.Println("key failed", key)
-				// outdata, _ := json.MarshalIndent(term, " ", "")
-				// fmt.Println(string(outdata))
-				goto nextTerm
+				continue nextTerm
 			}
 		}
-		// fmt.Printf("unmarshelling data for %T\n", term)
+		term := reg.new()
 		err = json.Unmarshal(data, term)
 		if err != nil {
 			return nil, err
 		}
 		return term, nil
-	nextTerm:
-		// fmt.Println("nextTerm")
 	}
 	return nil, fmt.Errorf("parsing into yield curve failed")
-
 }
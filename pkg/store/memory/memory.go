@@ -0,0 +1,251 @@
+package memory
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	"google.golang.org/protobuf/proto"
+)
+
+// Algorithms is an in-memory Algorithm store
+type Algorithms struct {
+	mu         sync.RWMutex
+	algorithms map[string]*v1.AlgorithmStatus
+	specs      map[string][]byte
+	numbers    map[string]int
+}
+
+var _ store.Algorithms = &Algorithms{}
+
+// NewAlgorithms creates a new in-memory Algorithm store
+func NewAlgorithms() *Algorithms {
+	return &Algorithms{
+		algorithms: make(map[string]*v1.AlgorithmStatus),
+		specs:      make(map[string][]byte),
+		numbers:    make(map[string]int),
+	}
+}
+
+// Store stores the status of an Algorithm
+func (s *Algorithms) Store(ctx context.Context, status *v1.AlgorithmStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.algorithms[status.Name] = proto.Clone(status).(*v1.AlgorithmStatus)
+	return nil
+}
+
+// Get retrieves the status of a single Algorithm
+func (s *Algorithms) Get(ctx context.Context, name string) (*v1.AlgorithmStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res, ok := s.algorithms[name]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return proto.Clone(res).(*v1.AlgorithmStatus), nil
+}
+
+// Find searches for Algorithms
+func (s *Algorithms) Find(ctx context.Context, filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int) (slice []*v1.AlgorithmStatus, total int, err error) {
+	s.mu.RLock()
+	all := make([]*v1.AlgorithmStatus, 0, len(s.algorithms))
+	for _, a := range s.algorithms {
+		all = append(all, proto.Clone(a).(*v1.AlgorithmStatus))
+	}
+	s.mu.RUnlock()
+
+	// query.Evaluate sorts stably, hence sorting by name first makes name the tie-breaker
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return query.Evaluate(all, &v1.ListAlgorithmRequest{
+		Filter: filter,
+		Order:  order,
+		Start:  int32(start),
+		Limit:  int32(limit),
+	})
+}
+
+// StoreSpec stores the spec an Algorithm was started from
+func (s *Algorithms) StoreSpec(ctx context.Context, name string, spec []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.specs[name] = append([]byte(nil), spec...)
+	return nil
+}
+
+// GetSpec retrieves the spec an Algorithm was started from
+func (s *Algorithms) GetSpec(ctx context.Context, name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res, ok := s.specs[name]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return append([]byte(nil), res...), nil
+}
+
+// NextNumber returns the next number in a group
+func (s *Algorithms) NextNumber(ctx context.Context, group string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.numbers[group]++
+	return s.numbers[group], nil
+}
+
+// Delete removes the status and spec of an Algorithm
+func (s *Algorithms) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.algorithms[name]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.algorithms, name)
+	delete(s.specs, name)
+	return nil
+}
+
+// Logs is an in-memory log store
+type Logs struct {
+	mu   sync.RWMutex
+	logs map[string][]byte
+}
+
+var _ store.Logs = &Logs{}
+
+// NewLogs creates a new in-memory log store
+func NewLogs() *Logs {
+	return &Logs{logs: make(map[string][]byte)}
+}
+
+// Store stores the complete log of an Algorithm
+func (s *Logs) Store(ctx context.Context, name string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logs[name] = append([]byte(nil), content...)
+	return nil
+}
+
+// Get retrieves the log of an Algorithm
+func (s *Logs) Get(ctx context.Context, name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res, ok := s.logs[name]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return append([]byte(nil), res...), nil
+}
+
+// Size returns the size of the log of an Algorithm
+func (s *Logs) Size(ctx context.Context, name string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res, ok := s.logs[name]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	return int64(len(res)), nil
+}
+
+// Delete removes the log of an Algorithm
+func (s *Logs) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.logs[name]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.logs, name)
+	return nil
+}
+
+// Schedules is an in-memory schedule store
+type Schedules struct {
+	mu        sync.RWMutex
+	schedules map[string]*v1.Schedule
+}
+
+var _ store.Schedules = &Schedules{}
+
+// NewSchedules creates a new in-memory schedule store
+func NewSchedules() *Schedules {
+	return &Schedules{schedules: make(map[string]*v1.Schedule)}
+}
+
+// Store stores a schedule
+func (s *Schedules) Store(ctx context.Context, schedule *v1.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.schedules[schedule.Name] = proto.Clone(schedule).(*v1.Schedule)
+	return nil
+}
+
+// Get retrieves a single schedule
+func (s *Schedules) Get(ctx context.Context, name string) (*v1.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res, ok := s.schedules[name]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return proto.Clone(res).(*v1.Schedule), nil
+}
+
+// List returns all schedules ordered by name
+func (s *Schedules) List(ctx context.Context) ([]*v1.Schedule, error) {
+	s.mu.RLock()
+	res := make([]*v1.Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		res = append(res, proto.Clone(sched).(*v1.Schedule))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// Delete removes a schedule
+func (s *Schedules) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.schedules[name]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.schedules, name)
+	return nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migration is a single schema change
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// loadMigrations returns all migrations ordered by their version. Migration files are
+// named "<version>_<description>.sql".
+func loadMigrations() ([]migration, error) {
+	entries, err := migrations.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]migration, 0, len(entries))
+	for _, e := range entries {
+		segs := strings.SplitN(e.Name(), "_", 2)
+		version, err := strconv.Atoi(segs[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration name %s: %w", e.Name(), err)
+		}
+		content, err := migrations.ReadFile(path.Join("migrations", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, migration{Version: version, Name: e.Name(), SQL: string(content)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// Migrate brings the database schema up to date. Migrations which have been applied
+// before are skipped, so it's safe to call Migrate on every start.
+func Migrate(db *sql.DB) error {
+	ms, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer PRIMARY KEY,
+		applied timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return fmt.Errorf("cannot create migrations table: %w", err)
+	}
+
+	for _, m := range ms {
+		err := applyMigration(db, m)
+		if err != nil {
+			return fmt.Errorf("cannot apply migration %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// the lock makes concurrently starting instances wait for each other
+	_, err = tx.Exec(`LOCK TABLE schema_migrations IN EXCLUSIVE MODE`)
+	if err != nil {
+		return err
+	}
+	var applied bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, m.Version).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return tx.Commit()
+	}
+
+	_, err = tx.Exec(m.SQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, m.Version)
+	if err != nil {
+		return err
+	}
+	log.WithField("migration", m.Name).Info("applied database migration")
+	return tx.Commit()
+}
@@ -0,0 +1,52 @@
+-- algorithm_status holds the status of all Algorithms. The full status is kept as
+-- protobuf in data, all fields ListAlgorithm can filter and order on are extracted
+-- into columns of their own.
+CREATE TABLE algorithm_status (
+    name          text PRIMARY KEY,
+    data          bytea NOT NULL,
+    phase         text NOT NULL,
+    details       text,
+    owner         text,
+    spec_name     text,
+    trigger       text,
+    created       timestamptz,
+    finished      timestamptz,
+    repo_host     text,
+    repo_owner    text,
+    repo_repo     text,
+    repo_ref      text,
+    repo_revision text,
+    success       boolean,
+    failure_count integer,
+    can_replay    boolean,
+    did_execute   boolean,
+    wait_until    timestamptz
+);
+CREATE INDEX algorithm_status_phase ON algorithm_status (phase);
+CREATE INDEX algorithm_status_owner ON algorithm_status (owner);
+CREATE INDEX algorithm_status_spec_name ON algorithm_status (spec_name);
+CREATE INDEX algorithm_status_created ON algorithm_status (created);
+CREATE INDEX algorithm_status_repo ON algorithm_status (repo_owner, repo_repo, repo_ref);
+
+CREATE TABLE annotation (
+    algorithm_name text NOT NULL REFERENCES algorithm_status (name) ON DELETE CASCADE,
+    key            text NOT NULL,
+    value          text NOT NULL,
+    PRIMARY KEY (algorithm_name, key)
+);
+CREATE INDEX annotation_key_value ON annotation (key, value);
+
+CREATE TABLE algorithm_spec (
+    name text PRIMARY KEY,
+    data bytea NOT NULL
+);
+
+CREATE TABLE algorithm_log (
+    name text PRIMARY KEY,
+    data bytea NOT NULL
+);
+
+CREATE TABLE number_group (
+    name text PRIMARY KEY,
+    val  integer NOT NULL
+);
@@ -0,0 +1,5 @@
+-- schedule holds the schedules which start Algorithms on a cron calendar, kept as protobuf
+CREATE TABLE schedule (
+    name text PRIMARY KEY,
+    data bytea NOT NULL
+);
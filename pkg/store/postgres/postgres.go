@@ -0,0 +1,412 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Algorithms stores Algorithms in a PostgreSQL database
+type Algorithms struct {
+	DB *sql.DB
+}
+
+var _ store.Algorithms = &Algorithms{}
+
+// NewAlgorithms creates a new PostgreSQL-backed Algorithm store. The database schema
+// must have been brought up to date using Migrate.
+func NewAlgorithms(db *sql.DB) *Algorithms {
+	return &Algorithms{DB: db}
+}
+
+// Store stores the status of an Algorithm
+func (s *Algorithms) Store(ctx context.Context, status *v1.AlgorithmStatus) (err error) {
+	data, err := proto.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		md    = status.GetMetadata()
+		repo  = md.GetRepository()
+		conds = status.GetConditions()
+	)
+	var trigger, failureCount, success, canReplay, didExecute interface{}
+	if md != nil {
+		trigger = query.TriggerString(md.Trigger)
+	}
+	if conds != nil {
+		failureCount = conds.FailureCount
+		success = conds.Success
+		canReplay = conds.CanReplay
+		didExecute = conds.DidExecute
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO algorithm_status (
+			name, data, phase, details, owner, spec_name, trigger, created, finished,
+			repo_host, repo_owner, repo_repo, repo_ref, repo_revision,
+			success, failure_count, can_replay, did_execute, wait_until
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (name) DO UPDATE SET
+			data = EXCLUDED.data,
+			phase = EXCLUDED.phase,
+			details = EXCLUDED.details,
+			owner = EXCLUDED.owner,
+			spec_name = EXCLUDED.spec_name,
+			trigger = EXCLUDED.trigger,
+			created = EXCLUDED.created,
+			finished = EXCLUDED.finished,
+			repo_host = EXCLUDED.repo_host,
+			repo_owner = EXCLUDED.repo_owner,
+			repo_repo = EXCLUDED.repo_repo,
+			repo_ref = EXCLUDED.repo_ref,
+			repo_revision = EXCLUDED.repo_revision,
+			success = EXCLUDED.success,
+			failure_count = EXCLUDED.failure_count,
+			can_replay = EXCLUDED.can_replay,
+			did_execute = EXCLUDED.did_execute,
+			wait_until = EXCLUDED.wait_until`,
+		status.Name, data, query.PhaseString(status.Phase), nullString(status.Details),
+		nullString(md.GetOwner()), nullString(md.GetAlgorithmSpecName()), trigger,
+		nullTime(md.GetCreated()), nullTime(md.GetFinished()),
+		nullString(repo.GetHost()), nullString(repo.GetOwner()), nullString(repo.GetRepo()), nullString(repo.GetRef()), nullString(repo.GetRevision()),
+		success, failureCount, canReplay, didExecute, nullTime(conds.GetWaitUntil()),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot store algorithm %s: %w", status.Name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM annotation WHERE algorithm_name = $1`, status.Name)
+	if err != nil {
+		return err
+	}
+	for _, a := range md.GetAnnotations() {
+		// the first annotation of a key wins, like it does in the query package
+		_, err = tx.ExecContext(ctx, `INSERT INTO annotation (algorithm_name, key, value) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, status.Name, a.Key, a.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves the status of a single Algorithm
+func (s *Algorithms) Get(ctx context.Context, name string) (*v1.AlgorithmStatus, error) {
+	var data []byte
+	err := s.DB.QueryRowContext(ctx, `SELECT data FROM algorithm_status WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalStatus(data)
+}
+
+// Find searches for Algorithms
+func (s *Algorithms) Find(ctx context.Context, filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int) (slice []*v1.AlgorithmStatus, total int, err error) {
+	var q sqlQuery
+	where, err := q.Where(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = s.DB.QueryRowContext(ctx, `SELECT count(*) FROM algorithm_status WHERE `+where, q.Args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orderBy, err := q.OrderBy(order)
+	if err != nil {
+		return nil, 0, err
+	}
+	stmt := `SELECT data FROM algorithm_status WHERE ` + where + ` ORDER BY ` + orderBy + ` OFFSET ` + q.arg(start)
+	if limit > 0 {
+		stmt += ` LIMIT ` + q.arg(limit)
+	}
+	rows, err := s.DB.QueryContext(ctx, stmt, q.Args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, err
+		}
+		status, err := unmarshalStatus(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		slice = append(slice, status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return slice, total, nil
+}
+
+// StoreSpec stores the spec an Algorithm was started from
+func (s *Algorithms) StoreSpec(ctx context.Context, name string, spec []byte) error {
+	_, err := s.DB.ExecContext(ctx, `INSERT INTO algorithm_spec (name, data) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data`, name, spec)
+	return err
+}
+
+// GetSpec retrieves the spec an Algorithm was started from
+func (s *Algorithms) GetSpec(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := s.DB.QueryRowContext(ctx, `SELECT data FROM algorithm_spec WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// NextNumber returns the next number in a group
+func (s *Algorithms) NextNumber(ctx context.Context, group string) (int, error) {
+	var res int
+	err := s.DB.QueryRowContext(ctx, `
+		INSERT INTO number_group (name, val) VALUES ($1, 1)
+		ON CONFLICT (name) DO UPDATE SET val = number_group.val + 1
+		RETURNING val`, group).Scan(&res)
+	if err != nil {
+		return 0, err
+	}
+	return res, nil
+}
+
+// Delete removes the status and spec of an Algorithm. Its annotations are removed
+// by the foreign key.
+func (s *Algorithms) Delete(ctx context.Context, name string) (err error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM algorithm_status WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if err = expectAffected(res); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM algorithm_spec WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Logs stores the log output of finished Algorithms in a PostgreSQL database
+type Logs struct {
+	DB *sql.DB
+}
+
+var _ store.Logs = &Logs{}
+
+// NewLogs creates a new PostgreSQL-backed log store. The database schema
+// must have been brought up to date using Migrate.
+func NewLogs(db *sql.DB) *Logs {
+	return &Logs{DB: db}
+}
+
+// Store stores the complete log of an Algorithm
+func (s *Logs) Store(ctx context.Context, name string, content []byte) error {
+	_, err := s.DB.ExecContext(ctx, `INSERT INTO algorithm_log (name, data) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data`, name, content)
+	return err
+}
+
+// Get retrieves the log of an Algorithm
+func (s *Logs) Get(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := s.DB.QueryRowContext(ctx, `SELECT data FROM algorithm_log WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Size returns the size of the log of an Algorithm
+func (s *Logs) Size(ctx context.Context, name string) (int64, error) {
+	var size int64
+	err := s.DB.QueryRowContext(ctx, `SELECT octet_length(data) FROM algorithm_log WHERE name = $1`, name).Scan(&size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, store.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Delete removes the log of an Algorithm
+func (s *Logs) Delete(ctx context.Context, name string) error {
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM algorithm_log WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Schedules stores the schedules of Algorithms in a PostgreSQL database
+type Schedules struct {
+	DB *sql.DB
+}
+
+var _ store.Schedules = &Schedules{}
+
+// NewSchedules creates a new PostgreSQL-backed schedule store. The database schema
+// must have been brought up to date using Migrate.
+func NewSchedules(db *sql.DB) *Schedules {
+	return &Schedules{DB: db}
+}
+
+// Store stores a schedule
+func (s *Schedules) Store(ctx context.Context, schedule *v1.Schedule) error {
+	data, err := proto.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.ExecContext(ctx, `INSERT INTO schedule (name, data) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data`, schedule.Name, data)
+	if err != nil {
+		return fmt.Errorf("cannot store schedule %s: %w", schedule.Name, err)
+	}
+	return nil
+}
+
+// Get retrieves a single schedule
+func (s *Schedules) Get(ctx context.Context, name string) (*v1.Schedule, error) {
+	var data []byte
+	err := s.DB.QueryRowContext(ctx, `SELECT data FROM schedule WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalSchedule(data)
+}
+
+// List returns all schedules ordered by name
+func (s *Schedules) List(ctx context.Context) ([]*v1.Schedule, error) {
+	rows, err := s.DB.QueryContext(ctx, `SELECT data FROM schedule ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*v1.Schedule
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		sched, err := unmarshalSchedule(data)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, sched)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Delete removes a schedule
+func (s *Schedules) Delete(ctx context.Context, name string) error {
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM schedule WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// expectAffected returns ErrNotFound if a statement did not affect any row
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func unmarshalSchedule(data []byte) (*v1.Schedule, error) {
+	var res v1.Schedule
+	if err := proto.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func unmarshalStatus(data []byte) (*v1.AlgorithmStatus, error) {
+	var res v1.AlgorithmStatus
+	if err := proto.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(ts *timestamppb.Timestamp) sql.NullTime {
+	if ts == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: ts.AsTime(), Valid: true}
+}
@@ -0,0 +1,196 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+const annotationPrefix = "metadata.annotations."
+
+// columnKind determines how a column is compared to filter values and ordered
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindBool
+	kindInt
+	kindTime
+)
+
+type column struct {
+	Name string
+	Kind columnKind
+}
+
+// columns maps the fields understood by the query package to table columns
+var columns = map[string]column{
+	"name":                         {"name", kindText},
+	"phase":                        {"phase", kindText},
+	"details":                      {"details", kindText},
+	"metadata.owner":               {"owner", kindText},
+	"metadata.algorithm_spec_name": {"spec_name", kindText},
+	"metadata.trigger":             {"trigger", kindText},
+	"metadata.created":             {"created", kindTime},
+	"metadata.finished":            {"finished", kindTime},
+	"metadata.repository.host":     {"repo_host", kindText},
+	"metadata.repository.owner":    {"repo_owner", kindText},
+	"metadata.repository.repo":     {"repo_repo", kindText},
+	"metadata.repository.ref":      {"repo_ref", kindText},
+	"metadata.repository.revision": {"repo_revision", kindText},
+	"conditions.success":           {"success", kindBool},
+	"conditions.failure_count":     {"failure_count", kindInt},
+	"conditions.can_replay":        {"can_replay", kindBool},
+	"conditions.did_execute":       {"did_execute", kindBool},
+	"conditions.wait_until":        {"wait_until", kindTime},
+}
+
+// textExpr returns an SQL expression which renders the column the same way the query package renders the field
+func (c column) textExpr() string {
+	switch c.Kind {
+	case kindBool, kindInt:
+		return c.Name + "::text"
+	case kindTime:
+		return `to_char(` + c.Name + ` AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"000Z"')`
+	default:
+		return c.Name
+	}
+}
+
+// orderExpr returns an SQL expression by which the column is ordered
+func (c column) orderExpr() string {
+	if c.Kind == kindText {
+		return c.Name + ` COLLATE "C"`
+	}
+	return c.Name
+}
+
+// sqlQuery accumulates the clauses and arguments of an SQL query
+type sqlQuery struct {
+	Args []interface{}
+}
+
+func (q *sqlQuery) arg(v interface{}) string {
+	q.Args = append(q.Args, v)
+	return fmt.Sprintf("$%d", len(q.Args))
+}
+
+// Where translates a filter into an SQL condition
+func (q *sqlQuery) Where(filter []*v1.FilterExpression) (string, error) {
+	if len(filter) == 0 {
+		return "TRUE", nil
+	}
+
+	exprs := make([]string, 0, len(filter))
+	for _, expr := range filter {
+		if len(expr.Terms) == 0 {
+			exprs = append(exprs, "TRUE")
+			continue
+		}
+		terms := make([]string, 0, len(expr.Terms))
+		for _, term := range expr.Terms {
+			t, err := q.term(term)
+			if err != nil {
+				return "", err
+			}
+			terms = append(terms, t)
+		}
+		exprs = append(exprs, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(exprs, " OR ") + ")", nil
+}
+
+func (q *sqlQuery) term(term *v1.FilterTerm) (string, error) {
+	var res string
+	if strings.HasPrefix(term.Field, annotationPrefix) {
+		key := q.arg(strings.TrimPrefix(term.Field, annotationPrefix))
+		cond := "TRUE"
+		if term.Operation != v1.FilterOp_OP_EXISTS {
+			cond = q.compare("a.value", term)
+		}
+		res = fmt.Sprintf("EXISTS (SELECT 1 FROM annotation a WHERE a.algorithm_name = algorithm_status.name AND a.key = %s AND %s)", key, cond)
+	} else {
+		col, ok := columns[term.Field]
+		if !ok {
+			return "", fmt.Errorf("unknown field %s", term.Field)
+		}
+		if term.Operation == v1.FilterOp_OP_EXISTS {
+			res = col.Name + " IS NOT NULL"
+		} else {
+			// comparisons with unset (NULL) columns don't match, hence negating them does
+			res = "COALESCE(" + q.compare(col.textExpr(), term) + ", FALSE)"
+		}
+	}
+
+	if term.Negate {
+		res = "NOT " + res
+	}
+	return res, nil
+}
+
+func (q *sqlQuery) compare(expr string, term *v1.FilterTerm) string {
+	switch term.Operation {
+	case v1.FilterOp_OP_STARTS_WITH:
+		return expr + " LIKE " + q.arg(escapeLike(term.Value)+"%")
+	case v1.FilterOp_OP_ENDS_WITH:
+		return expr + " LIKE " + q.arg("%"+escapeLike(term.Value))
+	case v1.FilterOp_OP_CONTAINS:
+		return expr + " LIKE " + q.arg("%"+escapeLike(term.Value)+"%")
+	default:
+		return expr + " = " + q.arg(term.Value)
+	}
+}
+
+// OrderBy translates the order expressions into an SQL ORDER BY list. Unset fields come
+// first in ascending order, and name is the final tie-breaker.
+func (q *sqlQuery) OrderBy(order []*v1.OrderExpression) (string, error) {
+	res := make([]string, 0, len(order)+1)
+	for _, o := range order {
+		var expr string
+		if strings.HasPrefix(o.Field, annotationPrefix) {
+			key := q.arg(strings.TrimPrefix(o.Field, annotationPrefix))
+			expr = fmt.Sprintf(`(SELECT a.value FROM annotation a WHERE a.algorithm_name = algorithm_status.name AND a.key = %s) COLLATE "C"`, key)
+		} else {
+			col, ok := columns[o.Field]
+			if !ok {
+				return "", fmt.Errorf("unknown field %s", o.Field)
+			}
+			expr = col.orderExpr()
+		}
+
+		if o.Ascending {
+			res = append(res, expr+" ASC NULLS FIRST")
+		} else {
+			res = append(res, expr+" DESC NULLS LAST")
+		}
+	}
+	res = append(res, `name COLLATE "C" ASC`)
+	return strings.Join(res, ", "), nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -0,0 +1,111 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   []*v1.FilterExpression
+		want     string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name: "no filter",
+			want: "TRUE",
+		},
+		{
+			name:     "equals",
+			filter:   []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "metadata.owner", Value: "alice"}}}},
+			want:     "((COALESCE(owner = $1, FALSE)))",
+			wantArgs: []interface{}{"alice"},
+		},
+		{
+			name: "terms and expressions",
+			filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{
+					{Field: "phase", Value: "running"},
+					{Field: "conditions.failure_count", Value: "1", Negate: true},
+				}},
+				{Terms: []*v1.FilterTerm{{Field: "metadata.finished", Operation: v1.FilterOp_OP_EXISTS}}},
+			},
+			want:     "((COALESCE(phase = $1, FALSE) AND NOT COALESCE(failure_count::text = $2, FALSE)) OR (finished IS NOT NULL))",
+			wantArgs: []interface{}{"running", "1"},
+		},
+		{
+			name:     "escapes wildcards",
+			filter:   []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "details", Value: `50%_\`, Operation: v1.FilterOp_OP_STARTS_WITH}}}},
+			want:     "((COALESCE(details LIKE $1, FALSE)))",
+			wantArgs: []interface{}{`50\%\_\\%`},
+		},
+		{
+			name:     "annotation",
+			filter:   []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "metadata.annotations.desk", Value: "fx", Operation: v1.FilterOp_OP_CONTAINS}}}},
+			want:     "((EXISTS (SELECT 1 FROM annotation a WHERE a.algorithm_name = algorithm_status.name AND a.key = $1 AND a.value LIKE $2)))",
+			wantArgs: []interface{}{"desk", "%fx%"},
+		},
+		{
+			name:    "unknown field",
+			filter:  []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "foo"}}}},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var q sqlQuery
+			act, err := q.Where(test.filter)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Where() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if act != test.want {
+				t.Errorf("unexpected condition:\nwant %s\n got %s", test.want, act)
+			}
+			if !reflect.DeepEqual(q.Args, test.wantArgs) {
+				t.Errorf("unexpected args: want %v, got %v", test.wantArgs, q.Args)
+			}
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	var q sqlQuery
+	act, err := q.OrderBy([]*v1.OrderExpression{
+		{Field: "metadata.created", Ascending: true},
+		{Field: "metadata.owner"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `created ASC NULLS FIRST, owner COLLATE "C" DESC NULLS LAST, name COLLATE "C" ASC`
+	if act != want {
+		t.Errorf("unexpected order:\nwant %s\n got %s", want, act)
+	}
+}
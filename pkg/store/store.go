@@ -0,0 +1,97 @@
+package store
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+// ErrNotFound is returned by Get if the item is not in the store
+var ErrNotFound = errors.New("not found")
+
+// Algorithms stores the status and spec of Algorithms
+type Algorithms interface {
+	// Store stores the status of an Algorithm, replacing any previous status of the same name
+	Store(ctx context.Context, status *v1.AlgorithmStatus) error
+
+	// Get retrieves the status of a single Algorithm.
+	// If no Algorithm with that name exists, ErrNotFound is returned.
+	Get(ctx context.Context, name string) (*v1.AlgorithmStatus, error)
+
+	// Find searches for Algorithms matching the filter and returns them ordered by order.
+	// At most limit results are returned, skipping the first start matches. A limit of zero
+	// means there's no limit. Matches with equal order keys are ordered by name. Find also
+	// returns the total number of matches. Callers are expected to validate the filter and
+	// order using query.Validate.
+	Find(ctx context.Context, filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int) (slice []*v1.AlgorithmStatus, total int, err error)
+
+	// StoreSpec stores the spec an Algorithm was started from
+	StoreSpec(ctx context.Context, name string, spec []byte) error
+
+	// GetSpec retrieves the spec an Algorithm was started from.
+	// If no spec was stored for that Algorithm, ErrNotFound is returned.
+	GetSpec(ctx context.Context, name string) ([]byte, error)
+
+	// NextNumber returns the next number in a group. Numbers start at 1.
+	NextNumber(ctx context.Context, group string) (int, error)
+
+	// Delete removes the status and spec of an Algorithm. Numbers already handed out are
+	// not reused. If no Algorithm with that name exists, ErrNotFound is returned.
+	Delete(ctx context.Context, name string) error
+}
+
+// Logs stores the log output of finished Algorithms
+type Logs interface {
+	// Store stores the complete log of an Algorithm, replacing any previous log
+	Store(ctx context.Context, name string, content []byte) error
+
+	// Get retrieves the log of an Algorithm.
+	// If no log was stored for that Algorithm, ErrNotFound is returned.
+	Get(ctx context.Context, name string) ([]byte, error)
+
+	// Size returns the size of the log of an Algorithm in bytes.
+	// If no log was stored for that Algorithm, ErrNotFound is returned.
+	Size(ctx context.Context, name string) (int64, error)
+
+	// Delete removes the log of an Algorithm.
+	// If no log was stored for that Algorithm, ErrNotFound is returned.
+	Delete(ctx context.Context, name string) error
+}
+
+// Schedules stores the schedules which start Algorithms on a cron calendar
+type Schedules interface {
+	// Store stores a schedule, replacing any previous schedule of the same name
+	Store(ctx context.Context, schedule *v1.Schedule) error
+
+	// Get retrieves a single schedule.
+	// If no schedule with that name exists, ErrNotFound is returned.
+	Get(ctx context.Context, name string) (*v1.Schedule, error)
+
+	// List returns all schedules ordered by name
+	List(ctx context.Context) ([]*v1.Schedule, error)
+
+	// Delete removes a schedule.
+	// If no schedule with that name exists, ErrNotFound is returned.
+	Delete(ctx context.Context, name string) error
+}
@@ -0,0 +1,568 @@
+package storetest
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/query"
+	"github.com/bhojpur/finance/pkg/store"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RunAlgorithmsTests runs the conformance tests every Algorithm store must pass.
+// newStore is expected to return an empty store on every call.
+func RunAlgorithmsTests(t *testing.T, newStore func(t *testing.T) store.Algorithms) {
+	t.Run("store and get", func(t *testing.T) { testStoreAndGet(t, newStore(t)) })
+	t.Run("store replaces", func(t *testing.T) { testStoreReplaces(t, newStore(t)) })
+	t.Run("specs", func(t *testing.T) { testSpecs(t, newStore(t)) })
+	t.Run("next number", func(t *testing.T) { testNextNumber(t, newStore(t)) })
+	t.Run("find", func(t *testing.T) { testFind(t, newStore(t)) })
+	t.Run("delete", func(t *testing.T) { testDelete(t, newStore(t)) })
+}
+
+// RunLogsTests runs the conformance tests every log store must pass.
+// newStore is expected to return an empty store on every call.
+func RunLogsTests(t *testing.T, newStore func(t *testing.T) store.Logs) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	_, err := s.Get(ctx, "does-not-exist.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown log, got %v", err)
+	}
+
+	content := []byte("[build|PHASE] compiling\n[build] some output\n[build|DONE]\n")
+	for _, c := range [][]byte{content[:10], content} {
+		err = s.Store(ctx, "eod-curve.1", c)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	act, err := s.Get(ctx, "eod-curve.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(act, content) {
+		t.Errorf("unexpected log: want %q, got %q", content, act)
+	}
+
+	size, err := s.Size(ctx, "eod-curve.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(content)) {
+		t.Errorf("unexpected log size: want %d, got %d", len(content), size)
+	}
+	_, err = s.Size(ctx, "does-not-exist.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the size of an unknown log, got %v", err)
+	}
+
+	err = s.Delete(ctx, "eod-curve.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Get(ctx, "eod-curve.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted log, got %v", err)
+	}
+	err = s.Delete(ctx, "eod-curve.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound when deleting an unknown log, got %v", err)
+	}
+}
+
+// RunSchedulesTests runs the conformance tests every schedule store must pass.
+// newStore is expected to return an empty store on every call.
+func RunSchedulesTests(t *testing.T, newStore func(t *testing.T) store.Schedules) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	_, err := s.Get(ctx, "nightly-revaluation")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown schedule, got %v", err)
+	}
+	err = s.Delete(ctx, "nightly-revaluation")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound when deleting an unknown schedule, got %v", err)
+	}
+
+	revaluation := &v1.Schedule{
+		Name:              "nightly-revaluation",
+		Cron:              "0 22 * * mon-fri",
+		TimeZone:          "Asia/Kolkata",
+		AlgorithmSpecName: "revaluation",
+		Annotations:       []*v1.Annotation{{Key: "desk", Value: "rates"}},
+		ConcurrencyPolicy: v1.ConcurrencyPolicy_CONCURRENCY_FORBID,
+		Owner:             "foo",
+	}
+	curve := &v1.Schedule{Name: "eod-curve", Cron: "@daily", AlgorithmSpecName: "eod-curve"}
+	for _, sched := range []*v1.Schedule{revaluation, curve} {
+		if err := s.Store(ctx, sched); err != nil {
+			t.Fatal(err)
+		}
+	}
+	revaluation.Status = &v1.ScheduleStatus{LastAlgorithm: "revaluation.1", NextRun: timestamppb.New(time.Date(2022, 3, 1, 16, 30, 0, 0, time.UTC))}
+	if err := s.Store(ctx, revaluation); err != nil {
+		t.Fatal(err)
+	}
+
+	act, err := s.Get(ctx, revaluation.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(act, revaluation) {
+		t.Errorf("unexpected schedule: want %v, got %v", revaluation, act)
+	}
+
+	all, err := s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Name != "eod-curve" || all[1].Name != "nightly-revaluation" {
+		t.Errorf("unexpected schedules: %v", all)
+	}
+
+	if err := s.Delete(ctx, curve.Name); err != nil {
+		t.Fatal(err)
+	}
+	all, err = s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Name != "nightly-revaluation" {
+		t.Errorf("unexpected schedules after delete: %v", all)
+	}
+}
+
+func testStoreAndGet(t *testing.T, s store.Algorithms) {
+	ctx := context.Background()
+
+	_, err := s.Get(ctx, "does-not-exist.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown algorithm, got %v", err)
+	}
+
+	for _, status := range fixture() {
+		err := s.Store(ctx, status)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, status := range fixture() {
+		act, err := s.Get(ctx, status.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(act, status) {
+			t.Errorf("unexpected status for %s: want %v, got %v", status.Name, status, act)
+		}
+	}
+}
+
+func testStoreReplaces(t *testing.T, s store.Algorithms) {
+	ctx := context.Background()
+
+	status := fixture()[1]
+	err := s.Store(ctx, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status.Phase = v1.AlgorithmPhase_PHASE_DONE
+	status.Metadata.Finished = timestamppb.New(status.Metadata.Created.AsTime().Add(time.Minute))
+	status.Metadata.Annotations = []*v1.Annotation{{Key: "desk", Value: "credit"}}
+	err = s.Store(ctx, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	act, err := s.Get(ctx, status.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(act, status) {
+		t.Errorf("unexpected status: want %v, got %v", status, act)
+	}
+
+	// the indexed fields must have been replaced as well
+	res, total, err := s.Find(ctx, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+		term("phase", "done", v1.FilterOp_OP_EQUALS, false),
+		term("metadata.annotations.desk", "credit", v1.FilterOp_OP_EQUALS, false),
+	}}}, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(res) != 1 || res[0].Name != status.Name {
+		t.Errorf("expected to find %s after replacing it, got %v (total %d)", status.Name, names(res), total)
+	}
+}
+
+func testSpecs(t *testing.T, s store.Algorithms) {
+	ctx := context.Background()
+
+	_, err := s.GetSpec(ctx, "does-not-exist.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown spec, got %v", err)
+	}
+
+	spec := []byte("description: end of day curve\n")
+	err = s.StoreSpec(ctx, "eod-curve.1", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	act, err := s.GetSpec(ctx, "eod-curve.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(act, spec) {
+		t.Errorf("unexpected spec: want %q, got %q", spec, act)
+	}
+}
+
+func testNextNumber(t *testing.T, s store.Algorithms) {
+	ctx := context.Background()
+
+	for _, group := range []string{"eod-curve", "revaluation"} {
+		for want := 1; want <= 3; want++ {
+			act, err := s.NextNumber(ctx, group)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act != want {
+				t.Errorf("unexpected number in group %s: want %d, got %d", group, want, act)
+			}
+		}
+	}
+}
+
+func testDelete(t *testing.T, s store.Algorithms) {
+	ctx := context.Background()
+
+	err := s.Delete(ctx, "does-not-exist.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound when deleting an unknown algorithm, got %v", err)
+	}
+
+	created := time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, status := range []*v1.AlgorithmStatus{
+		newStatus("eod-curve.1", "foo", "finance", v1.AlgorithmPhase_PHASE_DONE, created, map[string]string{"curve": "EUR"}),
+		newStatus("eod-curve.2", "foo", "finance", v1.AlgorithmPhase_PHASE_DONE, created, map[string]string{"curve": "EUR"}),
+	} {
+		if err := s.Store(ctx, status); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.StoreSpec(ctx, status.Name, []byte("description: end of day curve\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = s.Delete(ctx, "eod-curve.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Get(ctx, "eod-curve.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted algorithm, got %v", err)
+	}
+	_, err = s.GetSpec(ctx, "eod-curve.1")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected the spec of a deleted algorithm to be gone, got %v", err)
+	}
+
+	res, total, err := s.Find(ctx, filter(term("metadata.annotations.curve", "EUR", v1.FilterOp_OP_EQUALS, false)), nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || !equal(names(res), []string{"eod-curve.2"}) {
+		t.Errorf("expected only eod-curve.2 to remain, got %v (total %d)", names(res), total)
+	}
+	if _, err := s.GetSpec(ctx, "eod-curve.2"); err != nil {
+		t.Errorf("expected the spec of eod-curve.2 to remain: %v", err)
+	}
+}
+
+// testFind checks the store against the reference implementation in the query package
+func testFind(t *testing.T, s store.Algorithms) {
+	ctx := context.Background()
+	for _, status := range fixture() {
+		err := s.Store(ctx, status)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		req   *v1.ListAlgorithmRequest
+		want  []string
+		total int
+	}{
+		{
+			name:  "no filter",
+			req:   &v1.ListAlgorithmRequest{},
+			want:  []string{"eod-curve.1", "eod-curve.2", "revaluation.1", "revaluation.2", "settlement.1"},
+			total: 5,
+		},
+		{
+			name:  "equals phase",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("phase", "running", v1.FilterOp_OP_EQUALS, false))},
+			want:  []string{"eod-curve.2", "revaluation.1"},
+			total: 2,
+		},
+		{
+			name: "terms are AND'ed",
+			req: &v1.ListAlgorithmRequest{Filter: filter(
+				term("metadata.owner", "alice", v1.FilterOp_OP_EQUALS, false),
+				term("metadata.repository.repo", "books", v1.FilterOp_OP_EQUALS, false),
+			)},
+			want:  []string{"revaluation.1"},
+			total: 1,
+		},
+		{
+			name: "expressions are OR'ed",
+			req: &v1.ListAlgorithmRequest{Filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{term("metadata.owner", "carol", v1.FilterOp_OP_EQUALS, false)}},
+				{Terms: []*v1.FilterTerm{term("name", "eod-curve.2", v1.FilterOp_OP_EQUALS, false)}},
+			}},
+			want:  []string{"eod-curve.2", "revaluation.2"},
+			total: 2,
+		},
+		{
+			name:  "starts with",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("name", "reval", v1.FilterOp_OP_STARTS_WITH, false))},
+			want:  []string{"revaluation.1", "revaluation.2"},
+			total: 2,
+		},
+		{
+			name:  "wildcards are matched literally",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("details", "100%_", v1.FilterOp_OP_CONTAINS, false))},
+			want:  []string{"settlement.1"},
+			total: 1,
+		},
+		{
+			name:  "ends with",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("name", ".1", v1.FilterOp_OP_ENDS_WITH, false))},
+			want:  []string{"eod-curve.1", "revaluation.1", "settlement.1"},
+			total: 3,
+		},
+		{
+			name:  "annotation contains",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("metadata.annotations.desk", "a", v1.FilterOp_OP_CONTAINS, false))},
+			want:  []string{"eod-curve.1"},
+			total: 1,
+		},
+		{
+			name:  "annotation exists",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("metadata.annotations.desk", "", v1.FilterOp_OP_EXISTS, false))},
+			want:  []string{"eod-curve.1", "eod-curve.2", "revaluation.2"},
+			total: 3,
+		},
+		{
+			name:  "negated exists",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("metadata.finished", "", v1.FilterOp_OP_EXISTS, true))},
+			want:  []string{"eod-curve.2", "revaluation.1", "revaluation.2"},
+			total: 3,
+		},
+		{
+			name:  "negated equals matches unset fields",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("conditions.success", "true", v1.FilterOp_OP_EQUALS, true))},
+			want:  []string{"eod-curve.2", "revaluation.1", "revaluation.2", "settlement.1"},
+			total: 4,
+		},
+		{
+			name:  "equals time",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("metadata.created", "2022-03-01T11:00:00.000000000Z", v1.FilterOp_OP_EQUALS, false))},
+			want:  []string{"revaluation.1"},
+			total: 1,
+		},
+		{
+			name:  "time prefix",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("metadata.created", "2022-03-02", v1.FilterOp_OP_STARTS_WITH, false))},
+			want:  []string{"settlement.1"},
+			total: 1,
+		},
+		{
+			name:  "equals number",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("conditions.failure_count", "12", v1.FilterOp_OP_EQUALS, false))},
+			want:  []string{"settlement.1"},
+			total: 1,
+		},
+		{
+			name:  "trigger",
+			req:   &v1.ListAlgorithmRequest{Filter: filter(term("metadata.trigger", "push", v1.FilterOp_OP_EQUALS, false))},
+			want:  []string{"settlement.1"},
+			total: 1,
+		},
+		{
+			name:  "created descending",
+			req:   &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.created"}}},
+			want:  []string{"settlement.1", "revaluation.2", "eod-curve.2", "revaluation.1", "eod-curve.1"},
+			total: 5,
+		},
+		{
+			name: "multiple keys",
+			req: &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{
+				{Field: "metadata.owner", Ascending: true},
+				{Field: "metadata.created", Ascending: false},
+			}},
+			want:  []string{"revaluation.1", "eod-curve.1", "settlement.1", "eod-curve.2", "revaluation.2"},
+			total: 5,
+		},
+		{
+			name:  "failure count is ordered numerically",
+			req:   &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "conditions.failure_count", Ascending: true}}},
+			want:  []string{"eod-curve.1", "revaluation.1", "revaluation.2", "eod-curve.2", "settlement.1"},
+			total: 5,
+		},
+		{
+			name:  "unset annotations sort first",
+			req:   &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.annotations.desk", Ascending: true}}},
+			want:  []string{"revaluation.1", "settlement.1", "revaluation.2", "eod-curve.2", "eod-curve.1"},
+			total: 5,
+		},
+		{
+			name:  "unset fields sort last descending",
+			req:   &v1.ListAlgorithmRequest{Order: []*v1.OrderExpression{{Field: "metadata.finished"}}},
+			want:  []string{"settlement.1", "eod-curve.1", "eod-curve.2", "revaluation.1", "revaluation.2"},
+			total: 5,
+		},
+		{
+			name: "start and limit",
+			req: &v1.ListAlgorithmRequest{
+				Order: []*v1.OrderExpression{{Field: "metadata.created", Ascending: true}},
+				Start: 1,
+				Limit: 2,
+			},
+			want:  []string{"revaluation.1", "eod-curve.2"},
+			total: 5,
+		},
+		{
+			name:  "start beyond end",
+			req:   &v1.ListAlgorithmRequest{Start: 10},
+			want:  nil,
+			total: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref := fixture()
+			sort.Slice(ref, func(i, j int) bool { return ref[i].Name < ref[j].Name })
+			refRes, refTotal, err := query.Evaluate(ref, test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := names(refRes); !equal(act, test.want) || refTotal != test.total {
+				t.Fatalf("test case disagrees with the query package: want %v (total %d), query package returned %v (total %d)", test.want, test.total, act, refTotal)
+			}
+
+			res, total, err := s.Find(ctx, test.req.Filter, test.req.Order, int(test.req.Start), int(test.req.Limit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := names(res); !equal(act, test.want) {
+				t.Errorf("unexpected result: want %v, got %v", test.want, act)
+			}
+			if total != test.total {
+				t.Errorf("unexpected total: want %d, got %d", test.total, total)
+			}
+		})
+	}
+}
+
+func newStatus(name, owner, repo string, phase v1.AlgorithmPhase, created time.Time, annotations map[string]string) *v1.AlgorithmStatus {
+	var annos []*v1.Annotation
+	for k, v := range annotations {
+		annos = append(annos, &v1.Annotation{Key: k, Value: v})
+	}
+	return &v1.AlgorithmStatus{
+		Name:  name,
+		Phase: phase,
+		Metadata: &v1.AlgorithmMetadata{
+			Owner:       owner,
+			Repository:  &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: repo, Ref: "main"},
+			Trigger:     v1.AlgorithmTrigger_TRIGGER_MANUAL,
+			Created:     timestamppb.New(created),
+			Annotations: annos,
+		},
+		Conditions: &v1.AlgorithmConditions{CanReplay: true},
+	}
+}
+
+func fixture() []*v1.AlgorithmStatus {
+	t0 := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	res := []*v1.AlgorithmStatus{
+		newStatus("eod-curve.1", "alice", "finance", v1.AlgorithmPhase_PHASE_DONE, t0, map[string]string{"desk": "rates"}),
+		newStatus("eod-curve.2", "bob", "finance", v1.AlgorithmPhase_PHASE_RUNNING, t0.Add(2*time.Hour), map[string]string{"desk": "fx"}),
+		newStatus("revaluation.1", "alice", "books", v1.AlgorithmPhase_PHASE_RUNNING, t0.Add(time.Hour), nil),
+		newStatus("revaluation.2", "carol", "books", v1.AlgorithmPhase_PHASE_WAITING, t0.Add(3*time.Hour), map[string]string{"desk": ""}),
+		newStatus("settlement.1", "bob", "books", v1.AlgorithmPhase_PHASE_DONE, t0.Add(24*time.Hour), nil),
+	}
+	res[0].Metadata.Finished = timestamppb.New(t0.Add(30 * time.Minute))
+	res[0].Conditions.Success = true
+	res[0].Conditions.DidExecute = true
+	res[1].Conditions.FailureCount = 2
+	res[3].Conditions.WaitUntil = timestamppb.New(t0.Add(4 * time.Hour))
+
+	settlement := res[4]
+	settlement.Details = "matched 100%_ of trades"
+	settlement.Metadata.Trigger = v1.AlgorithmTrigger_TRIGGER_PUSH
+	settlement.Metadata.Repository.Revision = "c0ffee"
+	settlement.Metadata.Finished = timestamppb.New(t0.Add(25 * time.Hour))
+	settlement.Conditions.FailureCount = 12
+	settlement.Conditions.DidExecute = true
+	return res
+}
+
+func filter(terms ...*v1.FilterTerm) []*v1.FilterExpression {
+	return []*v1.FilterExpression{{Terms: terms}}
+}
+
+func term(field, value string, op v1.FilterOp, negate bool) *v1.FilterTerm {
+	return &v1.FilterTerm{Field: field, Value: value, Operation: op, Negate: negate}
+}
+
+func names(res []*v1.AlgorithmStatus) []string {
+	var r []string
+	for _, s := range res {
+		r = append(r, s.Name)
+	}
+	return r
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package telemetry
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// healthService is the name of the standard gRPC health service, whose frequent checks are only logged at debug level
+const healthService = "grpc.health.v1.Health"
+
+// Interceptors measure and log the gRPC requests a server handles. Their metrics
+// are exported by registering them with a Prometheus registry.
+type Interceptors struct {
+	duration *prometheus.HistogramVec
+	streams  *prometheus.GaugeVec
+}
+
+// NewInterceptors produces interceptors for a gRPC server
+func NewInterceptors() *Interceptors {
+	return &Interceptors{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "finance",
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle gRPC requests by status code. Streams are measured until they end.",
+		}, []string{"service", "method", "code"}),
+		streams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "finance",
+			Subsystem: "grpc",
+			Name:      "active_streams",
+			Help:      "Number of streaming gRPC requests currently open, e.g. Listen and Subscribe calls.",
+		}, []string{"service", "method"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (i *Interceptors) Describe(ch chan<- *prometheus.Desc) {
+	i.duration.Describe(ch)
+	i.streams.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (i *Interceptors) Collect(ch chan<- prometheus.Metric) {
+	i.duration.Collect(ch)
+	i.streams.Collect(ch)
+}
+
+// UnaryInterceptor measures and logs unary calls
+func (i *Interceptors) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		i.observe(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor measures and logs streaming calls
+func (i *Interceptors) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		service, method := splitMethod(info.FullMethod)
+		active := i.streams.WithLabelValues(service, method)
+		active.Inc()
+		defer active.Dec()
+
+		start := time.Now()
+		err := handler(srv, ss)
+		i.observe(ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+// observe records a finished call in the metrics and the log
+func (i *Interceptors) observe(ctx context.Context, fullMethod string, start time.Time, err error) {
+	var (
+		duration        = time.Since(start)
+		code            = status.Code(err)
+		service, method = splitMethod(fullMethod)
+	)
+	i.duration.WithLabelValues(service, method, code.String()).Observe(duration.Seconds())
+
+	entry := log.WithFields(log.Fields{
+		"grpc.service":  service,
+		"grpc.method":   method,
+		"grpc.code":     code.String(),
+		"grpc.duration": duration.Seconds(),
+	})
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry = entry.WithField("peer", p.Addr.String())
+	}
+	if err != nil {
+		entry = entry.WithField("error", status.Convert(err).Message())
+	}
+	switch {
+	case isServerError(code):
+		entry.Warn("gRPC request failed")
+	case service == healthService:
+		entry.Debug("gRPC request")
+	default:
+		entry.Info("gRPC request")
+	}
+}
+
+// isServerError returns true for codes which indicate a problem of the server rather than of the request
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitMethod splits a full gRPC method name, e.g. "/v1.FinanceService/Listen" into service and method
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if idx := strings.LastIndex(fullMethod, "/"); idx >= 0 {
+		return fullMethod[:idx], fullMethod[idx+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,116 @@
+package telemetry
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+}
+
+func (fakeServerStream) Context() context.Context { return context.Background() }
+
+func histogramCount(t *testing.T, o prometheus.Observer) int {
+	var m dto.Metric
+	if err := o.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return int(m.GetHistogram().GetSampleCount())
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	i := NewInterceptors()
+	intercept := i.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/v1.FinanceService/GetAlgorithm"}
+	for _, err := range []error{nil, status.Error(codes.NotFound, "not found"), status.Error(codes.NotFound, "not found")} {
+		err := err
+		_, act := intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+		if act != err {
+			t.Errorf("expected the handler's error %v, got %v", err, act)
+		}
+	}
+
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, 1},
+		{codes.NotFound, 2},
+	}
+	for _, test := range tests {
+		h := i.duration.WithLabelValues("v1.FinanceService", "GetAlgorithm", test.code.String())
+		if n := histogramCount(t, h); n != test.want {
+			t.Errorf("expected %d %v calls, got %d", test.want, test.code, n)
+		}
+	}
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	i := NewInterceptors()
+	info := &grpc.StreamServerInfo{FullMethod: "/v1.FinanceService/Listen", IsServerStream: true}
+	active := i.streams.WithLabelValues("v1.FinanceService", "Listen")
+
+	err := i.StreamInterceptor()(nil, fakeServerStream{}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		if n := testutil.ToFloat64(active); n != 1 {
+			t.Errorf("expected one active stream, got %v", n)
+		}
+		return status.Error(codes.Canceled, "canceled")
+	})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("expected the handler's error, got %v", err)
+	}
+	if n := testutil.ToFloat64(active); n != 0 {
+		t.Errorf("expected no active stream, got %v", n)
+	}
+	if n := testutil.CollectAndCount(i, "finance_grpc_request_duration_seconds"); n != 1 {
+		t.Errorf("expected one measured call, got %d", n)
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		fullMethod  string
+		wantService string
+		wantMethod  string
+	}{
+		{"/v1.FinanceService/Listen", "v1.FinanceService", "Listen"},
+		{"/grpc.health.v1.Health/Check", "grpc.health.v1.Health", "Check"},
+		{"Listen", "unknown", "Listen"},
+	}
+	for _, test := range tests {
+		service, method := splitMethod(test.fullMethod)
+		if service != test.wantService || method != test.wantMethod {
+			t.Errorf("splitMethod(%q) = %q, %q; want %q, %q", test.fullMethod, service, method, test.wantService, test.wantMethod)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package webhook
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxBodySize is the largest delivery accepted unless configured otherwise. It matches
+// the largest payload GitHub sends.
+const DefaultMaxBodySize = 25 * 1024 * 1024
+
+var (
+	// ErrInvalidSignature is returned for deliveries which are unsigned or signed with another secret
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrIgnoredEvent is returned for verified deliveries of events which don't start Algorithms,
+	// e.g. pings or deleted tags
+	ErrIgnoredEvent = errors.New("event is ignored")
+)
+
+// Event is a verified push or branch deletion
+type Event struct {
+	// Trigger is TRIGGER_PUSH for pushes and TRIGGER_DELETED for deleted branches
+	Trigger v1.AlgorithmTrigger
+
+	// Repository names the repository and the full ref of the event. The revision is
+	// only set for pushes.
+	Repository *v1.Repository
+
+	// Sender is the name of the user who caused the event
+	Sender string
+}
+
+// RepositoryName returns host/owner/repo of the event's repository
+func (ev *Event) RepositoryName() string {
+	return ev.Repository.Host + "/" + ev.Repository.Owner + "/" + ev.Repository.Repo
+}
+
+// Parse verifies a webhook delivery of GitHub, GitLab, Gitea or Gogs and extracts its event.
+// GitHub, Gitea and Gogs deliveries must be signed with the secret, GitLab ones carry the secret as token.
+func Parse(header http.Header, body []byte, secret string) (*Event, error) {
+	if secret == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		if !validHMAC(header.Get("X-Gitea-Signature"), body, secret) {
+			return nil, ErrInvalidSignature
+		}
+		// Gitea and Gogs send their payloads in GitHub's format
+		return parseGitHub(header.Get("X-Gitea-Event"), body)
+	case header.Get("X-Gogs-Event") != "":
+		if !validHMAC(header.Get("X-Gogs-Signature"), body, secret) {
+			return nil, ErrInvalidSignature
+		}
+		return parseGitHub(header.Get("X-Gogs-Event"), body)
+	case header.Get("X-GitHub-Event") != "":
+		sig := header.Get("X-Hub-Signature-256")
+		if !strings.HasPrefix(sig, "sha256=") || !validHMAC(strings.TrimPrefix(sig, "sha256="), body, secret) {
+			return nil, ErrInvalidSignature
+		}
+		return parseGitHub(header.Get("X-GitHub-Event"), body)
+	case header.Get("X-Gitlab-Event") != "":
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return nil, ErrInvalidSignature
+		}
+		return parseGitLab(header.Get("X-Gitlab-Event"), body)
+	default:
+		return nil, fmt.Errorf("unknown Git host: no event header")
+	}
+}
+
+// validHMAC checks a hex encoded HMAC-SHA256 signature of the body
+func validHMAC(signature string, body []byte, secret string) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// zeroRevision is the revision Git hosts report for a ref which does not exist (anymore)
+const zeroRevision = "0000000000000000000000000000000000000000"
+
+type githubRepository struct {
+	Name    string `json:"name"`
+	HTMLURL string `json:"html_url"`
+	Owner   struct {
+		Login    string `json:"login"`
+		Username string `json:"username"`
+	} `json:"owner"`
+}
+
+func (r githubRepository) toAPI(ref string) (*v1.Repository, error) {
+	u, err := url.Parse(r.HTMLURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid repository URL %q", r.HTMLURL)
+	}
+	owner := r.Owner.Login
+	if owner == "" {
+		owner = r.Owner.Username
+	}
+	if owner == "" || r.Name == "" {
+		return nil, fmt.Errorf("repository owner or name missing")
+	}
+	return &v1.Repository{Host: u.Host, Owner: owner, Repo: r.Name, Ref: ref}, nil
+}
+
+type githubSender struct {
+	Login    string `json:"login"`
+	Username string `json:"username"`
+}
+
+func (s githubSender) name() string {
+	if s.Login != "" {
+		return s.Login
+	}
+	return s.Username
+}
+
+// githubPush is the payload of a push in GitHub's format, which Gitea and Gogs share
+type githubPush struct {
+	Ref        string           `json:"ref"`
+	After      string           `json:"after"`
+	Deleted    bool             `json:"deleted"`
+	Repository githubRepository `json:"repository"`
+	Sender     githubSender     `json:"sender"`
+}
+
+// githubDelete is the payload of a deleted branch or tag in GitHub's format
+type githubDelete struct {
+	Ref        string           `json:"ref"`
+	RefType    string           `json:"ref_type"`
+	Repository githubRepository `json:"repository"`
+	Sender     githubSender     `json:"sender"`
+}
+
+func parseGitHub(event string, body []byte) (*Event, error) {
+	switch event {
+	case "push":
+		var p githubPush
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("cannot parse push: %w", err)
+		}
+		// deleted branches are announced by a delete event as well
+		if p.Deleted || p.After == zeroRevision {
+			return nil, ErrIgnoredEvent
+		}
+		return pushEvent(p)
+	case "delete":
+		var p githubDelete
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("cannot parse delete: %w", err)
+		}
+		return deleteEvent(p)
+	default:
+		return nil, ErrIgnoredEvent
+	}
+}
+
+func pushEvent(p githubPush) (*Event, error) {
+	if p.Ref == "" || p.After == "" {
+		return nil, fmt.Errorf("push without ref or revision")
+	}
+	repo, err := p.Repository.toAPI(p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	repo.Revision = p.After
+	return &Event{Trigger: v1.AlgorithmTrigger_TRIGGER_PUSH, Repository: repo, Sender: p.Sender.name()}, nil
+}
+
+func deleteEvent(p githubDelete) (*Event, error) {
+	if p.RefType != "branch" {
+		return nil, ErrIgnoredEvent
+	}
+	if p.Ref == "" {
+		return nil, fmt.Errorf("delete without ref")
+	}
+	repo, err := p.Repository.toAPI("refs/heads/" + strings.TrimPrefix(p.Ref, "refs/heads/"))
+	if err != nil {
+		return nil, err
+	}
+	return &Event{Trigger: v1.AlgorithmTrigger_TRIGGER_DELETED, Repository: repo, Sender: p.Sender.name()}, nil
+}
+
+// gitlabPush is the payload of a GitLab push hook
+type gitlabPush struct {
+	Ref          string `json:"ref"`
+	After        string `json:"after"`
+	UserUsername string `json:"user_username"`
+	Project      struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+}
+
+func parseGitLab(event string, body []byte) (*Event, error) {
+	if event != "Push Hook" {
+		return nil, ErrIgnoredEvent
+	}
+	var p gitlabPush
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("cannot parse push: %w", err)
+	}
+	if p.Ref == "" || p.After == "" {
+		return nil, fmt.Errorf("push without ref or revision")
+	}
+	u, err := url.Parse(p.Project.WebURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid project URL %q", p.Project.WebURL)
+	}
+	// projects may live in nested groups, all of which make up the owner
+	idx := strings.LastIndex(p.Project.PathWithNamespace, "/")
+	if idx <= 0 {
+		return nil, fmt.Errorf("invalid project path %q", p.Project.PathWithNamespace)
+	}
+	repo := &v1.Repository{
+		Host:  u.Host,
+		Owner: p.Project.PathWithNamespace[:idx],
+		Repo:  p.Project.PathWithNamespace[idx+1:],
+		Ref:   p.Ref,
+	}
+
+	// GitLab reports deleted branches as pushes to the zero revision
+	if p.After == zeroRevision {
+		if !strings.HasPrefix(p.Ref, "refs/heads/") {
+			return nil, ErrIgnoredEvent
+		}
+		return &Event{Trigger: v1.AlgorithmTrigger_TRIGGER_DELETED, Repository: repo, Sender: p.UserUsername}, nil
+	}
+	repo.Revision = p.After
+	return &Event{Trigger: v1.AlgorithmTrigger_TRIGGER_PUSH, Repository: repo, Sender: p.UserUsername}, nil
+}
+
+// Handler receives webhook deliveries and passes the events on
+type Handler struct {
+	// Secret verifies the deliveries
+	Secret string
+
+	// MaxBodySize limits the size of a delivery, defaults to DefaultMaxBodySize
+	MaxBodySize int64
+
+	// OnEvent acts on an event and returns the names of the Algorithms it started
+	OnEvent func(ctx context.Context, ev *Event) ([]string, error)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxSize := h.MaxBodySize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBodySize
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	if err != nil {
+		http.Error(w, "cannot read delivery", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ev, err := Parse(r.Header, body, h.Secret)
+	if errors.Is(err, ErrInvalidSignature) {
+		log.WithField("remote", r.RemoteAddr).Warn("rejected webhook delivery with invalid signature")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, ErrIgnoredEvent) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "ignored")
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("repository", ev.RepositoryName()).WithField("ref", ev.Repository.Ref).WithField("trigger", ev.Trigger).Debug("webhook event")
+	started, err := h.OnEvent(r.Context(), ev)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if status.Code(err) == codes.PermissionDenied {
+			code = http.StatusForbidden
+		}
+		http.Error(w, status.Convert(err).Message(), code)
+		return
+	}
+	for _, name := range started {
+		fmt.Fprintln(w, name)
+	}
+}
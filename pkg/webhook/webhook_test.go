@@ -0,0 +1,255 @@
+package webhook
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const testSecret = "It's a Secret to Everybody"
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery returns the headers a Git host sends along with a recorded payload
+func delivery(t *testing.T, host, event, fixture, secret string) (http.Header, []byte) {
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	switch host {
+	case "github":
+		header.Set("X-GitHub-Event", event)
+		header.Set("X-Hub-Signature-256", "sha256="+sign(body, secret))
+	case "gitea":
+		header.Set("X-Gitea-Event", event)
+		header.Set("X-Gitea-Signature", sign(body, secret))
+	case "gogs":
+		header.Set("X-Gogs-Event", event)
+		header.Set("X-Gogs-Signature", sign(body, secret))
+	case "gitlab":
+		header.Set("X-Gitlab-Event", event)
+		header.Set("X-Gitlab-Token", secret)
+	}
+	return header, body
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Host        string
+		Event       string
+		Fixture     string
+		Secret      string
+		Expectation *Event
+		Error       error
+	}{
+		{
+			Name:    "github push",
+			Host:    "github",
+			Event:   "push",
+			Fixture: "github-push.json",
+			Expectation: &Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "rates", Ref: "refs/heads/main", Revision: "3f5a7c9e1b3d5f7a9c1e3b5d7f9a1c3e5b7d9f1a"},
+				Sender:     "prao",
+			},
+		},
+		{
+			Name:    "github branch delete",
+			Host:    "github",
+			Event:   "delete",
+			Fixture: "github-delete.json",
+			Expectation: &Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_DELETED,
+				Repository: &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "rates", Ref: "refs/heads/feature/fx-swaps"},
+				Sender:     "prao",
+			},
+		},
+		{Name: "github push of deleted branch", Host: "github", Event: "push", Fixture: "github-push-deleted.json", Error: ErrIgnoredEvent},
+		{Name: "github tag delete", Host: "github", Event: "delete", Fixture: "github-delete-tag.json", Error: ErrIgnoredEvent},
+		{Name: "github ping", Host: "github", Event: "ping", Fixture: "github-ping.json", Error: ErrIgnoredEvent},
+		{Name: "github wrong secret", Host: "github", Event: "push", Fixture: "github-push.json", Secret: "guessed", Error: ErrInvalidSignature},
+		{
+			Name:    "gitea push",
+			Host:    "gitea",
+			Event:   "push",
+			Fixture: "gitea-push.json",
+			Expectation: &Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "git.bhojpur.net", Owner: "finance", Repo: "pricing", Ref: "refs/heads/develop", Revision: "b2c3d4e5f60718293a4b5c6d7e8f901234567890"},
+				Sender:     "amehta",
+			},
+		},
+		{
+			Name:    "gogs push",
+			Host:    "gogs",
+			Event:   "push",
+			Fixture: "gitea-push.json",
+			Expectation: &Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "git.bhojpur.net", Owner: "finance", Repo: "pricing", Ref: "refs/heads/develop", Revision: "b2c3d4e5f60718293a4b5c6d7e8f901234567890"},
+				Sender:     "amehta",
+			},
+		},
+		{Name: "gitea wrong secret", Host: "gitea", Event: "push", Fixture: "gitea-push.json", Secret: "guessed", Error: ErrInvalidSignature},
+		{
+			Name:    "gitlab push",
+			Host:    "gitlab",
+			Event:   "Push Hook",
+			Fixture: "gitlab-push.json",
+			Expectation: &Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_PUSH,
+				Repository: &v1.Repository{Host: "gitlab.com", Owner: "bhojpur/quant", Repo: "risk-engine", Ref: "refs/heads/main", Revision: "da1560886d4f094c3e6c9ef40349f7d38b5d27d7"},
+				Sender:     "kiyer",
+			},
+		},
+		{
+			Name:    "gitlab branch delete",
+			Host:    "gitlab",
+			Event:   "Push Hook",
+			Fixture: "gitlab-push-deleted.json",
+			Expectation: &Event{
+				Trigger:    v1.AlgorithmTrigger_TRIGGER_DELETED,
+				Repository: &v1.Repository{Host: "gitlab.com", Owner: "bhojpur/quant", Repo: "risk-engine", Ref: "refs/heads/hotfix/var-limits"},
+				Sender:     "kiyer",
+			},
+		},
+		{Name: "gitlab wrong token", Host: "gitlab", Event: "Push Hook", Fixture: "gitlab-push.json", Secret: "guessed", Error: ErrInvalidSignature},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			secret := test.Secret
+			if secret == "" {
+				secret = testSecret
+			}
+			header, body := delivery(t, test.Host, test.Event, test.Fixture, secret)
+
+			ev, err := Parse(header, body, testSecret)
+			if !errors.Is(err, test.Error) {
+				t.Fatalf("unexpected error: want %v, got %v", test.Error, err)
+			}
+			if test.Expectation == nil {
+				return
+			}
+			if ev.Trigger != test.Expectation.Trigger || ev.Sender != test.Expectation.Sender || !proto.Equal(ev.Repository, test.Expectation.Repository) {
+				t.Errorf("unexpected event: want %+v, got %+v", test.Expectation, ev)
+			}
+		})
+	}
+}
+
+func TestParseWithoutSecret(t *testing.T) {
+	header, body := delivery(t, "github", "push", "github-push.json", "")
+	_, err := Parse(header, body, "")
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("deliveries must not be accepted without a secret: got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var received []*Event
+	h := &Handler{
+		Secret: testSecret,
+		OnEvent: func(ctx context.Context, ev *Event) ([]string, error) {
+			received = append(received, ev)
+			if ev.Repository.Ref == "refs/heads/develop" {
+				return nil, status.Error(codes.PermissionDenied, "Bhojpur Finance is read-only")
+			}
+			return []string{"eod-curve.1", "revaluation.4"}, nil
+		},
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	tests := []struct {
+		Name    string
+		Method  string
+		Host    string
+		Event   string
+		Fixture string
+		Secret  string
+		Code    int
+		Body    string
+	}{
+		{Name: "push", Host: "github", Event: "push", Fixture: "github-push.json", Code: http.StatusOK, Body: "eod-curve.1\nrevaluation.4\n"},
+		{Name: "ping", Host: "github", Event: "ping", Fixture: "github-ping.json", Code: http.StatusAccepted, Body: "ignored\n"},
+		{Name: "invalid signature", Host: "github", Event: "push", Fixture: "github-push.json", Secret: "guessed", Code: http.StatusUnauthorized},
+		{Name: "unknown host", Fixture: "github-push.json", Code: http.StatusBadRequest},
+		{Name: "read-only", Host: "gitea", Event: "push", Fixture: "gitea-push.json", Code: http.StatusForbidden, Body: "Bhojpur Finance is read-only\n"},
+		{Name: "get", Method: http.MethodGet, Host: "github", Event: "push", Fixture: "github-push.json", Code: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			secret := test.Secret
+			if secret == "" {
+				secret = testSecret
+			}
+			method := test.Method
+			if method == "" {
+				method = http.MethodPost
+			}
+			header, body := delivery(t, test.Host, test.Event, test.Fixture, secret)
+			req, err := http.NewRequest(method, srv.URL, strings.NewReader(string(body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header = header
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			content, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != test.Code {
+				t.Errorf("unexpected status: want %d, got %d (%s)", test.Code, resp.StatusCode, content)
+			}
+			if test.Body != "" && string(content) != test.Body {
+				t.Errorf("unexpected body: want %q, got %q", test.Body, content)
+			}
+		})
+	}
+	if len(received) != 2 {
+		t.Errorf("expected two events to be passed on, got %d", len(received))
+	}
+}
@@ -0,0 +1,413 @@
+package webui
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxRequestSize limits the size of JSON request bodies
+const maxRequestSize = 1 << 20
+
+var (
+	unmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+	marshaler   = protojson.MarshalOptions{EmitUnpopulated: true}
+)
+
+// unaryMethod calls a unary gRPC method with a JSON-encoded request
+type unaryMethod func(ctx context.Context, req []byte) (proto.Message, error)
+
+// streamMethod calls a server-streaming gRPC method with a JSON-encoded request and
+// forwards every message it receives to send
+type streamMethod func(ctx context.Context, req []byte, send func(proto.Message) error) error
+
+// bridge makes the gRPC services available to browsers. Requests are POSTed as JSON to
+// /api/<service>/<method>, e.g. /api/v1.FinanceService/ListAlgorithm. Unary methods respond
+// with a JSON object, streaming methods with one JSON object per line, each of which is
+// either {"result": ...} or {"error": ...}.
+type bridge struct {
+	unary  map[string]unaryMethod
+	stream map[string]streamMethod
+}
+
+func newBridge(finance v1.FinanceServiceClient, ui v1.FinanceUIClient, pricing v1.PricingServiceClient, loan v1.LoanServiceClient) *bridge {
+	return &bridge{
+		unary: map[string]unaryMethod{
+			"v1.FinanceService/StartAlgorithm": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.StartAlgorithmRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.StartAlgorithm(ctx, &req)
+			},
+			"v1.FinanceService/StartFromPreviousAlgorithm": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.StartFromPreviousAlgorithmRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.StartFromPreviousAlgorithm(ctx, &req)
+			},
+			"v1.FinanceService/ListAlgorithm": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.ListAlgorithmRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.ListAlgorithm(ctx, &req)
+			},
+			"v1.FinanceService/GetAlgorithm": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.GetAlgorithmRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.GetAlgorithm(ctx, &req)
+			},
+			"v1.FinanceService/StopAlgorithm": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.StopAlgorithmRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.StopAlgorithm(ctx, &req)
+			},
+			"v1.FinanceService/CreateSchedule": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.CreateScheduleRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.CreateSchedule(ctx, &req)
+			},
+			"v1.FinanceService/ListSchedules": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.ListSchedulesRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.ListSchedules(ctx, &req)
+			},
+			"v1.FinanceService/GetSchedule": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.GetScheduleRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.GetSchedule(ctx, &req)
+			},
+			"v1.FinanceService/UpdateSchedule": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.UpdateScheduleRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.UpdateSchedule(ctx, &req)
+			},
+			"v1.FinanceService/DeleteSchedule": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.DeleteScheduleRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.DeleteSchedule(ctx, &req)
+			},
+			"v1.FinanceService/PruneAlgorithms": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.PruneAlgorithmsRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return finance.PruneAlgorithms(ctx, &req)
+			},
+			"v1.PricingService/Price": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.PriceRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return pricing.Price(ctx, &req)
+			},
+			"v1.PricingService/Solve": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.SolveRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return pricing.Solve(ctx, &req)
+			},
+			"v1.LoanService/GenerateSchedule": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.GenerateScheduleRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return loan.GenerateSchedule(ctx, &req)
+			},
+			"v1.FinanceUI/IsReadOnly": func(ctx context.Context, body []byte) (proto.Message, error) {
+				var req v1.IsReadOnlyRequest
+				if err := decode(body, &req); err != nil {
+					return nil, err
+				}
+				return ui.IsReadOnly(ctx, &req)
+			},
+		},
+		stream: map[string]streamMethod{
+			"v1.FinanceService/Subscribe": func(ctx context.Context, body []byte, send func(proto.Message) error) error {
+				var req v1.SubscribeRequest
+				if err := decode(body, &req); err != nil {
+					return err
+				}
+				inc, err := finance.Subscribe(ctx, &req)
+				if err != nil {
+					return err
+				}
+				return forward(func() (proto.Message, error) { return inc.Recv() }, send)
+			},
+			"v1.FinanceService/Listen": func(ctx context.Context, body []byte, send func(proto.Message) error) error {
+				var req v1.ListenRequest
+				if err := decode(body, &req); err != nil {
+					return err
+				}
+				inc, err := finance.Listen(ctx, &req)
+				if err != nil {
+					return err
+				}
+				return forward(func() (proto.Message, error) { return inc.Recv() }, send)
+			},
+			"v1.FinanceUI/ListAlgorithmSpecs": func(ctx context.Context, body []byte, send func(proto.Message) error) error {
+				var req v1.ListAlgorithmSpecsRequest
+				if err := decode(body, &req); err != nil {
+					return err
+				}
+				inc, err := ui.ListAlgorithmSpecs(ctx, &req)
+				if err != nil {
+					return err
+				}
+				return forward(func() (proto.Message, error) { return inc.Recv() }, send)
+			},
+		},
+	}
+}
+
+func decode(body []byte, msg proto.Message) error {
+	if len(body) == 0 {
+		return nil
+	}
+	err := unmarshaler.Unmarshal(body, msg)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "cannot parse request: %v", err)
+	}
+	return nil
+}
+
+// forward passes all messages produced by recv on to send until the stream ends
+func forward(recv func() (proto.Message, error), send func(proto.Message) error) error {
+	for {
+		msg, err := recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = send(msg)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (b *bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path
+	unary, isUnary := b.unary[method]
+	stream, isStream := b.stream[method]
+	if !isUnary && !isStream {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Browsers send cross-site form posts without a preflight, so we accept JSON
+	// only and refuse requests that another site made on behalf of the user.
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+		return
+	}
+	if !sameOrigin(r) {
+		writeError(w, status.Error(codes.PermissionDenied, "cross-site requests are not allowed"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, "cannot read request"))
+		return
+	}
+
+	ctx := r.Context()
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", auth)
+	}
+
+	if isUnary {
+		resp, err := unary(ctx, body)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = writeJSON(w, resp)
+		if err != nil {
+			log.WithError(err).WithField("method", method).Debug("cannot write response")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	send := func(msg proto.Message) error {
+		err := writeLine(w, "result", msg)
+		if err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	err = stream(ctx, body, send)
+	if err != nil && ctx.Err() == nil {
+		_ = writeLine(w, "error", errorBody(err))
+	}
+}
+
+func writeJSON(w io.Writer, msg proto.Message) error {
+	content, err := marshaler.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// writeLine writes a single line of a streaming response
+func writeLine(w io.Writer, field string, msg interface{}) error {
+	var (
+		content []byte
+		err     error
+	)
+	if pm, ok := msg.(proto.Message); ok {
+		content, err = marshaler.Marshal(pm)
+	} else {
+		content, err = json.Marshal(msg)
+	}
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(map[string]json.RawMessage{field: content})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// sameOrigin reports whether a request was made by the web UI itself rather
+// than by a page of another site. Clients other than browsers send neither header.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+type errorResponse struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+func errorBody(err error) errorResponse {
+	s := status.Convert(err)
+	res := errorResponse{Code: s.Code().String(), Message: s.Message()}
+	for _, d := range s.Proto().Details {
+		// details of types unknown to this binary cannot be rendered and are left out
+		content, err := marshaler.Marshal(d)
+		if err != nil {
+			continue
+		}
+		res.Details = append(res.Details, content)
+	}
+	return res
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(status.Code(err)))
+	_ = json.NewEncoder(w).Encode(errorBody(err))
+}
+
+// httpStatus maps gRPC status codes to their HTTP equivalent
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,290 @@
+package webui
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+	"github.com/bhojpur/finance/pkg/finance"
+	"github.com/bhojpur/finance/pkg/loan"
+	"github.com/bhojpur/finance/pkg/pricing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer(t *testing.T, cfg finance.Config) *httptest.Server {
+	srv := finance.NewService(cfg)
+	grpcServer := grpc.NewServer()
+	v1.RegisterFinanceServiceServer(grpcServer, srv)
+	v1.RegisterFinanceUIServer(grpcServer, finance.NewUIService(srv.Config))
+	v1.RegisterPricingServiceServer(grpcServer, pricing.NewService())
+	v1.RegisterLoanServiceServer(grpcServer, loan.NewService())
+
+	l := bufconn.Listen(1 << 20)
+	go func() { _ = grpcServer.Serve(l) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	web := httptest.NewServer(Handler(v1.NewFinanceServiceClient(conn), v1.NewFinanceUIClient(conn), v1.NewPricingServiceClient(conn), v1.NewLoanServiceClient(conn)))
+	t.Cleanup(web.Close)
+	return web
+}
+
+func post(t *testing.T, web *httptest.Server, method, body string) (*http.Response, string) {
+	resp, err := http.Post(web.URL+"/api/"+method, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		sb.WriteString(scanner.Text())
+		sb.WriteString("\n")
+	}
+	return resp, sb.String()
+}
+
+func TestBridgeUnary(t *testing.T) {
+	web := newTestServer(t, finance.Config{})
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "start",
+			method:     "v1.FinanceService/StartAlgorithm",
+			body:       `{"metadata": {"owner": "foo", "algorithmSpecName": "eod-curve"}, "algorithmYaml": "ZGVzY3JpcHRpb246IGVvZA=="}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"eod-curve.1"`,
+		},
+		{
+			name:       "get",
+			method:     "v1.FinanceService/GetAlgorithm",
+			body:       `{"name": "eod-curve.1"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"phase":"PHASE_PREPARING"`,
+		},
+		{
+			name:       "list",
+			method:     "v1.FinanceService/ListAlgorithm",
+			body:       `{"filter": [{"terms": [{"field": "metadata.owner", "value": "foo"}]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"total":1`,
+		},
+		{
+			name:       "price",
+			method:     "v1.PricingService/Price",
+			body:       `{"termStructure": "{\"r\": 2, \"spread\": 0}", "instrument": {"forwardContract": {"strike": 100, "forwardPrice": 100, "maturity": 1}}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"presentValue":0`,
+		},
+		{
+			name:       "loan schedule",
+			method:     "v1.LoanService/GenerateSchedule",
+			body:       `{"config": {"startDate": "2022-01-01", "endDate": "2022-12-31", "frequency": "FREQUENCY_MONTHLY", "amountBorrowed": "1200", "interestType": "INTEREST_TYPE_FLAT", "interest": "0"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"totalPrincipal":"-1200"`,
+		},
+		{
+			name:       "uneven loan schedule",
+			method:     "v1.LoanService/GenerateSchedule",
+			body:       `{"config": {"startDate": "2022-01-01", "endDate": "2022-12-30", "frequency": "FREQUENCY_MONTHLY", "amountBorrowed": "1200", "interestType": "INTEREST_TYPE_FLAT", "interest": "0"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"field":"config.end_date"`,
+		},
+		{
+			name:       "not found",
+			method:     "v1.FinanceService/GetAlgorithm",
+			body:       `{"name": "does-not-exist.1"}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"NotFound"`,
+		},
+		{
+			name:       "invalid JSON",
+			method:     "v1.FinanceService/GetAlgorithm",
+			body:       `{"name": `,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"InvalidArgument"`,
+		},
+		{
+			name:       "unknown method",
+			method:     "v1.FinanceService/DeleteEverything",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "read-only",
+			method:     "v1.FinanceUI/IsReadOnly",
+			wantStatus: http.StatusOK,
+			wantBody:   `"readonly":false`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, body := post(t, web, test.method, test.body)
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("unexpected status: want %d, got %d (%s)", test.wantStatus, resp.StatusCode, body)
+			}
+			if !strings.Contains(strings.ReplaceAll(body, " ", ""), test.wantBody) {
+				t.Errorf("expected body to contain %s, got %s", test.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestBridgeStream(t *testing.T) {
+	web := newTestServer(t, finance.Config{})
+	post(t, web, "v1.FinanceService/StartAlgorithm", `{"metadata": {"owner": "foo", "algorithmSpecName": "eod-curve"}, "algorithmYaml": "ZGVzY3JpcHRpb246IGVvZA=="}`)
+	post(t, web, "v1.FinanceService/StopAlgorithm", `{"name": "eod-curve.1"}`)
+
+	resp, body := post(t, web, "v1.FinanceService/Listen", `{"name": "eod-curve.1", "updates": true, "logs": "LOGS_RAW"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("unexpected content type: %s", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single message, got %q", lines)
+	}
+	var msg struct {
+		Result struct {
+			Update struct {
+				Phase string `json:"phase"`
+			} `json:"update"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Result.Update.Phase != "PHASE_DONE" {
+		t.Errorf("unexpected phase: %s", msg.Result.Update.Phase)
+	}
+
+	// errors which occur once the stream has started are sent as the last message
+	_, body = post(t, web, "v1.FinanceService/Listen", `{"name": "does-not-exist.1", "updates": true}`)
+	if !strings.Contains(body, `"error":{"code":"NotFound"`) {
+		t.Errorf("expected a NotFound error, got %s", body)
+	}
+}
+
+func TestBridgeCrossSite(t *testing.T) {
+	web := newTestServer(t, finance.Config{})
+
+	tests := []struct {
+		name        string
+		contentType string
+		header      http.Header
+		wantStatus  int
+	}{
+		{
+			name:        "same origin",
+			contentType: "application/json",
+			header:      http.Header{"Origin": {web.URL}, "Sec-Fetch-Site": {"same-origin"}},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "json with charset",
+			contentType: "application/json; charset=utf-8",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "form post",
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "no content type",
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "cross-site fetch",
+			contentType: "application/json",
+			header:      http.Header{"Sec-Fetch-Site": {"cross-site"}},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "foreign origin",
+			contentType: "application/json",
+			header:      http.Header{"Origin": {"https://attacker.example"}},
+			wantStatus:  http.StatusForbidden,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, web.URL+"/api/v1.FinanceService/ListAlgorithm", strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("unexpected status: want %d, got %d", test.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	web := newTestServer(t, finance.Config{})
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, err := http.Get(web.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: unexpected status %d", path, resp.StatusCode)
+		}
+	}
+}
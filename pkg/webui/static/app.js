@@ -0,0 +1,348 @@
+"use strict";
+
+// requestHeaders returns the headers of bridge requests, including the API token the user entered, if any
+function requestHeaders() {
+    const headers = {"Content-Type": "application/json"};
+    const token = localStorage.getItem("financeToken");
+    if (token) {
+        headers["Authorization"] = "Bearer " + token;
+    }
+    return headers;
+}
+
+// askForToken lets the user enter an API token once the server asks for authentication.
+// We ask only once per page load so that a reconnecting stream doesn't keep prompting.
+let askedForToken = false;
+function askForToken() {
+    if (askedForToken) {
+        return;
+    }
+    askedForToken = true;
+    const token = window.prompt("Bhojpur Finance requires authentication. Please enter your API token:");
+    if (token) {
+        localStorage.setItem("financeToken", token.trim());
+    } else {
+        localStorage.removeItem("financeToken");
+    }
+}
+
+// call invokes a unary method of the JSON bridge
+async function call(method, req) {
+    const resp = await fetch("api/" + method, {
+        method: "POST",
+        headers: requestHeaders(),
+        body: JSON.stringify(req || {}),
+    });
+    const body = await resp.json();
+    if (resp.status === 401) {
+        askForToken();
+    }
+    if (!resp.ok) {
+        throw new Error(body.message || resp.statusText);
+    }
+    return body;
+}
+
+// stream invokes a streaming method of the JSON bridge and calls onMessage for every message.
+// The returned promise resolves once the stream ends.
+async function stream(method, req, onMessage, signal) {
+    const resp = await fetch("api/" + method, {
+        method: "POST",
+        headers: requestHeaders(),
+        body: JSON.stringify(req || {}),
+        signal: signal,
+    });
+    if (resp.status === 401) {
+        askForToken();
+    }
+    if (!resp.ok) {
+        const body = await resp.json();
+        throw new Error(body.message || resp.statusText);
+    }
+
+    const reader = resp.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = "";
+    for (;;) {
+        const {value, done} = await reader.read();
+        if (done) {
+            return;
+        }
+        buffer += decoder.decode(value, {stream: true});
+        let idx;
+        while ((idx = buffer.indexOf("\n")) >= 0) {
+            const line = buffer.slice(0, idx);
+            buffer = buffer.slice(idx + 1);
+            if (!line) {
+                continue;
+            }
+            const msg = JSON.parse(line);
+            if (msg.error) {
+                throw new Error(msg.error.message);
+            }
+            onMessage(msg.result);
+        }
+    }
+}
+
+// keepStreaming restarts a stream whenever it breaks, waiting a little longer after every failure
+function keepStreaming(method, req, onMessage, signal, onReconnect) {
+    let delay = 1000;
+    const run = async () => {
+        while (!signal.aborted) {
+            try {
+                setConnection("connected");
+                await stream(method, req, (msg) => {
+                    delay = 1000;
+                    onMessage(msg);
+                }, signal);
+                return;
+            } catch (err) {
+                if (signal.aborted) {
+                    return;
+                }
+                setConnection("reconnecting: " + err.message);
+            }
+            await new Promise((resolve) => setTimeout(resolve, delay));
+            delay = Math.min(delay * 2, 30000);
+            if (onReconnect) {
+                onReconnect();
+            }
+        }
+    };
+    run();
+}
+
+const state = {
+    readOnly: false,
+    algorithms: new Map(),
+    abort: null,
+};
+
+function $(id) {
+    return document.getElementById(id);
+}
+
+function setConnection(text) {
+    $("connection").textContent = text;
+}
+
+function showError(err) {
+    const el = $("error");
+    el.textContent = err ? err.message || String(err) : "";
+    el.hidden = !err;
+}
+
+function phaseName(phase) {
+    return (phase || "PHASE_UNKNOWN").replace("PHASE_", "").toLowerCase();
+}
+
+function formatTime(ts) {
+    return ts ? new Date(ts).toLocaleString() : "";
+}
+
+function el(tag, attrs, ...children) {
+    const res = document.createElement(tag);
+    Object.entries(attrs || {}).forEach(([k, v]) => {
+        if (k === "class") {
+            res.className = v;
+        } else {
+            res.setAttribute(k, v);
+        }
+    });
+    children.forEach((c) => res.append(c));
+    return res;
+}
+
+function phaseBadge(phase) {
+    const name = phaseName(phase);
+    return el("span", {class: "phase phase-" + name}, name);
+}
+
+// --- list view ---------------------------------------------------------------
+
+function listFilter() {
+    const terms = [];
+    const name = $("filter-name").value.trim();
+    if (name) {
+        terms.push({field: "name", value: name, operation: "OP_CONTAINS"});
+    }
+    const phase = $("filter-phase").value;
+    if (phase) {
+        terms.push({field: "phase", value: phase, operation: "OP_EQUALS"});
+    }
+    return terms.length ? [{terms: terms}] : [];
+}
+
+function renderList() {
+    const rows = Array.from(state.algorithms.values())
+        .sort((a, b) => (b.metadata.created || "").localeCompare(a.metadata.created || ""))
+        .map((algo) => {
+            const conds = algo.conditions || {};
+            const done = algo.phase === "PHASE_DONE";
+            const row = el("tr", {},
+                el("td", {}, algo.name),
+                el("td", {}, algo.metadata.owner || ""),
+                el("td", {}, phaseBadge(algo.phase)),
+                el("td", {class: done ? "success-" + conds.success : ""}, done ? String(conds.success) : ""),
+                el("td", {}, String(conds.failureCount || 0)),
+                el("td", {}, formatTime(algo.metadata.created)),
+                el("td", {}, formatTime(algo.metadata.finished)),
+            );
+            row.addEventListener("click", () => {
+                location.hash = "#/algorithm/" + encodeURIComponent(algo.name);
+            });
+            return row;
+        });
+    $("algorithms").replaceChildren(...rows);
+}
+
+async function loadList() {
+    const resp = await call("v1.FinanceService/ListAlgorithm", {
+        filter: listFilter(),
+        order: [{field: "metadata.created", ascending: false}],
+        limit: 100,
+    });
+    state.algorithms = new Map(resp.result.map((a) => [a.name, a]));
+    $("total").textContent = "showing " + resp.result.length + " of " + resp.total + " algorithms";
+    renderList();
+}
+
+function showList(signal) {
+    $("list-view").hidden = false;
+    $("detail-view").hidden = true;
+    loadList().catch(showError);
+
+    keepStreaming("v1.FinanceService/Subscribe", {filter: listFilter()}, (msg) => {
+        // the server applies the filter to the updates already
+        state.algorithms.set(msg.result.name, msg.result);
+        renderList();
+    }, signal, () => loadList().catch(showError));
+}
+
+// --- detail view -------------------------------------------------------------
+
+function renderStatus(algo) {
+    const md = algo.metadata || {};
+    const conds = algo.conditions || {};
+    const repo = md.repository;
+    const entries = [
+        ["Phase", phaseBadge(algo.phase)],
+        ["Details", algo.details || ""],
+        ["Owner", md.owner || ""],
+        ["Spec", md.algorithmSpecName || ""],
+        ["Trigger", (md.trigger || "").replace("TRIGGER_", "").toLowerCase()],
+        ["Repository", repo && repo.repo ? repo.host + "/" + repo.owner + "/" + repo.repo + "@" + (repo.revision || repo.ref) : ""],
+        ["Created", formatTime(md.created)],
+        ["Finished", formatTime(md.finished)],
+        ["Wait until", formatTime(conds.waitUntil)],
+        ["Success", String(!!conds.success)],
+        ["Failure count", String(conds.failureCount || 0)],
+        ["Did execute", String(!!conds.didExecute)],
+        ["Can replay", String(!!conds.canReplay)],
+    ];
+    (md.annotations || []).forEach((a) => entries.push([a.key, a.value]));
+
+    $("detail-status").replaceChildren(...entries.flatMap(([k, v]) => [el("dt", {}, k), el("dd", {}, v)]));
+    $("detail-results").replaceChildren(...(algo.results || []).map((r) =>
+        el("li", {}, el("strong", {}, r.type), " ", r.payload, r.description ? " – " + r.description : "")));
+    $("stop").hidden = state.readOnly || algo.phase === "PHASE_DONE";
+}
+
+function appendSlice(evt) {
+    const log = $("log");
+    const name = evt.name || "";
+    const id = "slice-" + name;
+    let slice = name ? document.getElementById(id) : null;
+    const ensureSlice = () => {
+        if (!slice) {
+            slice = el("div", {id: id, class: "slice"}, el("div", {class: "slice-name"}, name));
+            log.append(slice);
+        }
+        return slice;
+    };
+
+    switch (evt.type) {
+    case "SLICE_PHASE": {
+        const line = el("div", {class: "phase-line"});
+        line.innerHTML = evt.payload;
+        log.append(line);
+        break;
+    }
+    case "SLICE_START":
+        ensureSlice();
+        break;
+    case "SLICE_CONTENT": {
+        const line = el("div");
+        // the server converts the log to escaped HTML
+        line.innerHTML = evt.payload;
+        (name ? ensureSlice() : log).append(line);
+        break;
+    }
+    case "SLICE_DONE":
+        ensureSlice().querySelector(".slice-name").classList.add("slice-done");
+        break;
+    case "SLICE_FAIL":
+        ensureSlice().querySelector(".slice-name").classList.add("slice-fail");
+        break;
+    case "SLICE_RESULT":
+        break;
+    default:
+        ensureSlice().querySelector(".slice-name").classList.add("slice-abandoned");
+    }
+    log.scrollTop = log.scrollHeight;
+}
+
+function showDetail(name, signal) {
+    $("list-view").hidden = true;
+    $("detail-view").hidden = false;
+    $("detail-name").textContent = name;
+    $("detail-status").replaceChildren();
+    $("detail-results").replaceChildren();
+    $("log").replaceChildren();
+
+    $("stop").onclick = () => {
+        call("v1.FinanceService/StopAlgorithm", {name: name}).catch(showError);
+    };
+
+    keepStreaming("v1.FinanceService/Listen", {name: name, updates: true, logs: "LOGS_HTML"}, (msg) => {
+        if (msg.update) {
+            renderStatus(msg.update);
+        } else if (msg.slice) {
+            appendSlice(msg.slice);
+        }
+    }, signal, () => $("log").replaceChildren());
+}
+
+// --- routing -----------------------------------------------------------------
+
+function route() {
+    if (state.abort) {
+        state.abort.abort();
+    }
+    state.abort = new AbortController();
+    showError(null);
+
+    const m = location.hash.match(/^#\/algorithm\/(.+)$/);
+    if (m) {
+        showDetail(decodeURIComponent(m[1]), state.abort.signal);
+    } else {
+        showList(state.abort.signal);
+    }
+}
+
+let filterTimeout;
+$("filter").addEventListener("input", () => {
+    clearTimeout(filterTimeout);
+    filterTimeout = setTimeout(route, 300);
+});
+$("filter").addEventListener("submit", (e) => e.preventDefault());
+window.addEventListener("hashchange", route);
+
+call("v1.FinanceUI/IsReadOnly", {})
+    .then((resp) => {
+        state.readOnly = resp.readonly;
+        $("readonly").hidden = !resp.readonly;
+    })
+    .catch(showError)
+    .finally(route);
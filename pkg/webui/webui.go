@@ -0,0 +1,47 @@
+package webui
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	v1 "github.com/bhojpur/finance/pkg/api/v1"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the web dashboard and the JSON bridge it uses to talk to the gRPC services.
+// The bridge is available under /api/.
+func Handler(finance v1.FinanceServiceClient, ui v1.FinanceUIClient, pricing v1.PricingServiceClient, loan v1.LoanServiceClient) http.Handler {
+	content, err := fs.Sub(static, "static")
+	if err != nil {
+		// static is embedded at compile time, hence this cannot happen
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api/", newBridge(finance, ui, pricing, loan)))
+	mux.Handle("/", http.FileServer(http.FS(content)))
+	return mux
+}